@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/iris-contrib/swagger/v12/swaggerFiles"
 	"github.com/yilei-pixocial/kubeapi/pkg/service"
@@ -8,6 +9,10 @@ import (
 	"github.com/yilei-pixocial/kubeapi/router"
 	"github.com/yilei-pixocial/kubeapi/router/middleware"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/iris-contrib/swagger/v12"
 	"github.com/kataras/iris/v12"
@@ -32,22 +37,87 @@ func main() {
 	}
 	InitMiddleware()
 	sysinit.InitLogger()
-	router.SetRoutes(App)
+
+	// 进程级 context：收到 SIGINT/SIGTERM 后同时关闭 HTTP 服务和同步任务
+	ctx, cancel := setupSignalHandling()
+	defer cancel()
 
 	if err := sysinit.InitRedis(); err != nil {
 		log.Fatalf("Failed to initialize Redis: %v", err)
 	}
 
-	service.SyncToRedis()
+	mysqlEnabled := sysinit.GCF.UString("mysql.url") != ""
+	if mysqlEnabled {
+		if err := sysinit.InitMysql(); err != nil {
+			log.Fatalf("Failed to initialize MySQL: %v", err)
+		}
+	}
+
+	clusterConfigs, err := service.LoadClusterConfigs()
+	if err != nil {
+		log.Fatalf("Failed to load cluster config: %v", err)
+	}
+
+	// 每个集群独立的 informer 缓存和同步任务，随进程 context 启停
+	registry := service.NewClusterRegistry(ctx)
+	history, err := service.NewEventHistory()
+	if err != nil {
+		log.Fatalf("Failed to create event history: %v", err)
+	}
+	registry.SetEventHistory(history)
+	auditor, err := service.NewAuditor(sysinit.MysqlSession)
+	if err != nil {
+		log.Fatalf("Failed to create auditor: %v", err)
+	}
+	registry.SetAuditor(auditor)
+	for _, cfg := range clusterConfigs {
+		if _, err := registry.AddConfig(cfg); err != nil {
+			log.Fatalf("Failed to register cluster %s: %v", cfg.ID, err)
+		}
+	}
+
+	// 配置了 mysql 时支持通过 API 动态注册集群
+	var store *service.ClusterStore
+	if mysqlEnabled {
+		key, err := service.LoadSecretKey()
+		if err != nil {
+			log.Fatalf("Failed to load secret key: %v", err)
+		}
+		if store, err = service.NewClusterStore(sysinit.MysqlSession, key); err != nil {
+			log.Fatalf("Failed to create cluster store: %v", err)
+		}
+		if err := store.Reconcile(registry); err != nil {
+			log.Fatalf("Failed to load stored clusters: %v", err)
+		}
+		go store.Run(ctx, registry, sysinit.UDuration("clusterStore.reloadInterval", 30*time.Second))
+	}
+	router.SetRoutes(App, registry, store)
+
 	config := &swagger.Config{
 		URL: fmt.Sprintf("http://localhost:%s/swagger/doc.json", sysinit.GCF.UString("server.port")), //The url pointing to API definition
 	}
 	// use swagger middleware to
 	App.Get("/swagger/*any", swagger.CustomWrapHandler(config, swaggerFiles.Handler))
 
+	go func() {
+		<-ctx.Done()
+		timeout, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		if err := App.Shutdown(timeout); err != nil {
+			log.Printf("Shutdown error: %v", err)
+		}
+	}()
+
 	// 启动
-	run := App.Run(iris.Addr(":"+sysinit.GCF.UString("server.port")), iris.WithCharset("UTF-8"))
-	log.Fatal(run)
+	run := App.Run(iris.Addr(":"+sysinit.GCF.UString("server.port")),
+		iris.WithCharset("UTF-8"),
+		iris.WithoutInterruptHandler,
+		iris.WithoutServerError(iris.ErrServerClosed))
+	cancel()
+	registry.Wait()
+	if run != nil {
+		log.Fatal(run)
+	}
 }
 
 func InitMiddleware() {
@@ -56,3 +126,17 @@ func InitMiddleware() {
 	// 设置限流器
 	middleware.InitHttpLimiter()
 }
+
+func setupSignalHandling() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-sigs
+		log.Printf("Received signal: %v", sig)
+		cancel()
+	}()
+	return ctx, cancel
+}
@@ -1,11 +1,15 @@
 package k8s
 
 import (
+	"fmt"
+
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/util/homedir"
 	"path/filepath"
+	"sort"
 )
 
 func NewClientSet(kubeconfigPath string) (*kubernetes.Clientset, error) {
@@ -17,14 +21,93 @@ func NewClientSet(kubeconfigPath string) (*kubernetes.Clientset, error) {
 }
 
 func NewClientSetFromConfig(kubeconfigPath string) (*kubernetes.Clientset, error) {
+	config, err := NewRestConfig(kubeconfigPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(config)
+}
+
+// NewRestConfig 从 kubeconfig 构建 rest.Config，contextName 为空时使用 current-context
+func NewRestConfig(kubeconfigPath, contextName string) (*rest.Config, error) {
+	return loadingConfig(kubeconfigPath, contextName).ClientConfig()
+}
+
+// ListContexts 返回 kubeconfig 中的所有 context 名称
+func ListContexts(kubeconfigPath string) ([]string, error) {
+	raw, err := loadingConfig(kubeconfigPath, "").RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []string
+	for name := range raw.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, nil
+}
+
+func loadingConfig(kubeconfigPath, contextName string) clientcmd.ClientConfig {
 	if kubeconfigPath == "" {
 		kubeconfigPath = filepath.Join(homedir.HomeDir(), ".kube", "config")
 	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	)
+}
+
+// NewRestConfigFromKubeconfig 从 kubeconfig 内容构建 rest.Config，contextName 为空时使用 current-context
+// kubeconfig 来自 API 请求，只允许内联的凭据，见 validateInlineKubeconfig
+func NewRestConfigFromKubeconfig(kubeconfig []byte, contextName string) (*rest.Config, error) {
+	raw, err := clientcmd.Load(kubeconfig)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateInlineKubeconfig(raw); err != nil {
+		return nil, err
+	}
+	return clientcmd.NewNonInteractiveClientConfig(*raw, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+}
 
-	return kubernetes.NewForConfig(config)
+// validateInlineKubeconfig 拒绝 exec、auth-provider 等会在本机执行命令的认证方式，
+// 以及引用本机文件的证书和 token 路径，只接受 token 和 *-data 字段
+func validateInlineKubeconfig(config *clientcmdapi.Config) error {
+	for name, auth := range config.AuthInfos {
+		switch {
+		case auth.Exec != nil:
+			return fmt.Errorf("user %s: exec is not allowed", name)
+		case auth.AuthProvider != nil:
+			return fmt.Errorf("user %s: auth-provider is not allowed", name)
+		case auth.TokenFile != "":
+			return fmt.Errorf("user %s: tokenFile is not allowed, use token", name)
+		case auth.ClientCertificate != "":
+			return fmt.Errorf("user %s: client-certificate is not allowed, use client-certificate-data", name)
+		case auth.ClientKey != "":
+			return fmt.Errorf("user %s: client-key is not allowed, use client-key-data", name)
+		case auth.Username != "" || auth.Password != "":
+			return fmt.Errorf("user %s: username and password are not allowed", name)
+		}
+	}
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("cluster %s: certificate-authority is not allowed, use certificate-authority-data", name)
+		}
+	}
+	return nil
+}
+
+// NewRestConfigFromToken 使用 bearer token 和 CA 证书构建 rest.Config
+func NewRestConfigFromToken(server, token string, caData []byte, insecure bool) *rest.Config {
+	return &rest.Config{
+		Host:        server,
+		BearerToken: token,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData:   caData,
+			Insecure: insecure,
+		},
+	}
 }
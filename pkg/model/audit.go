@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// 审计结果
+const (
+	AUDIT_RESULT_SUCCESS = "success"
+	AUDIT_RESULT_FAILED  = "failed"
+)
+
+// K8sAudit 对集群的写操作和交互式会话的审计记录
+type K8sAudit struct {
+	ID         uint      `gorm:"primary_key" json:"id"`
+	ClusterID  string    `gorm:"type:varchar(64);index:idx_k8s_audit_cluster_time;not null" json:"clusterID"`
+	User       string    `gorm:"type:varchar(128);index" json:"user"`
+	RemoteAddr string    `gorm:"type:varchar(64)" json:"remoteAddr"`
+	Action     string    `gorm:"type:varchar(32)" json:"action"`
+	Kind       string    `gorm:"type:varchar(64)" json:"kind"`
+	Namespace  string    `gorm:"type:varchar(253)" json:"namespace"`
+	Name       string    `gorm:"type:varchar(253)" json:"name"`
+	Detail     string    `gorm:"type:text" json:"detail"` // 操作参数 JSON，例如 exec 的容器和命令
+	DryRun     bool      `json:"dryRun"`
+	Result     string    `gorm:"type:varchar(16)" json:"result"`
+	Message    string    `gorm:"type:text" json:"message"`
+	StartTime  time.Time `gorm:"index:idx_k8s_audit_cluster_time" json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+}
+
+func (K8sAudit) TableName() string {
+	return "k8s_audit"
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// 集群认证方式
+const (
+	CLUSTER_AUTH_KUBECONFIG = "kubeconfig"
+	CLUSTER_AUTH_TOKEN      = "token"
+)
+
+// K8sCluster 通过 API 注册的集群，凭据字段均为加密后的密文
+type K8sCluster struct {
+	ID         uint      `gorm:"primary_key" json:"-"`
+	ClusterID  string    `gorm:"type:varchar(64);unique_index;not null" json:"clusterID"`
+	Name       string    `gorm:"type:varchar(128)" json:"clusterName"`
+	RegionID   string    `gorm:"type:varchar(64)" json:"clusterRegionID"`
+	AuthType   string    `gorm:"type:varchar(16)" json:"authType"`
+	Server     string    `gorm:"type:varchar(255)" json:"server"`
+	Context    string    `gorm:"type:varchar(128)" json:"context"`
+	Kubeconfig string    `gorm:"type:mediumtext" json:"-"`
+	Token      string    `gorm:"type:text" json:"-"`
+	CAData     string    `gorm:"type:text" json:"-"`
+	Insecure   bool      `json:"insecure"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func (K8sCluster) TableName() string {
+	return "k8s_cluster"
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// K8sEvent 事件历史，同一个 Event 对象只保留最新状态
+// idx_k8s_event_object 用于按关联对象查询
+type K8sEvent struct {
+	ID              uint      `gorm:"primary_key" json:"-"`
+	EventID         string    `gorm:"type:varchar(255);unique_index;not null" json:"eventID"`
+	ClusterID       string    `gorm:"type:varchar(64);index:idx_k8s_event_cluster_time,idx_k8s_event_object;not null" json:"clusterID"`
+	ClusterName     string    `gorm:"type:varchar(128)" json:"clusterName"`
+	Namespace       string    `gorm:"type:varchar(253)" json:"namespace"`
+	Name            string    `gorm:"type:varchar(253)" json:"name"`
+	Type            string    `gorm:"type:varchar(16)" json:"type"`
+	Reason          string    `gorm:"type:varchar(128)" json:"reason"`
+	Message         string    `gorm:"type:text" json:"message"`
+	ObjectKind      string    `gorm:"type:varchar(64)" json:"objectKind"`
+	ObjectNamespace string    `gorm:"type:varchar(253);index:idx_k8s_event_object" json:"objectNamespace"`
+	ObjectName      string    `gorm:"type:varchar(253);index:idx_k8s_event_object" json:"objectName"`
+	ObjectUID       string    `gorm:"type:varchar(64)" json:"objectUID"`
+	FieldPath       string    `gorm:"type:varchar(255)" json:"fieldPath"`
+	Source          string    `gorm:"type:varchar(128)" json:"source"`
+	Count           int32     `json:"count"`
+	FirstTime       time.Time `json:"firstTime"`
+	LastTime        time.Time `gorm:"index:idx_k8s_event_cluster_time,idx_k8s_event_object" json:"lastTime"`
+}
+
+func (K8sEvent) TableName() string {
+	return "k8s_event"
+}
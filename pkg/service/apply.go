@@ -0,0 +1,466 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// AuditApply apply 的审计 action，prune 删除对象时记录为 delete
+const AuditApply = "apply"
+
+const defaultFieldManager = "kubeapi"
+
+// ApplyOptions apply 接口配置
+type ApplyOptions struct {
+	FieldManager   string                    // server-side apply 的 fieldManager，prune 只删除由它 apply 过的对象
+	MaxObjects     int                       // 单次请求最多的对象数，0 表示不限制
+	MaxBytes       int64                     // 请求体最大字节数，0 表示不限制
+	PruneAllowlist []schema.GroupVersionKind // 清单中没有该类型的对象时 prune 也会检查的资源类型
+}
+
+func LoadApplyOptions() ApplyOptions {
+	options := ApplyOptions{
+		FieldManager: sysinit.GCF.UString("apply.fieldManager", defaultFieldManager),
+		MaxObjects:   sysinit.GCF.UInt("apply.maxObjects", 500),
+		MaxBytes:     int64(sysinit.GCF.UInt("apply.maxBytes", 10<<20)),
+	}
+	for _, item := range sysinit.GCF.UList("apply.pruneAllowlist") {
+		value, _ := item.(string)
+		gvk, err := parsePruneGVK(value)
+		if err != nil {
+			logrus.Errorf("Invalid apply.pruneAllowlist %q: %v", value, err)
+			continue
+		}
+		options.PruneAllowlist = append(options.PruneAllowlist, gvk)
+	}
+	return options
+}
+
+// parsePruneGVK 解析 group/version/kind，与 kubectl --prune-allowlist 相同核心组写作 core/v1/ConfigMap，也可以省略为 v1/ConfigMap
+func parsePruneGVK(value string) (schema.GroupVersionKind, error) {
+	parts := strings.Split(value, "/")
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}, nil
+	case len(parts) == 3 && parts[1] != "" && parts[2] != "":
+		if parts[0] == "core" {
+			parts[0] = ""
+		}
+		return schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}, nil
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("expected group/version/kind")
+}
+
+// ApplyManifests
+// @Summary 服务端apply任意资源
+// @Description 请求体为多文档 YAML 或 JSON，支持 kind: List。每个对象通过 discovery 解析资源类型后按文档顺序以 server-side apply 提交，fieldManager 取自 apply.fieldManager。
+// @Description 请求体不能超过 apply.maxBytes。单个对象失败不影响其余对象，有对象失败时返回 500，data 中列出每个对象的结果：created/configured/unchanged/pruned/error。
+// @Description prune=true 时删除匹配 selector、由同一 fieldManager apply 过但不在本次清单中的对象，检查清单中出现的资源类型和命名空间，以及 apply.pruneAllowlist 中的资源类型（命名空间级资源检查清单中出现的命名空间和 namespace 参数），有对象失败时不执行 prune。每个对象写入审计记录。
+// @Tags　k8s
+// @Accept application/yaml
+// @Accept application/json
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace query string false "未指定命名空间的命名空间级对象使用的命名空间，默认 default"
+// @Param dryRun query string false "All 表示只校验不保存"
+// @Param force query bool false "字段被其他 fieldManager 管理时强制获取所有权"
+// @Param prune query bool false "删除不在清单中的对象，需要同时指定 selector"
+// @Param selector query string false "标签选择器，prune 时必填，清单中的对象都必须匹配"
+// @Param body body string true "多文档 YAML 或 JSON"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 500 {object} resp.Message
+// @Router /api/v1/k8s/apply [post]
+// @Router /api/v1/clusters/{cluster}/k8s/apply [post]
+func (k *K8sService) ApplyManifests(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	selector, err := labels.Parse(ctx.URLParam("selector"))
+	if err != nil {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("invalid selector: %v", err)))
+		return
+	}
+	prune := ctx.URLParamDefault("prune", "false") == "true"
+	if prune && selector.Empty() {
+		ctx.JSON(resp.BadResponse("selector is required when prune is enabled"))
+		return
+	}
+	// 对象数在解析后才能检查，先限制请求体大小
+	body := ctx.Request().Body
+	if k.Apply.MaxBytes > 0 {
+		body = http.MaxBytesReader(ctx.ResponseWriter(), body, k.Apply.MaxBytes)
+	}
+	objects, err := decodeManifests(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.StatusCode(iris.StatusRequestEntityTooLarge)
+		}
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	if len(objects) == 0 {
+		ctx.JSON(resp.BadResponse("no objects found in request body"))
+		return
+	}
+	if k.Apply.MaxObjects > 0 && len(objects) > k.Apply.MaxObjects {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("too many objects: %d, at most %d", len(objects), k.Apply.MaxObjects)))
+		return
+	}
+	// 不匹配 selector 的对象会在下次 prune 时被遗漏，提前拒绝
+	for _, obj := range objects {
+		if !selector.Matches(labels.Set(obj.GetLabels())) {
+			ctx.JSON(resp.BadResponse(fmt.Sprintf("%s %s does not match selector %s", obj.GetKind(), obj.GetName(), selector)))
+			return
+		}
+	}
+
+	a := &manifestApplier{
+		ctx:          ctx,
+		cluster:      c,
+		audit:        k.Registry.audit,
+		mapper:       restmapper.NewDeferredDiscoveryRESTMapper(c.Discovery),
+		namespace:    ctx.URLParamDefault("namespace", metav1.NamespaceDefault),
+		fieldManager: k.Apply.FieldManager,
+		force:        ctx.URLParamDefault("force", "false") == "true",
+		dryRun:       dryRun,
+		scopes:       map[pruneKey]*pruneScope{},
+	}
+	result := vo.ApplyResultVo{DryRun: dryRun != nil, FieldManager: a.fieldManager, Objects: []vo.ApplyObjectVo{}}
+	failed := 0
+	for _, obj := range objects {
+		item := a.apply(obj)
+		if item.Result == vo.ApplyError {
+			failed++
+		}
+		result.Objects = append(result.Objects, item)
+	}
+
+	switch {
+	case failed > 0:
+		msg := fmt.Sprintf("%d of %d objects failed", failed, len(objects))
+		if prune {
+			msg += ", prune skipped"
+		}
+		ctx.JSON(resp.Message{Code: resp.MESSAGE_ERROR, Message: msg, Data: result})
+		return
+	case prune:
+		pruned := a.prune(selector, k.Apply.PruneAllowlist)
+		for _, item := range pruned {
+			if item.Result == vo.ApplyError {
+				failed++
+			}
+		}
+		result.Objects = append(result.Objects, pruned...)
+		if failed > 0 {
+			ctx.JSON(resp.Message{Code: resp.MESSAGE_ERROR, Message: fmt.Sprintf("failed to prune %d objects", failed), Data: result})
+			return
+		}
+	}
+	ctx.JSON(resp.OkWithData(result))
+}
+
+// decodeManifests 解析多文档 YAML 或 JSON，跳过空文档，展开 kind: List
+func decodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	var objects []*unstructured.Unstructured
+	for i := 1; ; i++ {
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("document %d: apiVersion and kind are required", i)
+		}
+		if !obj.IsList() {
+			objects = append(objects, obj)
+			continue
+		}
+		err := obj.EachListItem(func(item runtime.Object) error {
+			u := item.(*unstructured.Unstructured)
+			if u.GetAPIVersion() == "" || u.GetKind() == "" {
+				return fmt.Errorf("apiVersion and kind are required")
+			}
+			objects = append(objects, u)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+	}
+}
+
+// pruneScope prune 时检查的资源类型和命名空间，集群级资源的命名空间为空
+type pruneScope struct {
+	gvr        schema.GroupVersionResource
+	gvk        schema.GroupVersionKind
+	namespaced bool
+	namespace  string
+	applied    sets.Set[string] // 本次已 apply 的对象名称
+}
+
+// pruneKey 同一资源可能以不同版本出现在清单中，prune 范围按 GroupResource 和命名空间合并
+type pruneKey struct {
+	resource  schema.GroupResource
+	namespace string
+}
+
+func (s pruneScope) key() pruneKey {
+	return pruneKey{resource: s.gvr.GroupResource(), namespace: s.namespace}
+}
+
+// manifestApplier 依次 apply 清单中的对象，并记录 prune 需要检查的范围
+type manifestApplier struct {
+	ctx          iris.Context
+	cluster      *Cluster
+	audit        *Auditor
+	mapper       *restmapper.DeferredDiscoveryRESTMapper
+	namespace    string // 默认命名空间
+	fieldManager string
+	force        bool
+	dryRun       []string
+	scopes       map[pruneKey]*pruneScope
+}
+
+// mapping 解析资源类型，未找到时刷新 discovery 重试一次（清单中可能刚创建了 CRD）
+func (a *manifestApplier) mapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		a.mapper.Reset()
+		mapping, err = a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
+
+func (a *manifestApplier) apply(obj *unstructured.Unstructured) vo.ApplyObjectVo {
+	item := vo.ApplyObjectVo{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	ri, scope, err := a.resourceFor(obj)
+	if err != nil {
+		item.Result, item.Error = vo.ApplyError, err.Error()
+		return item
+	}
+	item.Namespace = obj.GetNamespace()
+
+	record := a.audit.Begin(a.ctx, a.cluster.ID, AuditApply, item.Kind, item.Namespace, item.Name)
+	record.DryRun = a.dryRun != nil
+	item.Result, err = a.applyObject(ri, obj)
+	auditDetail(record, map[string]interface{}{"apiVersion": item.APIVersion, "fieldManager": a.fieldManager, "force": a.force, "result": item.Result})
+	a.audit.End(record, err)
+	if err != nil {
+		item.Result, item.Error = vo.ApplyError, err.Error()
+		return item
+	}
+	if a.scopes[scope.key()] == nil {
+		scope.applied = sets.New[string]()
+		a.scopes[scope.key()] = &scope
+	}
+	a.scopes[scope.key()].applied.Insert(item.Name)
+	return item
+}
+
+// resourceFor 解析对象的资源类型并补全命名空间
+func (a *manifestApplier) resourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, pruneScope, error) {
+	if obj.GetName() == "" {
+		return nil, pruneScope{}, fmt.Errorf("metadata.name is required")
+	}
+	gvk := obj.GroupVersionKind()
+	mapping, err := a.mapping(gvk)
+	if err != nil {
+		return nil, pruneScope{}, err
+	}
+	scope := pruneScope{gvr: mapping.Resource, gvk: gvk}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() != "" {
+			return nil, scope, fmt.Errorf("%s is cluster-scoped but namespace %s is set", gvk.Kind, obj.GetNamespace())
+		}
+		return a.cluster.Dynamic.Resource(mapping.Resource), scope, nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(a.namespace)
+	}
+	scope.namespaced, scope.namespace = true, obj.GetNamespace()
+	return a.cluster.Dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace()), scope, nil
+}
+
+// applyObject 提交 server-side apply，通过与 apply 前的对象比较判断结果
+func (a *manifestApplier) applyObject(ri dynamic.ResourceInterface, obj *unstructured.Unstructured) (string, error) {
+	reqCtx := a.ctx.Request().Context()
+	existing, err := ri.Get(reqCtx, obj.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	obj.SetManagedFields(nil)
+	applied, err := ri.Apply(reqCtx, obj.GetName(), obj, metav1.ApplyOptions{
+		FieldManager: a.fieldManager,
+		Force:        a.force,
+		DryRun:       a.dryRun,
+	})
+	switch {
+	case err != nil:
+		return "", err
+	case existing == nil:
+		return vo.ApplyCreated, nil
+	case objectChanged(existing, applied):
+		return vo.ApplyConfigured, nil
+	default:
+		return vo.ApplyUnchanged, nil
+	}
+}
+
+// objectChanged 忽略 resourceVersion、managedFields 等 apiserver 维护的字段和 status，dryRun 时同样适用
+func objectChanged(before, after *unstructured.Unstructured) bool {
+	normalize := func(u *unstructured.Unstructured) map[string]interface{} {
+		obj := u.DeepCopy().Object
+		unstructured.RemoveNestedField(obj, "metadata", "resourceVersion")
+		unstructured.RemoveNestedField(obj, "metadata", "managedFields")
+		unstructured.RemoveNestedField(obj, "metadata", "generation")
+		unstructured.RemoveNestedField(obj, "status")
+		return obj
+	}
+	return !reflect.DeepEqual(normalize(before), normalize(after))
+}
+
+// allowlistScopes 返回白名单中清单未覆盖的资源类型的 prune 范围，解析失败的类型返回错误结果
+func (a *manifestApplier) allowlistScopes(allowlist []schema.GroupVersionKind) ([]*pruneScope, []vo.ApplyObjectVo) {
+	covered := sets.New[pruneKey]()
+	namespaces := sets.New(a.namespace)
+	for key, scope := range a.scopes {
+		covered.Insert(key)
+		if scope.namespaced {
+			namespaces.Insert(scope.namespace)
+		}
+	}
+	var scopes []*pruneScope
+	var results []vo.ApplyObjectVo
+	for _, gvk := range allowlist {
+		mapping, err := a.mapping(gvk)
+		if err != nil {
+			results = append(results, vo.ApplyObjectVo{
+				APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind,
+				Result: vo.ApplyError, Error: fmt.Sprintf("failed to resolve prune allowlist: %v", err),
+			})
+			continue
+		}
+		candidates := []pruneScope{{gvr: mapping.Resource, gvk: gvk}}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			candidates = candidates[:0]
+			for _, namespace := range sets.List(namespaces) {
+				candidates = append(candidates, pruneScope{gvr: mapping.Resource, gvk: gvk, namespaced: true, namespace: namespace})
+			}
+		}
+		for _, scope := range candidates {
+			if covered.Has(scope.key()) {
+				continue
+			}
+			covered.Insert(scope.key())
+			scopes = append(scopes, &scope)
+		}
+	}
+	return scopes, results
+}
+
+// prune 删除匹配 selector、由 fieldManager apply 过但本次未 apply 的对象，
+// 检查清单中出现的资源类型以及 allowlist 中的资源类型
+func (a *manifestApplier) prune(selector labels.Selector, allowlist []schema.GroupVersionKind) []vo.ApplyObjectVo {
+	scopes, results := a.allowlistScopes(allowlist)
+	for _, scope := range a.scopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		if scopes[i].gvr.String() != scopes[j].gvr.String() {
+			return scopes[i].gvr.String() < scopes[j].gvr.String()
+		}
+		return scopes[i].namespace < scopes[j].namespace
+	})
+
+	reqCtx := a.ctx.Request().Context()
+	for _, scope := range scopes {
+		var ri dynamic.ResourceInterface = a.cluster.Dynamic.Resource(scope.gvr)
+		if scope.namespaced {
+			ri = a.cluster.Dynamic.Resource(scope.gvr).Namespace(scope.namespace)
+		}
+		list, err := ri.List(reqCtx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			results = append(results, vo.ApplyObjectVo{
+				APIVersion: scope.gvk.GroupVersion().String(), Kind: scope.gvk.Kind, Namespace: scope.namespace,
+				Result: vo.ApplyError, Error: fmt.Sprintf("failed to list for prune: %v", err),
+			})
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if scope.applied.Has(obj.GetName()) || obj.GetDeletionTimestamp() != nil || !appliedBy(obj, a.fieldManager) {
+				continue
+			}
+			results = append(results, a.delete(ri, obj, *scope, selector))
+		}
+	}
+	return results
+}
+
+func (a *manifestApplier) delete(ri dynamic.ResourceInterface, obj *unstructured.Unstructured, scope pruneScope, selector labels.Selector) vo.ApplyObjectVo {
+	item := vo.ApplyObjectVo{APIVersion: scope.gvk.GroupVersion().String(), Kind: scope.gvk.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	record := a.audit.Begin(a.ctx, a.cluster.ID, AuditDelete, item.Kind, item.Namespace, item.Name)
+	record.DryRun = a.dryRun != nil
+	auditDetail(record, map[string]interface{}{"apiVersion": item.APIVersion, "prune": selector.String()})
+	policy := metav1.DeletePropagationBackground
+	err := ri.Delete(a.ctx.Request().Context(), obj.GetName(), metav1.DeleteOptions{
+		DryRun:            a.dryRun,
+		PropagationPolicy: &policy,
+		Preconditions:     metav1.NewUIDPreconditions(string(obj.GetUID())),
+	})
+	a.audit.End(record, err)
+	if err != nil && !apierrors.IsNotFound(err) {
+		item.Result, item.Error = vo.ApplyError, err.Error()
+		return item
+	}
+	item.Result = vo.ApplyPruned
+	return item
+}
+
+// appliedBy 对象是否由 fieldManager 通过 apply 管理，避免 prune 删除其他方式创建的对象
+func appliedBy(obj *unstructured.Unstructured, fieldManager string) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == fieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
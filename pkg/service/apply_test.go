@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery/cached/memory"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDecodeManifests(t *testing.T) {
+	body := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+# 空文档
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: b
+  - apiVersion: v1
+    kind: Service
+    metadata:
+      name: c
+`
+	objects, err := decodeManifests(strings.NewReader(body))
+	if err != nil || len(objects) != 3 || objects[1].GetKind() != "Secret" || objects[2].GetName() != "c" {
+		t.Fatalf("decodeManifests() = %v, %v", objects, err)
+	}
+	objects, err = decodeManifests(strings.NewReader(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}}
+{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}`))
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("decodeManifests(json stream) = %v, %v", objects, err)
+	}
+	if _, err := decodeManifests(strings.NewReader("metadata:\n  name: a\n")); err == nil {
+		t.Error("document without kind should be rejected")
+	}
+}
+
+func configMap(name string, data map[string]interface{}, managedBy string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "demo"},
+		},
+		"data": data,
+	}}
+	if managedBy != "" {
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: managedBy, Operation: metav1.ManagedFieldsOperationApply}})
+	}
+	return obj
+}
+
+func secret(name, managedBy string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "demo"},
+		},
+	}}
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: managedBy, Operation: metav1.ManagedFieldsOperationApply}})
+	return obj
+}
+
+func TestParsePruneGVK(t *testing.T) {
+	for value, want := range map[string]schema.GroupVersionKind{
+		"v1/ConfigMap":          {Version: "v1", Kind: "ConfigMap"},
+		"core/v1/ConfigMap":     {Version: "v1", Kind: "ConfigMap"},
+		"apps/v1/Deployment":    {Group: "apps", Version: "v1", Kind: "Deployment"},
+		"example.com/v1/Widget": {Group: "example.com", Version: "v1", Kind: "Widget"},
+	} {
+		if got, err := parsePruneGVK(value); err != nil || got != want {
+			t.Errorf("parsePruneGVK(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "ConfigMap", "apps//Deployment", "a/b/c/d"} {
+		if _, err := parsePruneGVK(value); err == nil {
+			t.Errorf("parsePruneGVK(%q) should fail", value)
+		}
+	}
+}
+
+// fakeServerSideApply fake tracker 的 apply 要求对象已存在，这里模拟 server-side apply 的创建和替换；
+// fake 客户端不会传递 ApplyOptions，fieldManager 固定为 kubeapi
+func fakeServerSideApply(dyn *dynamicfake.FakeDynamicClient) {
+	dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchActionImpl)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &obj.Object); err != nil {
+			return true, nil, err
+		}
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubeapi", Operation: metav1.ManagedFieldsOperationApply}})
+		_, err := dyn.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		switch {
+		case apierrors.IsNotFound(err):
+			err = dyn.Tracker().Create(patch.GetResource(), obj, patch.GetNamespace())
+		case err == nil:
+			err = dyn.Tracker().Update(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, err
+	})
+}
+
+func TestApplyManifests(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: metav1.Verbs{"get", "list", "patch", "delete"}},
+			{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"get", "list", "patch", "delete"}},
+			{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: metav1.Verbs{"get", "list", "patch", "delete"}},
+		},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+		{Version: "v1", Resource: "namespaces"}: "NamespaceList",
+		{Version: "v1", Resource: "secrets"}:    "SecretList",
+	},
+		configMap("same", map[string]interface{}{"a": "1"}, "kubeapi"),
+		configMap("changed", map[string]interface{}{"a": "1"}, "kubeapi"),
+		configMap("stale", map[string]interface{}{"a": "1"}, "kubeapi"),
+		configMap("manual", map[string]interface{}{"a": "1"}, ""),
+		// 清单中没有 Secret，通过 pruneAllowlist 检查
+		secret("stale-secret", "kubeapi"),
+	)
+	fakeServerSideApply(dyn)
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {
+			ClusterInfo: ClusterInfo{ID: "test"},
+			Dynamic:     dyn,
+			Discovery:   memory.NewMemCacheClient(client.Discovery()),
+		}},
+		order: []string{"test"},
+	}
+	k := &K8sService{Registry: reg, Apply: ApplyOptions{
+		FieldManager:   "kubeapi",
+		MaxObjects:     10,
+		PruneAllowlist: []schema.GroupVersionKind{{Version: "v1", Kind: "ConfigMap"}, {Version: "v1", Kind: "Secret"}},
+	}}
+	app := iris.New()
+	app.Post("/apply", k.ApplyManifests)
+	e := httptest.New(t, app)
+
+	manifest := `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+  labels: {app: demo}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: same, namespace: default, labels: {app: demo}}
+data: {a: "1"}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: changed, labels: {app: demo}}
+data: {a: "2"}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: created, labels: {app: demo}}
+`
+	results := func(obj map[string]interface{}) map[string]string {
+		got := map[string]string{}
+		for _, item := range obj["objects"].([]interface{}) {
+			o := item.(map[string]interface{})
+			got[o["kind"].(string)+"/"+o["name"].(string)] = o["result"].(string)
+		}
+		return got
+	}
+	want := map[string]string{
+		"Namespace/demo":      vo.ApplyCreated,
+		"ConfigMap/same":      vo.ApplyUnchanged,
+		"ConfigMap/changed":   vo.ApplyConfigured,
+		"ConfigMap/created":   vo.ApplyCreated,
+		"ConfigMap/stale":     vo.ApplyPruned,
+		"Secret/stale-secret": vo.ApplyPruned,
+	}
+
+	data := e.POST("/apply").WithQuery("prune", true).WithQuery("selector", "app=demo").
+		WithBytes([]byte(manifest)).Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	data.Value("fieldManager").IsEqual("kubeapi")
+	got := results(data.Raw())
+	for key, result := range want {
+		if got[key] != result {
+			t.Errorf("%s = %q, want %q", key, got[key], result)
+		}
+	}
+	if _, ok := got["ConfigMap/manual"]; ok {
+		t.Error("object not applied by the field manager should not be pruned")
+	}
+	if _, err := dyn.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default").
+		Get(context.Background(), "stale", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("stale object was not pruned: %v", err)
+	}
+
+	data = e.POST("/apply").WithQuery("dryRun", "All").WithBytes([]byte(manifest)).
+		Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	data.Value("dryRun").IsEqual(true)
+	data.Value("objects").Array().Length().IsEqual(4)
+
+	// 有对象失败时不执行 prune
+	body := manifest + "---\napiVersion: example.com/v1\nkind: Widget\nmetadata: {name: w, labels: {app: demo}}\n"
+	msg := e.POST("/apply").WithQuery("prune", true).WithQuery("selector", "app=demo").WithBytes([]byte(body)).
+		Expect().JSON().Object()
+	msg.Value("code").IsEqual(resp.MESSAGE_ERROR)
+	msg.Value("message").String().Contains("prune skipped")
+
+	for name, query := range map[string]map[string]interface{}{
+		"prune without selector": {"prune": true},
+		"selector mismatch":      {"selector": "app=other"},
+		"invalid dryRun":         {"dryRun": "true"},
+	} {
+		code := e.POST("/apply").WithQueryObject(query).WithBytes([]byte(manifest)).Expect().JSON().Object().Value("code")
+		if code.Raw() != float64(resp.MESSAGE_BAD) {
+			t.Errorf("%s: code = %v, want %d", name, code.Raw(), resp.MESSAGE_BAD)
+		}
+	}
+}
+
+func TestApplyPruneAcrossVersions(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	hpa := func(version string) *metav1.APIResourceList {
+		return &metav1.APIResourceList{GroupVersion: "autoscaling/" + version, APIResources: []metav1.APIResource{
+			{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler", Verbs: metav1.Verbs{"get", "list", "patch", "delete"}},
+		}}
+	}
+	client.Resources = []*metav1.APIResourceList{hpa("v2"), hpa("v1")}
+	// fake tracker 按版本分别保存对象，这里 b 预先放在 v2 下，模拟 apiserver 以任意版本都能列出同一个对象
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "b", "namespace": "default", "labels": map[string]interface{}{"app": "demo"}},
+	}}
+	existing.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubeapi", Operation: metav1.ManagedFieldsOperationApply}})
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "autoscaling", Version: "v1", Resource: "horizontalpodautoscalers"}: "HorizontalPodAutoscalerList",
+		{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}: "HorizontalPodAutoscalerList",
+	}, existing)
+	fakeServerSideApply(dyn)
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {
+			ClusterInfo: ClusterInfo{ID: "test"},
+			Dynamic:     dyn,
+			Discovery:   memory.NewMemCacheClient(client.Discovery()),
+		}},
+		order: []string{"test"},
+	}
+	k := &K8sService{Registry: reg, Apply: ApplyOptions{FieldManager: "kubeapi", MaxBytes: 1024}}
+	app := iris.New()
+	app.Post("/apply", k.ApplyManifests)
+	e := httptest.New(t, app)
+
+	manifest := `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata: {name: a, labels: {app: demo}}
+---
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata: {name: b, labels: {app: demo}}
+`
+	data := e.POST("/apply").WithQuery("prune", true).WithQuery("selector", "app=demo").
+		WithBytes([]byte(manifest)).Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	for _, item := range data.Value("objects").Array().Iter() {
+		if item.Object().Value("result").Raw() == vo.ApplyPruned {
+			t.Errorf("object applied with another version was pruned: %v", item.Raw())
+		}
+	}
+
+	// 超过 maxBytes 的请求体在解析时拒绝
+	e.POST("/apply").WithBytes([]byte(strings.Repeat(manifest+"---\n", 20))).
+		Expect().Status(httptest.StatusRequestEntityTooLarge)
+}
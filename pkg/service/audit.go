@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/model"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+)
+
+// defaultAuditUserHeader 认证代理传递用户名的默认请求头
+const defaultAuditUserHeader = "X-Forwarded-User"
+
+// anonymousUser 请求未带用户名时记录的用户
+const anonymousUser = "anonymous"
+
+// Auditor 记录审计日志，始终输出到日志，配置了 mysql 时同时写入 k8s_audit 表
+// nil 表示只输出到日志
+type Auditor struct {
+	db          *gorm.DB
+	userHeader  string
+	requireUser bool
+}
+
+// NewAuditor db 为 nil 时只输出到日志
+func NewAuditor(db *gorm.DB) (*Auditor, error) {
+	if db != nil {
+		if err := db.AutoMigrate(&model.K8sAudit{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to migrate audit table: %w", err)
+		}
+	}
+	return &Auditor{
+		db:          db,
+		userHeader:  sysinit.GCF.UString("audit.userHeader", defaultAuditUserHeader),
+		requireUser: sysinit.GCF.UBool("audit.requireUser", false),
+	}, nil
+}
+
+// RequireUser 中间件，配置 audit.requireUser 时拒绝未携带用户名请求头的请求，
+// 用于写操作和 exec 等需要审计的接口。请求头须由可信的认证代理设置
+func (a *Auditor) RequireUser(ctx iris.Context) {
+	if a != nil && a.requireUser && ctx.GetHeader(a.userHeader) == "" {
+		ctx.StatusCode(iris.StatusUnauthorized)
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("%s header is required", a.userHeader)))
+		return
+	}
+	ctx.Next()
+}
+
+// RequireUser 使用集群注册表的审计配置校验请求的用户名
+func (k *K8sService) RequireUser(ctx iris.Context) {
+	k.Registry.audit.RequireUser(ctx)
+}
+
+// Begin 从请求中取出用户和来源地址，开始一条审计记录
+func (a *Auditor) Begin(ctx iris.Context, clusterID, action, kind, namespace, name string) *model.K8sAudit {
+	header := defaultAuditUserHeader
+	if a != nil {
+		header = a.userHeader
+	}
+	user := ctx.GetHeader(header)
+	if user == "" {
+		user = anonymousUser
+	}
+	return &model.K8sAudit{
+		ClusterID:  clusterID,
+		User:       user,
+		RemoteAddr: ctx.RemoteAddr(),
+		Action:     action,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+		StartTime:  time.Now(),
+	}
+}
+
+// auditDetail 将操作参数序列化为 JSON 写入审计记录
+func auditDetail(record *model.K8sAudit, detail interface{}) {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		logrus.Warnf("Failed to encode audit detail: %v", err)
+		return
+	}
+	record.Detail = string(data)
+}
+
+// End 根据 err 设置结果并写入审计记录
+func (a *Auditor) End(record *model.K8sAudit, err error) {
+	record.EndTime = time.Now()
+	record.Result = model.AUDIT_RESULT_SUCCESS
+	if err != nil {
+		record.Result = model.AUDIT_RESULT_FAILED
+		record.Message = err.Error()
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audit":     record.Action,
+		"user":      record.User,
+		"remote":    record.RemoteAddr,
+		"cluster":   record.ClusterID,
+		"kind":      record.Kind,
+		"namespace": record.Namespace,
+		"name":      record.Name,
+		"detail":    record.Detail,
+		"dryRun":    record.DryRun,
+		"result":    record.Result,
+		"duration":  record.EndTime.Sub(record.StartTime).String(),
+	}).Info(record.Message)
+
+	if a == nil || a.db == nil {
+		return
+	}
+	if err := a.db.Create(record).Error; err != nil {
+		logrus.Errorf("Failed to save audit record %s %s/%s: %v", record.Action, record.Namespace, record.Name, err)
+	}
+}
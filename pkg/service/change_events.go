@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+)
+
+// ErrRevisionTooOld 续传的 revision 已被裁剪出事件 stream，需要重新获取全量数据
+var ErrRevisionTooOld = errors.New("revision is too old")
+
+// ChangePublisher 将资源变化事件写入每个集群的 redis stream，同时发布到 pub/sub 频道
+//
+//	{prefix}{clusterID}:revision  单调递增的事件版本号
+//	{prefix}{clusterID}:events    事件 stream，按 events.consumerGroups 创建消费组
+//	{prefix}{clusterID}:events    同名 pub/sub 频道
+type ChangePublisher struct {
+	client      *redis.Client
+	clusterID   string
+	revisionKey string
+	streamKey   string
+	channel     string
+	maxLen      int64
+	groups      []string
+
+	// 所有 watch 共用一个读取事件 stream 的 goroutine，见 Subscribe
+	mu      sync.Mutex
+	subs    map[*changeSubscriber]struct{}
+	stopHub context.CancelFunc
+}
+
+func NewChangePublisher(client *redis.Client, clusterID string) *ChangePublisher {
+	base := sysinit.GCF.UString("redis.keyPrefix") + clusterID
+	p := &ChangePublisher{
+		client:      client,
+		clusterID:   clusterID,
+		revisionKey: base + ":revision",
+		streamKey:   base + ":events",
+		channel:     base + ":events",
+		maxLen:      int64(sysinit.GCF.UInt("events.streamMaxLen", 10000)),
+	}
+	for _, group := range sysinit.GCF.UList("events.consumerGroups") {
+		if name, ok := group.(string); ok && name != "" {
+			p.groups = append(p.groups, name)
+		}
+	}
+	return p
+}
+
+// EnsureGroups 创建配置中的消费组，已存在时忽略
+func (p *ChangePublisher) EnsureGroups(ctx context.Context) error {
+	for _, group := range p.groups {
+		err := p.client.XGroupCreateMkStream(ctx, p.streamKey, group, "$").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("failed to create consumer group %s: %w", group, err)
+		}
+	}
+	return nil
+}
+
+// Events 为变化分配版本号并生成事件，版本号在事务外预先分配，失败时会留下空洞但保持单调递增
+func (p *ChangePublisher) Events(ctx context.Context, changes []itemChange) ([]vo.ChangeEventVo, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	last, err := p.client.IncrBy(ctx, p.revisionKey, int64(len(changes))).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate revision: %w", err)
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	events := make([]vo.ChangeEventVo, 0, len(changes))
+	for i, change := range changes {
+		event := vo.ChangeEventVo{
+			Revision:  last - int64(len(changes)) + int64(i) + 1,
+			Kind:      change.Kind,
+			ID:        change.ID,
+			ClusterID: p.clusterID,
+			Timestamp: now,
+		}
+		switch {
+		case change.Old == nil:
+			event.Type = vo.EventCreated
+		case change.New == nil:
+			event.Type = vo.EventDeleted
+		default:
+			event.Type = vo.EventUpdated
+		}
+		if change.Old != nil {
+			event.Namespace = change.Old.Namespace
+			event.Old = json.RawMessage(change.Old.Data)
+		}
+		if change.New != nil {
+			event.Namespace = change.New.Namespace
+			event.New = json.RawMessage(change.New.Data)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Publish 在调用方的事务中追加事件，与数据写入一起提交
+func (p *ChangePublisher) Publish(ctx context.Context, pipe redis.Pipeliner, events []vo.ChangeEventVo) {
+	for _, event := range events {
+		b, err := json.Marshal(event)
+		if err != nil {
+			logrus.Errorf("Failed to marshal change event %s: %v", event.ID, err)
+			continue
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: p.streamKey,
+			MaxLen: p.maxLen,
+			Approx: true,
+			Values: map[string]interface{}{
+				"revision": strconv.FormatInt(event.Revision, 10),
+				"type":     event.Type,
+				"kind":     event.Kind,
+				"id":       event.ID,
+				"event":    string(b),
+			},
+		})
+		pipe.Publish(ctx, p.channel, b)
+	}
+}
+
+// Position 返回从 since 之后续传的 stream 游标及对应的 revision，since 为 0 时从最新的事件之后开始
+func (p *ChangePublisher) Position(ctx context.Context, since int64) (string, int64, error) {
+	current, err := p.client.Get(ctx, p.revisionKey).Int64()
+	if err != nil && err != redis.Nil {
+		return "", 0, fmt.Errorf("failed to get revision: %w", err)
+	}
+	if since == 0 {
+		latest, err := p.client.XRevRangeN(ctx, p.streamKey, "+", "-", 1).Result()
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read events: %w", err)
+		}
+		if len(latest) == 0 {
+			return "0-0", current, nil
+		}
+		// revision 在事务外分配，以 stream 中最新的事件为准
+		if revision, ok := streamRevision(latest[0]); ok {
+			current = revision
+		}
+		return latest[0].ID, current, nil
+	}
+
+	if since > current {
+		return "", 0, fmt.Errorf("revision %d is newer than current revision %d", since, current)
+	}
+	oldest, err := p.client.XRangeN(ctx, p.streamKey, "-", "+", 1).Result()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read events: %w", err)
+	}
+	if since < current {
+		if len(oldest) == 0 {
+			return "", 0, fmt.Errorf("%w: %d", ErrRevisionTooOld, since)
+		}
+		if revision, ok := streamRevision(oldest[0]); ok && revision > since+1 {
+			return "", 0, fmt.Errorf("%w: %d", ErrRevisionTooOld, since)
+		}
+	}
+	// 从头读取，由调用方跳过 since 及之前的事件
+	return "0-0", since, nil
+}
+
+// streamEvent 事件及其在 stream 中的 ID，订阅者按 ID 去重
+type streamEvent struct {
+	id    string
+	event vo.ChangeEventVo
+}
+
+// readEvents 读取游标之后的事件，返回新的游标。block 小于 0 时不阻塞，
+// 否则没有新事件时最多阻塞 block，阻塞期间独占一个 redis 连接
+func (p *ChangePublisher) readEvents(ctx context.Context, cursor string, count int64, block time.Duration) ([]streamEvent, string, error) {
+	streams, err := p.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{p.streamKey, cursor},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, cursor, nil
+	}
+	if err != nil {
+		return nil, cursor, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	var events []streamEvent
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			cursor = msg.ID
+			data, _ := msg.Values["event"].(string)
+			var event vo.ChangeEventVo
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				logrus.Warnf("Failed to decode change event %s: %v", msg.ID, err)
+				continue
+			}
+			events = append(events, streamEvent{id: msg.ID, event: event})
+		}
+	}
+	return events, cursor, nil
+}
+
+func streamRevision(msg redis.XMessage) (int64, bool) {
+	value, _ := msg.Values["revision"].(string)
+	revision, err := strconv.ParseInt(value, 10, 64)
+	return revision, err == nil
+}
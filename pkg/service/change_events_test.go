@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+)
+
+func TestDiffItems(t *testing.T) {
+	old := map[string]map[string]storedItem{
+		KindService: {
+			"c/default/a": {Namespace: "default", Data: `{"name":"a"}`},
+			"c/default/b": {Namespace: "default", Data: `{"name":"b"}`},
+		},
+	}
+	next := map[string]map[string]storedItem{
+		KindService: {
+			"c/default/b": {Namespace: "default", Data: `{"name":"b","type":"NodePort"}`},
+			"c/default/c": {Namespace: "default", Data: `{"name":"c"}`},
+		},
+	}
+
+	changes := diffItems(old, next)
+	if len(changes) != 3 {
+		t.Fatalf("diffItems() returned %d changes", len(changes))
+	}
+	want := []struct {
+		id       string
+		old, new bool
+	}{
+		{"c/default/a", true, false},
+		{"c/default/b", true, true},
+		{"c/default/c", false, true},
+	}
+	for i, w := range want {
+		if changes[i].ID != w.id || (changes[i].Old != nil) != w.old || (changes[i].New != nil) != w.new {
+			t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], w)
+		}
+	}
+}
+
+func TestRedisStorePublish(t *testing.T) {
+	mr, client := newTestRedis(t)
+	publisher := &ChangePublisher{
+		client:      client,
+		clusterID:   "test",
+		revisionKey: "cmdb-k8s-test:revision",
+		streamKey:   "cmdb-k8s-test:events",
+		channel:     "cmdb-k8s-test:events",
+		maxLen:      100,
+		groups:      []string{"cmdb"},
+	}
+	store := &RedisStore{client: client, prefix: "cmdb-k8s-", clusterID: "test", publisher: publisher}
+	ctx := context.Background()
+	if err := store.EnsureGroups(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.EnsureGroups(ctx); err != nil {
+		t.Errorf("EnsureGroups() on existing group: %v", err)
+	}
+
+	sub := client.Subscribe(ctx, "cmdb-k8s-test:events")
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	data := ClusterData{
+		ClusterId: "test",
+		Services:  []vo.ServiceVo{{ServiceID: "test/default/web", Name: "web", Namespace: "default"}},
+	}
+	// 首次全量写入不产生事件
+	if err := store.Write(ctx, data, 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if stream, _ := mr.Stream("cmdb-k8s-test:events"); len(stream) != 0 {
+		t.Fatalf("initial write published %d events", len(stream))
+	}
+
+	data.Services = append(data.Services, vo.ServiceVo{ServiceID: "test/default/api", Name: "api", Namespace: "default"})
+	data.Services[0].Type = "NodePort"
+	if err := store.Write(ctx, data, 2, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, _ := mr.Stream("cmdb-k8s-test:events")
+	if len(stream) != 2 {
+		t.Fatalf("stream length = %d", len(stream))
+	}
+	var events []vo.ChangeEventVo
+	for _, entry := range stream {
+		for i := 0; i+1 < len(entry.Values); i += 2 {
+			if entry.Values[i] != "event" {
+				continue
+			}
+			var event vo.ChangeEventVo
+			if err := json.Unmarshal([]byte(entry.Values[i+1]), &event); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, event)
+		}
+	}
+	if events[0].Type != vo.EventCreated || events[0].ID != "test/default/api" || events[0].Revision != 1 {
+		t.Errorf("events[0] = %+v", events[0])
+	}
+	if events[1].Type != vo.EventUpdated || events[1].Old == nil || events[1].New == nil || events[1].Revision != 2 {
+		t.Errorf("events[1] = %+v", events[1])
+	}
+	if mr.HGet("cmdb-k8s-test:meta", "revision") != "2" {
+		t.Errorf("meta revision = %q", mr.HGet("cmdb-k8s-test:meta", "revision"))
+	}
+
+	msg, err := sub.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event vo.ChangeEventVo
+	if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil || event.Revision != 1 {
+		t.Errorf("pub/sub event = %+v, %v", event, err)
+	}
+}
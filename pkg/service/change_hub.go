@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// subscriberBuffer 每个订阅者缓冲的批次数，缓冲满时订阅者改为直接读取 stream 追赶
+const subscriberBuffer = 16
+
+// changeSubscriber 事件 stream 的订阅者，lagged 表示有批次因缓冲满被丢弃
+type changeSubscriber struct {
+	ch     chan []streamEvent
+	lagged atomic.Bool
+}
+
+// Subscribe 订阅事件 stream 中的新事件。每个集群只有一个 goroutine 阻塞读取 stream 并分发给所有订阅者，
+// 避免每个 watch 连接各占一个 redis 连接。第一个订阅者加入时从 stream 的最新位置开始读取，
+// 订阅之前的事件由订阅者通过 CatchUp 读取
+func (p *ChangePublisher) Subscribe(ctx context.Context) (*changeSubscriber, error) {
+	sub := &changeSubscriber{ch: make(chan []streamEvent, subscriberBuffer)}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopHub == nil {
+		// 起始位置需在订阅者追赶之前确定，否则两者之间写入的事件会被遗漏
+		cursor := "0-0"
+		latest, err := p.client.XRevRangeN(ctx, p.streamKey, "+", "-", 1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read events: %w", err)
+		}
+		if len(latest) > 0 {
+			cursor = latest[0].ID
+		}
+		hubCtx, cancel := context.WithCancel(context.Background())
+		p.stopHub = cancel
+		go p.runHub(hubCtx, cursor)
+	}
+	if p.subs == nil {
+		p.subs = map[*changeSubscriber]struct{}{}
+	}
+	p.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Unsubscribe 取消订阅，最后一个订阅者离开时停止读取
+func (p *ChangePublisher) Unsubscribe(sub *changeSubscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.subs, sub)
+	if len(p.subs) == 0 && p.stopHub != nil {
+		p.stopHub()
+		p.stopHub = nil
+	}
+}
+
+// CatchUp 不阻塞地读取 cursor 之后 stream 中已有的事件，直到读完，返回新的游标
+func (p *ChangePublisher) CatchUp(ctx context.Context, cursor string, handle func([]streamEvent) bool) (string, error) {
+	for ctx.Err() == nil {
+		events, next, err := p.readEvents(ctx, cursor, watchReadCount, -1)
+		if err != nil {
+			return cursor, err
+		}
+		if next == cursor {
+			return cursor, nil
+		}
+		cursor = next
+		if !handle(events) {
+			return cursor, nil
+		}
+	}
+	return cursor, nil
+}
+
+// runHub 阻塞读取 stream 并分发，出错时稍后重试，订阅者不受影响
+func (p *ChangePublisher) runHub(ctx context.Context, cursor string) {
+	for ctx.Err() == nil {
+		events, next, err := p.readEvents(ctx, cursor, watchReadCount, watchBlock)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.Warnf("Failed to read change events of cluster %s: %v", p.clusterID, err)
+				select {
+				case <-time.After(time.Second):
+				case <-ctx.Done():
+				}
+			}
+			continue
+		}
+		cursor = next
+		if len(events) > 0 {
+			p.broadcast(events)
+		}
+	}
+}
+
+func (p *ChangePublisher) broadcast(events []streamEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for sub := range p.subs {
+		select {
+		case sub.ch <- events:
+		default:
+			sub.lagged.Store(true)
+		}
+	}
+}
+
+// streamIDAfter stream ID a 是否在 b 之后，ID 格式为 {毫秒}-{序号}
+func streamIDAfter(a, b string) bool {
+	ams, aseq := parseStreamID(a)
+	bms, bseq := parseStreamID(b)
+	if ams != bms {
+		return ams > bms
+	}
+	return aseq > bseq
+}
+
+func parseStreamID(id string) (uint64, uint64) {
+	ms, seq, _ := strings.Cut(id, "-")
+	msValue, _ := strconv.ParseUint(ms, 10, 64)
+	seqValue, _ := strconv.ParseUint(seq, 10, 64)
+	return msValue, seqValue
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/k8s"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+)
+
+// AllClusters 作为集群参数时表示聚合所有集群
+const AllClusters = "all"
+
+// discoveryTimeout 注册集群时探测 API 的超时时间
+const discoveryTimeout = 10 * time.Second
+
+// 集群来源
+const (
+	ClusterSourceConfig = "config" // application.yml
+	ClusterSourceStore  = "store"  // 通过 API 注册并保存在 mysql
+)
+
+// ClusterConfig 集群注册信息
+type ClusterConfig struct {
+	ClusterInfo
+	Kubeconfig string
+	Context    string
+}
+
+// LoadClusterConfigs 读取集群配置，优先级：
+// kubernetes.clusters 列表 > kubernetes.allContexts 加载 kubeconfig 中全部 context > 单集群配置
+func LoadClusterConfigs() ([]ClusterConfig, error) {
+	kubeconfig := sysinit.GCF.UString("kubernetes.kubeconfig")
+
+	if items := sysinit.GCF.UList("kubernetes.clusters"); len(items) > 0 {
+		var configs []ClusterConfig
+		for i := range items {
+			path := fmt.Sprintf("kubernetes.clusters.%d.", i)
+			cfg := ClusterConfig{
+				ClusterInfo: ClusterInfo{
+					ID:       sysinit.GCF.UString(path + "id"),
+					Name:     sysinit.GCF.UString(path + "name"),
+					RegionID: sysinit.GCF.UString(path+"regionID", sysinit.GCF.UString("kubernetes.clusterRegionID")),
+				},
+				Kubeconfig: sysinit.GCF.UString(path+"kubeconfig", kubeconfig),
+				Context:    sysinit.GCF.UString(path + "context"),
+			}
+			if cfg.ID == "" {
+				return nil, fmt.Errorf("kubernetes.clusters[%d].id is empty", i)
+			}
+			if cfg.Name == "" {
+				cfg.Name = cfg.ID
+			}
+			configs = append(configs, cfg)
+		}
+		return configs, nil
+	}
+
+	if sysinit.GCF.UBool("kubernetes.allContexts") {
+		contexts, err := k8s.ListContexts(kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig contexts: %w", err)
+		}
+		var configs []ClusterConfig
+		for _, name := range contexts {
+			configs = append(configs, ClusterConfig{
+				ClusterInfo: ClusterInfo{
+					ID:       name,
+					Name:     name,
+					RegionID: sysinit.GCF.UString("kubernetes.clusterRegionID", "default"),
+				},
+				Kubeconfig: kubeconfig,
+				Context:    name,
+			})
+		}
+		return configs, nil
+	}
+
+	info, err := LoadClusterInfo()
+	if err != nil {
+		return nil, err
+	}
+	return []ClusterConfig{{ClusterInfo: info, Kubeconfig: kubeconfig}}, nil
+}
+
+// Cluster 已注册的集群，包含客户端、informer 缓存和同步任务
+type Cluster struct {
+	ClusterInfo
+	Source    string
+	Config    *rest.Config
+	ClientSet kubernetes.Interface
+	Dynamic   dynamic.Interface
+	Metadata  metadata.Interface // 只需要对象元数据时使用，不读取 Secret 等对象的内容
+	Discovery discovery.CachedDiscoveryInterface
+	Engine    *SyncEngine
+	Syncer    *SyncManager
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	discoveryMu sync.Mutex
+	discoveryAt time.Time // 上次刷新 discovery 缓存的时间
+}
+
+// ClusterRegistry 管理所有集群，集群的生命周期受进程级 context 控制
+type ClusterRegistry struct {
+	ctx     context.Context
+	options SyncOptions
+	history EventHistory // 未配置事件历史时为 nil
+	audit   *Auditor     // 未设置时审计只输出到日志
+
+	mu        sync.RWMutex
+	clusters  map[string]*Cluster
+	order     []string
+	defaultID string
+}
+
+func NewClusterRegistry(ctx context.Context) *ClusterRegistry {
+	return &ClusterRegistry{
+		ctx:       ctx,
+		options:   LoadSyncOptions(),
+		clusters:  map[string]*Cluster{},
+		defaultID: sysinit.GCF.UString("kubernetes.defaultCluster"),
+	}
+}
+
+// SetEventHistory 设置事件历史存储，需在注册集群之前调用
+func (r *ClusterRegistry) SetEventHistory(history EventHistory) {
+	r.history = history
+}
+
+// SetAuditor 设置写操作和交互式会话的审计
+func (r *ClusterRegistry) SetAuditor(audit *Auditor) {
+	r.audit = audit
+}
+
+// AddConfig 根据 kubeconfig 注册集群
+func (r *ClusterRegistry) AddConfig(cfg ClusterConfig) (*Cluster, error) {
+	config, err := k8s.NewRestConfig(cfg.Kubeconfig, cfg.Context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig for cluster %s: %w", cfg.ID, err)
+	}
+	return r.Add(cfg.ClusterInfo, config, ClusterSourceConfig)
+}
+
+// Add 注册集群并启动 informer 和同步任务
+func (r *ClusterRegistry) Add(info ClusterInfo, config *rest.Config, source string) (*Cluster, error) {
+	c, err := r.newCluster(info, config, source)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if _, ok := r.clusters[info.ID]; ok {
+		r.mu.Unlock()
+		c.cancel()
+		return nil, fmt.Errorf("cluster %s already exists", info.ID)
+	}
+	r.clusters[info.ID] = c
+	r.order = append(r.order, info.ID)
+	r.mu.Unlock()
+
+	if err := r.start(c); err != nil {
+		r.Remove(info.ID)
+		return nil, err
+	}
+
+	logrus.Infof("Cluster %s (%s) registered", info.ID, info.Name)
+	return c, nil
+}
+
+// Replace 用新的配置替换集群，集群不存在时直接注册。
+// 新集群启动成功后才换入 registry 并停止原集群，失败时原集群继续工作
+func (r *ClusterRegistry) Replace(info ClusterInfo, config *rest.Config, source string) (*Cluster, error) {
+	if old, ok := r.Get(info.ID); ok && old.Source != source {
+		return nil, fmt.Errorf("cluster %s is registered from %s", info.ID, old.Source)
+	}
+	c, err := r.newCluster(info, config, source)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.start(c); err != nil {
+		c.cancel()
+		return nil, err
+	}
+
+	r.mu.Lock()
+	old, ok := r.clusters[info.ID]
+	r.clusters[info.ID] = c
+	if !ok {
+		r.order = append(r.order, info.ID)
+	}
+	r.mu.Unlock()
+
+	if ok {
+		old.cancel()
+		old.Syncer.Wait()
+	}
+	logrus.Infof("Cluster %s (%s) registered", info.ID, info.Name)
+	return c, nil
+}
+
+// newCluster 创建集群的客户端、informer 和同步任务，不启动也不加入 registry
+func (r *ClusterRegistry) newCluster(info ClusterInfo, config *rest.Config, source string) (*Cluster, error) {
+	if info.ID == "" || info.ID == AllClusters {
+		return nil, fmt.Errorf("invalid cluster ID %q", info.ID)
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientSet for cluster %s: %w", info.ID, err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client for cluster %s: %w", info.ID, err)
+	}
+
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata client for cluster %s: %w", info.ID, err)
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	engine := NewSyncEngine(clientSet, info, r.options)
+	if r.options.GatewayAPI || len(r.options.Resources) > 0 {
+		// 集群不可达时不阻塞注册，只是不同步 CRD 类资源
+		probe := rest.CopyConfig(config)
+		probe.Timeout = discoveryTimeout
+		if disc, err := discovery.NewDiscoveryClientForConfig(probe); err == nil {
+			if r.options.GatewayAPI {
+				engine.WatchGatewayAPI(dynamicClient, disc)
+			}
+			engine.WatchCustomResources(dynamicClient, disc, r.options.Resources)
+		}
+	}
+	return &Cluster{
+		ClusterInfo: info,
+		Source:      source,
+		Config:      config,
+		ClientSet:   clientSet,
+		Dynamic:     dynamicClient,
+		Metadata:    metadataClient,
+		Discovery:   memory.NewMemCacheClient(clientSet.Discovery()),
+		Engine:      engine,
+		Syncer:      NewSyncManager(ctx, engine),
+		ctx:         ctx,
+		cancel:      cancel,
+	}, nil
+}
+
+// start 启动集群的事件记录、informer 和同步任务
+func (r *ClusterRegistry) start(c *Cluster) error {
+	if r.history != nil {
+		recorder := NewEventRecorder(r.history, c.ClusterInfo)
+		c.Engine.OnEvent(recorder.Record)
+		go recorder.Run(c.ctx)
+	}
+	c.Engine.Start(c.ctx)
+	return c.Syncer.Start()
+}
+
+// Remove 注销集群，停止其 informer 和同步任务
+func (r *ClusterRegistry) Remove(id string) error {
+	r.mu.Lock()
+	c, ok := r.clusters[id]
+	if ok {
+		delete(r.clusters, id)
+		for i, item := range r.order {
+			if item == id {
+				r.order = append(r.order[:i:i], r.order[i+1:]...)
+				break
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("cluster %s not found", id)
+	}
+	c.cancel()
+	c.Syncer.Wait()
+	logrus.Infof("Cluster %s removed", id)
+	return nil
+}
+
+func (r *ClusterRegistry) Get(id string) (*Cluster, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.clusters[id]
+	return c, ok
+}
+
+// List 按注册顺序返回所有集群
+func (r *ClusterRegistry) List() []*Cluster {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clusters := make([]*Cluster, 0, len(r.order))
+	for _, id := range r.order {
+		clusters = append(clusters, r.clusters[id])
+	}
+	return clusters
+}
+
+// Default 返回默认集群：kubernetes.defaultCluster，未配置时为第一个注册的集群
+func (r *ClusterRegistry) Default() (*Cluster, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if c, ok := r.clusters[r.defaultID]; ok {
+		return c, true
+	}
+	if len(r.order) == 0 {
+		return nil, false
+	}
+	return r.clusters[r.order[0]], true
+}
+
+// Resolve 根据路径参数或 cluster 查询参数确定请求的目标集群
+// 未指定时使用默认集群，指定 all 时返回所有集群
+func (r *ClusterRegistry) Resolve(ctx iris.Context) ([]*Cluster, error) {
+	id := ctx.Params().Get("cluster")
+	if id == "" {
+		id = ctx.URLParam("cluster")
+	}
+
+	switch id {
+	case "":
+		c, ok := r.Default()
+		if !ok {
+			return nil, fmt.Errorf("no cluster registered")
+		}
+		return []*Cluster{c}, nil
+	case AllClusters:
+		return r.List(), nil
+	default:
+		c, ok := r.Get(id)
+		if !ok {
+			return nil, fmt.Errorf("cluster %s not found", id)
+		}
+		return []*Cluster{c}, nil
+	}
+}
+
+// ResolveOne 解析单个集群，用于查询单个对象的接口，不支持 all
+func (r *ClusterRegistry) ResolveOne(ctx iris.Context) (*Cluster, error) {
+	clusters, err := r.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) != 1 {
+		return nil, fmt.Errorf("a single cluster must be specified")
+	}
+	return clusters[0], nil
+}
+
+// Wait 等待所有集群的同步任务退出
+func (r *ClusterRegistry) Wait() {
+	for _, c := range r.List() {
+		c.Syncer.Wait()
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olebedev/config"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"k8s.io/client-go/rest"
+)
+
+func TestLoadClusterConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    []ClusterConfig
+		wantErr bool
+	}{
+		{
+			"单集群配置",
+			`
+kubernetes:
+  kubeconfig: /etc/kube/config
+  clusterID: test
+  clusterName: 测试集群
+  clusterRegionID: default
+`,
+			[]ClusterConfig{{ClusterInfo: ClusterInfo{ID: "test", Name: "测试集群", RegionID: "default"}, Kubeconfig: "/etc/kube/config"}},
+			false,
+		},
+		{
+			"多集群配置",
+			`
+kubernetes:
+  kubeconfig: /etc/kube/config
+  clusterRegionID: default
+  clusters:
+    - id: prod
+      name: 生产集群
+      regionID: cn-hangzhou
+      context: prod
+    - id: dev
+      kubeconfig: /etc/kube/dev
+`,
+			[]ClusterConfig{
+				{ClusterInfo: ClusterInfo{ID: "prod", Name: "生产集群", RegionID: "cn-hangzhou"}, Kubeconfig: "/etc/kube/config", Context: "prod"},
+				{ClusterInfo: ClusterInfo{ID: "dev", Name: "dev", RegionID: "default"}, Kubeconfig: "/etc/kube/dev"},
+			},
+			false,
+		},
+		{
+			"缺少集群ID",
+			`
+kubernetes:
+  clusters:
+    - name: 无ID集群
+`,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.ParseYaml(tt.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sysinit.GCF = cfg
+
+			got, err := LoadClusterConfigs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadClusterConfigs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("LoadClusterConfigs() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("LoadClusterConfigs()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestClusterRegistryReplace 新配置无法创建客户端时保留原集群
+func TestClusterRegistryReplace(t *testing.T) {
+	old := &Cluster{ClusterInfo: ClusterInfo{ID: "prod"}, Source: ClusterSourceStore}
+	reg := &ClusterRegistry{
+		ctx:      context.Background(),
+		clusters: map[string]*Cluster{"prod": old},
+		order:    []string{"prod"},
+	}
+	broken := &rest.Config{Host: "https://127.0.0.1:6443", TLSClientConfig: rest.TLSClientConfig{CAFile: "/nonexistent/ca.crt"}}
+	if _, err := reg.Replace(old.ClusterInfo, broken, ClusterSourceStore); err == nil {
+		t.Fatal("Replace() with broken config succeeded")
+	}
+	if c, ok := reg.Get("prod"); !ok || c != old {
+		t.Errorf("cluster was not kept after failed replace: %v", c)
+	}
+	if _, err := reg.Replace(old.ClusterInfo, &rest.Config{Host: "https://127.0.0.1:6443"}, ClusterSourceConfig); err == nil {
+		t.Error("Replace() should not replace a cluster from another source")
+	}
+	if len(reg.List()) != 1 {
+		t.Errorf("clusters = %v", reg.List())
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"github.com/kataras/iris/v12"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+)
+
+type ClusterService struct {
+	Registry *ClusterRegistry
+	Store    *ClusterStore // 未配置 mysql 时为空，此时不支持动态注册
+}
+
+// AuditUpdate 更新集群的审计 action，注册和删除使用 AuditCreate、AuditDelete
+const AuditUpdate = "update"
+
+// clusterAuditDetail 审计记录中的集群信息，不包含凭据
+func clusterAuditDetail(req vo.ClusterRequestVo) map[string]interface{} {
+	detail := map[string]interface{}{"clusterName": req.ClusterName, "clusterRegionID": req.ClusterRegionID}
+	switch {
+	case req.Kubeconfig != "":
+		detail["auth"], detail["context"] = "kubeconfig", req.Context
+	case req.Token != "":
+		detail["auth"], detail["server"], detail["insecure"] = "token", req.Server, req.Insecure
+	}
+	return detail
+}
+
+func NewClusterService(registry *ClusterRegistry, store *ClusterStore) *ClusterService {
+	return &ClusterService{Registry: registry, Store: store}
+}
+
+func toClusterVo(c *Cluster) vo.ClusterVo {
+	healthy, err := c.Engine.Health()
+	result := vo.ClusterVo{
+		ClusterID:       c.ID,
+		ClusterName:     c.Name,
+		ClusterRegionID: c.RegionID,
+		Source:          c.Source,
+		K8sVersion:      c.Engine.Version(),
+		Healthy:         healthy,
+		NamespaceCount:  c.Engine.Count(KindNamespace),
+		ServiceCount:    c.Engine.Count(KindService),
+		Sync:            c.Syncer.Status(),
+	}
+	if err != nil {
+		result.HealthError = err.Error()
+	}
+	return result
+}
+
+// GetClusters
+// @Summary 获取已注册的集群及健康状态
+// @Tags　cluster
+// @Produce application/json
+// @Success 200 {object} resp.Message
+// @Router /api/v1/clusters [get]
+func (s *ClusterService) GetClusters(ctx iris.Context) {
+	results := []vo.ClusterVo{}
+	for _, c := range s.Registry.List() {
+		results = append(results, toClusterVo(c))
+	}
+	ctx.JSON(resp.OkWithData(results))
+}
+
+// GetCluster
+// @Summary 获取单个集群信息
+// @Tags　cluster
+// @Produce application/json
+// @Param cluster path string true "集群ID"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/clusters/{cluster} [get]
+func (s *ClusterService) GetCluster(ctx iris.Context) {
+	c, ok := s.Registry.Get(ctx.Params().Get("cluster"))
+	if !ok {
+		ctx.JSON(resp.BadResponse(ErrClusterNotFound.Error()))
+		return
+	}
+	ctx.JSON(resp.OkWithData(toClusterVo(c)))
+}
+
+// CreateCluster
+// @Summary 注册集群
+// @Description 使用 kubeconfig 或 server+token+CA 注册集群，凭据加密后保存在 mysql，注册后立即开始同步
+// @Description kubeconfig 只支持 token 和 *-data 内联凭据，不支持 exec、auth-provider、用户名密码以及引用本机文件的字段
+// @Tags　cluster
+// @Accept application/json
+// @Produce application/json
+// @Param body body vo.ClusterRequestVo true "集群信息"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/clusters [post]
+func (s *ClusterService) CreateCluster(ctx iris.Context) {
+	if s.Store == nil {
+		ctx.JSON(resp.BadResponse("cluster store is not configured"))
+		return
+	}
+	var req vo.ClusterRequestVo
+	if err := ctx.ReadJSON(&req); err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	audit := s.Registry.audit.Begin(ctx, req.ClusterID, AuditCreate, "Cluster", "", req.ClusterID)
+	auditDetail(audit, clusterAuditDetail(req))
+	record, err := s.Store.Register(s.Registry, req)
+	s.Registry.audit.End(audit, err)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	ctx.JSON(resp.OkWithData(record))
+}
+
+// UpdateCluster
+// @Summary 更新集群
+// @Description 未携带 kubeconfig 或 token 时沿用原有凭据
+// @Tags　cluster
+// @Accept application/json
+// @Produce application/json
+// @Param cluster path string true "集群ID"
+// @Param body body vo.ClusterRequestVo true "集群信息"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/clusters/{cluster} [put]
+func (s *ClusterService) UpdateCluster(ctx iris.Context) {
+	if s.Store == nil {
+		ctx.JSON(resp.BadResponse("cluster store is not configured"))
+		return
+	}
+	var req vo.ClusterRequestVo
+	if err := ctx.ReadJSON(&req); err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	id := ctx.Params().Get("cluster")
+	audit := s.Registry.audit.Begin(ctx, id, AuditUpdate, "Cluster", "", id)
+	auditDetail(audit, clusterAuditDetail(req))
+	record, err := s.Store.Update(s.Registry, id, req)
+	s.Registry.audit.End(audit, err)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	ctx.JSON(resp.OkWithData(record))
+}
+
+// DeleteCluster
+// @Summary 删除集群
+// @Tags　cluster
+// @Produce application/json
+// @Param cluster path string true "集群ID"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/clusters/{cluster} [delete]
+func (s *ClusterService) DeleteCluster(ctx iris.Context) {
+	if s.Store == nil {
+		ctx.JSON(resp.BadResponse("cluster store is not configured"))
+		return
+	}
+
+	id := ctx.Params().Get("cluster")
+	audit := s.Registry.audit.Begin(ctx, id, AuditDelete, "Cluster", "", id)
+	err := s.Store.Unregister(s.Registry, id)
+	s.Registry.audit.End(audit, err)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	ctx.JSON(resp.Ok())
+}
+
+// GetSyncStatus
+// @Summary 获取同步任务状态
+// @Tags　sync
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Success 200 {object} resp.Message
+// @Router /api/v1/sync/status [get]
+func (s *ClusterService) GetSyncStatus(ctx iris.Context) {
+	clusters, err := s.resolveSync(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	results := []vo.SyncStatusVo{}
+	for _, c := range clusters {
+		results = append(results, c.Syncer.Status())
+	}
+	ctx.JSON(resp.OkWithData(results))
+}
+
+// StartSync
+// @Summary 启动同步任务
+// @Tags　sync
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/sync/start [post]
+func (s *ClusterService) StartSync(ctx iris.Context) {
+	s.controlSync(ctx, (*SyncManager).Start)
+}
+
+// StopSync
+// @Summary 停止同步任务
+// @Tags　sync
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/sync/stop [post]
+func (s *ClusterService) StopSync(ctx iris.Context) {
+	s.controlSync(ctx, (*SyncManager).Stop)
+}
+
+func (s *ClusterService) controlSync(ctx iris.Context, action func(*SyncManager) error) {
+	clusters, err := s.resolveSync(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	results := []vo.SyncStatusVo{}
+	for _, c := range clusters {
+		if err := action(c.Syncer); err != nil && len(clusters) == 1 {
+			ctx.JSON(resp.BadResponse(err.Error()))
+			return
+		}
+		results = append(results, c.Syncer.Status())
+	}
+	ctx.JSON(resp.OkWithData(results))
+}
+
+// resolveSync 同步任务接口未指定集群时作用于所有集群
+func (s *ClusterService) resolveSync(ctx iris.Context) ([]*Cluster, error) {
+	if ctx.URLParam("cluster") == "" {
+		return s.Registry.List(), nil
+	}
+	return s.Registry.Resolve(ctx)
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/k8s"
+	"github.com/yilei-pixocial/kubeapi/pkg/model"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/util"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var ErrClusterNotFound = errors.New("cluster not found")
+
+// LoadSecretKey 读取凭据加密密钥，环境变量 KUBEAPI_SECRET_KEY 优先于 security.secretKey，
+// 值为 base64 编码的 16/24/32 字节密钥
+func LoadSecretKey() ([]byte, error) {
+	value := os.Getenv("KUBEAPI_SECRET_KEY")
+	if value == "" {
+		value = sysinit.GCF.UString("security.secretKey")
+	}
+	if value == "" {
+		return nil, errors.New("secret key is not configured, set KUBEAPI_SECRET_KEY or security.secretKey")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("secret key is not valid base64: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("secret key must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+}
+
+// ClusterStore 将通过 API 注册的集群保存在 mysql，凭据使用 AES-GCM 加密。
+// 多个 kubeapi 实例共用同一个库时，通过定期 Reconcile 发现其他实例注册的集群。
+type ClusterStore struct {
+	db  *gorm.DB
+	key []byte
+
+	mu    sync.Mutex
+	known map[string]time.Time // 已加载到 registry 的集群及其 UpdatedAt
+}
+
+func NewClusterStore(db *gorm.DB, key []byte) (*ClusterStore, error) {
+	if err := db.AutoMigrate(&model.K8sCluster{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to migrate cluster table: %w", err)
+	}
+	return &ClusterStore{
+		db:    db,
+		key:   key,
+		known: map[string]time.Time{},
+	}, nil
+}
+
+func (s *ClusterStore) List() ([]model.K8sCluster, error) {
+	var records []model.K8sCluster
+	if err := s.db.Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (s *ClusterStore) Get(id string) (model.K8sCluster, error) {
+	var record model.K8sCluster
+	err := s.db.Where("cluster_id = ?", id).First(&record).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return record, ErrClusterNotFound
+	}
+	return record, err
+}
+
+// Register 校验并保存集群，然后加入 registry
+func (s *ClusterStore) Register(registry *ClusterRegistry, req vo.ClusterRequestVo) (model.K8sCluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := registry.Get(req.ClusterID); ok {
+		return model.K8sCluster{}, fmt.Errorf("cluster %s already exists", req.ClusterID)
+	}
+	if _, err := s.Get(req.ClusterID); err == nil {
+		return model.K8sCluster{}, fmt.Errorf("cluster %s already exists", req.ClusterID)
+	}
+
+	record := model.K8sCluster{}
+	if err := s.fill(&record, req); err != nil {
+		return record, err
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return record, fmt.Errorf("failed to save cluster: %w", err)
+	}
+	return s.load(registry, record.ClusterID)
+}
+
+// Update 更新集群信息，请求中未携带凭据时沿用原有凭据
+func (s *ClusterStore) Update(registry *ClusterRegistry, id string, req vo.ClusterRequestVo) (model.K8sCluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := registry.Get(id); ok && c.Source != ClusterSourceStore {
+		return model.K8sCluster{}, fmt.Errorf("cluster %s is defined in config and cannot be modified", id)
+	}
+	record, err := s.Get(id)
+	if err != nil {
+		return record, err
+	}
+	previous := record
+	req.ClusterID = id
+	if err := s.fill(&record, req); err != nil {
+		return record, err
+	}
+	if err := s.db.Save(&record).Error; err != nil {
+		return record, fmt.Errorf("failed to save cluster: %w", err)
+	}
+
+	// 新配置注册失败时 registry 保留原集群，同时恢复原记录使两者一致
+	updated, err := s.load(registry, id)
+	if err != nil {
+		if rerr := s.db.Save(&previous).Error; rerr != nil {
+			logrus.Errorf("Failed to restore cluster %s: %v", id, rerr)
+		} else if restored, rerr := s.Get(id); rerr == nil {
+			s.known[id] = restored.UpdatedAt
+		}
+	}
+	return updated, err
+}
+
+// Unregister 删除集群并从 registry 中移除
+func (s *ClusterStore) Unregister(registry *ClusterRegistry, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := registry.Get(id); ok && c.Source != ClusterSourceStore {
+		return fmt.Errorf("cluster %s is defined in config and cannot be deleted", id)
+	}
+	record, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(&record).Error; err != nil {
+		return fmt.Errorf("failed to delete cluster: %w", err)
+	}
+	if c, ok := registry.Get(id); ok && c.Source == ClusterSourceStore {
+		registry.Remove(id)
+	}
+	delete(s.known, id)
+	return nil
+}
+
+// Run 定期将 mysql 中的集群同步到 registry，随 ctx 取消而退出
+func (s *ClusterStore) Run(ctx context.Context, registry *ClusterRegistry, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Reconcile(registry); err != nil {
+				logrus.Errorf("Failed to reconcile clusters: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reconcile 新增或更新过的集群重新注册，已删除的集群从 registry 中移除
+func (s *ClusterStore) Reconcile(registry *ClusterRegistry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.List()
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for _, record := range records {
+		seen[record.ClusterID] = true
+		if updatedAt, ok := s.known[record.ClusterID]; ok && updatedAt.Equal(record.UpdatedAt) {
+			continue
+		}
+		if c, ok := registry.Get(record.ClusterID); ok && c.Source != ClusterSourceStore {
+			logrus.Warnf("Cluster %s is defined in config, ignore stored registration", record.ClusterID)
+			s.known[record.ClusterID] = record.UpdatedAt
+			continue
+		}
+		if err := s.register(registry, record); err != nil {
+			logrus.Errorf("Failed to register cluster %s: %v", record.ClusterID, err)
+		}
+	}
+
+	for id := range s.known {
+		if seen[id] {
+			continue
+		}
+		if c, ok := registry.Get(id); ok && c.Source == ClusterSourceStore {
+			registry.Remove(id)
+		}
+		delete(s.known, id)
+	}
+	return nil
+}
+
+// load 重新读取记录（获取数据库生成的时间戳）并注册
+func (s *ClusterStore) load(registry *ClusterRegistry, id string) (model.K8sCluster, error) {
+	record, err := s.Get(id)
+	if err != nil {
+		return record, err
+	}
+	return record, s.register(registry, record)
+}
+
+// register 需在持有 s.mu 时调用，无论成功与否都记录版本，避免反复重试错误的配置。
+// 集群已注册时替换为新配置，失败时保留原集群
+func (s *ClusterStore) register(registry *ClusterRegistry, record model.K8sCluster) error {
+	s.known[record.ClusterID] = record.UpdatedAt
+
+	config, err := s.RestConfig(record)
+	if err != nil {
+		return err
+	}
+	_, err = registry.Replace(ClusterInfo{
+		ID:       record.ClusterID,
+		Name:     record.Name,
+		RegionID: record.RegionID,
+	}, config, ClusterSourceStore)
+	return err
+}
+
+// RestConfig 解密凭据并构建 rest.Config
+func (s *ClusterStore) RestConfig(record model.K8sCluster) (*rest.Config, error) {
+	switch record.AuthType {
+	case model.CLUSTER_AUTH_KUBECONFIG:
+		kubeconfig, err := util.AesGcmDecrypt(s.key, record.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+		}
+		return k8s.NewRestConfigFromKubeconfig([]byte(kubeconfig), record.Context)
+	case model.CLUSTER_AUTH_TOKEN:
+		token, err := util.AesGcmDecrypt(s.key, record.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt token: %w", err)
+		}
+		return k8s.NewRestConfigFromToken(record.Server, token, []byte(record.CAData), record.Insecure), nil
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", record.AuthType)
+	}
+}
+
+// fill 校验请求并写入记录，凭据加密保存；写入前会尝试连接集群
+func (s *ClusterStore) fill(record *model.K8sCluster, req vo.ClusterRequestVo) error {
+	if req.ClusterID == "" || req.ClusterID == AllClusters {
+		return fmt.Errorf("invalid cluster ID %q", req.ClusterID)
+	}
+	record.ClusterID = req.ClusterID
+	if req.ClusterName != "" {
+		record.Name = req.ClusterName
+	}
+	if record.Name == "" {
+		record.Name = req.ClusterID
+	}
+	if req.ClusterRegionID != "" {
+		record.RegionID = req.ClusterRegionID
+	}
+
+	var err error
+	switch {
+	case req.Kubeconfig != "":
+		record.AuthType = model.CLUSTER_AUTH_KUBECONFIG
+		record.Context = req.Context
+		record.Server, record.Token, record.CAData, record.Insecure = "", "", "", false
+		if record.Kubeconfig, err = util.AesGcmEncrypt(s.key, req.Kubeconfig); err != nil {
+			return err
+		}
+	case req.Token != "":
+		if req.Server == "" {
+			return errors.New("server is required when using token")
+		}
+		caData, err := decodeCAData(req.CAData)
+		if err != nil {
+			return err
+		}
+		record.AuthType = model.CLUSTER_AUTH_TOKEN
+		record.Server, record.CAData, record.Insecure = req.Server, caData, req.Insecure
+		record.Kubeconfig, record.Context = "", ""
+		if record.Token, err = util.AesGcmEncrypt(s.key, req.Token); err != nil {
+			return err
+		}
+	case record.AuthType == "":
+		return errors.New("kubeconfig or server and token is required")
+	}
+
+	config, err := s.RestConfig(*record)
+	if err != nil {
+		return err
+	}
+	if record.AuthType == model.CLUSTER_AUTH_KUBECONFIG {
+		record.Server = config.Host
+	}
+	return checkConnection(config)
+}
+
+// decodeCAData 支持直接传 PEM 或 base64 编码后的 PEM
+func decodeCAData(caData string) (string, error) {
+	if caData == "" || strings.Contains(caData, "-----BEGIN") {
+		return caData, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(caData)
+	if err != nil {
+		return "", fmt.Errorf("caData is neither PEM nor base64: %w", err)
+	}
+	return string(b), nil
+}
+
+// checkConnection 访问 apiserver 版本接口验证凭据
+func checkConnection(config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config.Timeout = 10 * time.Second
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	if _, err := clientSet.Discovery().ServerVersion(); err != nil {
+		return fmt.Errorf("failed to connect to cluster: %w", err)
+	}
+	return nil
+}
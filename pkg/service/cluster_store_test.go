@@ -0,0 +1,144 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yilei-pixocial/kubeapi/pkg/model"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+)
+
+func TestClusterStoreFill(t *testing.T) {
+	apiserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"major":"1","minor":"33","gitVersion":"v1.33.3"}`)
+	}))
+	defer apiserver.Close()
+
+	store := &ClusterStore{key: []byte("0123456789abcdef0123456789abcdef")}
+	record := model.K8sCluster{}
+	err := store.fill(&record, vo.ClusterRequestVo{ClusterID: "prod", Server: apiserver.URL, Token: "secret-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.AuthType != model.CLUSTER_AUTH_TOKEN || record.Name != "prod" || record.Token == "secret-token" {
+		t.Errorf("record = %+v", record)
+	}
+
+	config, err := store.RestConfig(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.BearerToken != "secret-token" || config.Host != apiserver.URL {
+		t.Errorf("RestConfig() = %+v", config)
+	}
+
+	// 未携带凭据时沿用原有凭据
+	if err := store.fill(&record, vo.ClusterRequestVo{ClusterID: "prod", ClusterName: "生产集群"}); err != nil {
+		t.Fatal(err)
+	}
+	if record.Name != "生产集群" || record.AuthType != model.CLUSTER_AUTH_TOKEN {
+		t.Errorf("record = %+v", record)
+	}
+
+	if err := store.fill(&model.K8sCluster{}, vo.ClusterRequestVo{ClusterID: "dev", Server: apiserver.URL, Token: "wrong"}); err == nil {
+		t.Errorf("fill() with wrong token succeeded")
+	}
+	if err := store.fill(&model.K8sCluster{}, vo.ClusterRequestVo{ClusterID: AllClusters, Server: apiserver.URL, Token: "secret-token"}); err == nil {
+		t.Errorf("fill() with reserved cluster ID succeeded")
+	}
+}
+
+func TestClusterStoreFillKubeconfig(t *testing.T) {
+	apiserver := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"major":"1","minor":"33","gitVersion":"v1.33.3"}`)
+	}))
+	defer apiserver.Close()
+
+	// clientcmd 只在 TLS 连接上使用 kubeconfig 中的用户凭据
+	kubeconfig := func(cluster, user string) string {
+		return fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: prod
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+clusters:
+- name: prod
+  cluster: {server: %q, insecure-skip-tls-verify: true%s}
+users:
+- name: admin
+  user: {%s}
+`, apiserver.URL, cluster, user)
+	}
+	store := &ClusterStore{key: []byte("0123456789abcdef0123456789abcdef")}
+	record := model.K8sCluster{}
+	if err := store.fill(&record, vo.ClusterRequestVo{ClusterID: "prod", Kubeconfig: kubeconfig("", "token: secret-token")}); err != nil {
+		t.Fatal(err)
+	}
+	if record.AuthType != model.CLUSTER_AUTH_KUBECONFIG || record.Server != apiserver.URL {
+		t.Errorf("record = %+v", record)
+	}
+
+	// 会在本机执行命令或读取本机文件的配置直接拒绝
+	for name, config := range map[string]string{
+		"exec":                  kubeconfig("", "exec: {apiVersion: client.authentication.k8s.io/v1, command: sh}"),
+		"auth-provider":         kubeconfig("", "auth-provider: {name: oidc}"),
+		"tokenFile":             kubeconfig("", "tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token"),
+		"client-certificate":    kubeconfig("", "client-certificate: /etc/tls.crt"),
+		"client-key":            kubeconfig("", "client-key: /etc/tls.key"),
+		"username":              kubeconfig("", "username: admin, password: secret"),
+		"certificate-authority": kubeconfig(", certificate-authority: /etc/ca.crt", "token: secret-token"),
+	} {
+		if err := store.fill(&model.K8sCluster{}, vo.ClusterRequestVo{ClusterID: "dev", Kubeconfig: config}); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("%s: fill() = %v, want not allowed", name, err)
+		}
+	}
+}
+
+func TestDecodeCAData(t *testing.T) {
+	pem := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	tests := []struct {
+		name    string
+		caData  string
+		want    string
+		wantErr bool
+	}{
+		{"PEM", pem, pem, false},
+		{"base64", base64.StdEncoding.EncodeToString([]byte(pem)), pem, false},
+		{"空", "", "", false},
+		{"非法", "not base64!", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCAData(tt.caData)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCAData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decodeCAData() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterAuditDetail(t *testing.T) {
+	detail := clusterAuditDetail(vo.ClusterRequestVo{ClusterID: "prod", Server: "https://prod", Token: "secret-token", CAData: "ca"})
+	if detail["auth"] != "token" || detail["server"] != "https://prod" {
+		t.Errorf("detail = %v", detail)
+	}
+	if strings.Contains(fmt.Sprint(detail), "secret-token") {
+		t.Errorf("detail contains credentials: %v", detail)
+	}
+}
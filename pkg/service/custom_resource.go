@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ResourceSpec sync.resources 中配置的自定义资源
+type ResourceSpec struct {
+	GVR    schema.GroupVersionResource
+	Name   string            // 在 ClusterData 和 redis 中使用的名称，默认 resource.group
+	Fields map[string]string // 字段名 -> JSONPath
+}
+
+// loadResourceSpecs 读取 sync.resources，配置有误的条目记录日志后跳过
+func loadResourceSpecs() []ResourceSpec {
+	var specs []ResourceSpec
+	names := map[string]bool{}
+	for i := range sysinit.GCF.UList("sync.resources") {
+		path := fmt.Sprintf("sync.resources.%d.", i)
+		spec, err := newResourceSpec(
+			sysinit.GCF.UString(path+"group"),
+			sysinit.GCF.UString(path+"version"),
+			sysinit.GCF.UString(path+"resource"),
+			sysinit.GCF.UString(path+"name"),
+			sysinit.GCF.UMap(path+"fields"),
+		)
+		if err == nil && names[spec.Name] {
+			err = fmt.Errorf("duplicate name %s", spec.Name)
+		}
+		if err != nil {
+			logrus.Errorf("Invalid sync.resources[%d]: %v", i, err)
+			continue
+		}
+		names[spec.Name] = true
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func newResourceSpec(group, version, resource, name string, fields map[string]interface{}) (ResourceSpec, error) {
+	if version == "" || resource == "" {
+		return ResourceSpec{}, fmt.Errorf("version and resource are required")
+	}
+	spec := ResourceSpec{
+		GVR:    schema.GroupVersionResource{Group: group, Version: version, Resource: resource},
+		Name:   name,
+		Fields: map[string]string{},
+	}
+	if spec.Name == "" {
+		spec.Name = spec.GVR.GroupResource().String()
+	}
+	// 名称会作为 redis key 的一部分，避免与内置类型冲突
+	if builtinKinds[spec.Name] {
+		return ResourceSpec{}, fmt.Errorf("name %s is reserved", spec.Name)
+	}
+
+	for field, value := range fields {
+		expr, ok := value.(string)
+		if !ok {
+			return ResourceSpec{}, fmt.Errorf("field %s: JSONPath must be a string", field)
+		}
+		expr = relaxedJSONPath(expr)
+		if err := jsonpath.New(field).Parse(expr); err != nil {
+			return ResourceSpec{}, fmt.Errorf("field %s: %w", field, err)
+		}
+		spec.Fields[field] = expr
+	}
+	return spec, nil
+}
+
+// relaxedJSONPath 允许省略外层的 {}，如 .status.phase
+func relaxedJSONPath(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{") {
+		return expr
+	}
+	return "{" + expr + "}"
+}
+
+// WatchCustomResources 同步配置的自定义资源，集群未提供的资源跳过，需在 Start 之前调用
+func (e *SyncEngine) WatchCustomResources(client dynamic.Interface, disc discovery.DiscoveryInterface, specs []ResourceSpec) {
+	for _, spec := range specs {
+		kind, namespaced, err := discoverResource(disc, spec.GVR)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				logrus.Infof("Resource %s is not served by cluster %s, skipped", spec.GVR.String(), e.Cluster.ID)
+			} else {
+				logrus.Warnf("Failed to discover resource %s in cluster %s: %v", spec.GVR.String(), e.Cluster.ID, err)
+			}
+			continue
+		}
+
+		if e.dynamicFactory == nil {
+			e.dynamicFactory = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+		}
+		spec := spec
+		e.watch(spec.Name, e.dynamicInformer(spec.GVR), func(obj interface{}) interface{} {
+			return toCustomResourceVo(e.Cluster, spec, kind, namespaced, obj.(*unstructured.Unstructured))
+		})
+		e.customKinds = append(e.customKinds, spec.Name)
+	}
+}
+
+// discoverResource 返回资源的 Kind 和是否为命名空间级资源
+func discoverResource(disc discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (string, bool, error) {
+	list, err := disc.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return "", false, err
+	}
+	for _, r := range list.APIResources {
+		if r.Name == gvr.Resource {
+			return r.Kind, r.Namespaced, nil
+		}
+	}
+	return "", false, apierrors.NewNotFound(gvr.GroupResource(), "")
+}
+
+func toCustomResourceVo(cluster ClusterInfo, spec ResourceSpec, kind string, namespaced bool, obj *unstructured.Unstructured) vo.CustomResourceVo {
+	r := vo.CustomResourceVo{
+		ResourceID:  fmt.Sprintf("%s/%s/%s", cluster.ID, kind, obj.GetName()),
+		Group:       spec.GVR.Group,
+		Version:     spec.GVR.Version,
+		Resource:    spec.GVR.Resource,
+		Kind:        kind,
+		Name:        obj.GetName(),
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		Labels:      obj.GetLabels(),
+		Fields:      projectFields(spec.Fields, obj.Object),
+		CreateTime:  obj.GetCreationTimestamp().Local().Format(timeLayout),
+	}
+	if namespaced {
+		r.ResourceID = fmt.Sprintf("%s/%s/%s/%s", cluster.ID, obj.GetNamespace(), kind, obj.GetName())
+		r.Namespace = obj.GetNamespace()
+		r.NamespaceID = fmt.Sprintf("%s/%s", cluster.ID, obj.GetNamespace())
+	}
+	return r
+}
+
+func customResourceItem(r vo.CustomResourceVo) req.ListItem {
+	return req.ListItem{ID: r.ResourceID, CreateTime: r.CreateTime, Name: r.Name, Namespace: r.Namespace, Labels: r.Labels}
+}
+
+// projectFields 按 JSONPath 提取字段，未匹配时为 nil，匹配多个值时为数组
+func projectFields(fields map[string]string, obj map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for field, expr := range fields {
+		// JSONPath 求值过程有内部状态，每次重新解析以保证并发安全
+		j := jsonpath.New(field).AllowMissingKeys(true)
+		if err := j.Parse(expr); err != nil {
+			continue
+		}
+		results, err := j.FindResults(obj)
+		if err != nil {
+			logrus.Debugf("Failed to evaluate %s: %v", expr, err)
+			result[field] = nil
+			continue
+		}
+
+		var values []interface{}
+		for _, group := range results {
+			for _, v := range group {
+				if v.IsValid() && v.CanInterface() {
+					values = append(values, v.Interface())
+				}
+			}
+		}
+		switch len(values) {
+		case 0:
+			result[field] = nil
+		case 1:
+			result[field] = values[0]
+		default:
+			result[field] = values
+		}
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewResourceSpec(t *testing.T) {
+	spec, err := newResourceSpec("argoproj.io", "v1alpha1", "rollouts", "", map[string]interface{}{"phase": ".status.phase"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Name != "rollouts.argoproj.io" || spec.Fields["phase"] != "{.status.phase}" {
+		t.Fatalf("spec = %+v", spec)
+	}
+
+	if _, err := newResourceSpec("argoproj.io", "v1alpha1", "rollouts", "", map[string]interface{}{"bad": "{.status["}); err == nil {
+		t.Fatal("expected error for invalid JSONPath")
+	}
+	if _, err := newResourceSpec("", "v1", "pods", KindPod, nil); err == nil {
+		t.Fatal("expected error for reserved name")
+	}
+}
+
+func TestWatchCustomResources(t *testing.T) {
+	rollouts := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+	rollout := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": "nginx:1.27"},
+				map[string]interface{}{"name": "sidecar", "image": "envoy:1.30"},
+			}}},
+		},
+	}}
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "argoproj.io/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "rollouts", Namespaced: true, Kind: "Rollout"}},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{rollouts: "RolloutList"}, rollout)
+
+	spec, err := newResourceSpec("argoproj.io", "v1alpha1", "rollouts", "", map[string]interface{}{
+		"replicas": "{.spec.replicas}",
+		"images":   "{.spec.template.spec.containers[*].image}",
+		"phase":    "{.status.phase}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, _ := newResourceSpec("cert-manager.io", "v1", "certificates", "", nil)
+
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{})
+	engine.WatchCustomResources(dynamicClient, client.Discovery(), []ResourceSpec{spec, missing})
+	if engine.Watching(missing.Name) {
+		t.Fatal("resources not served by the cluster should be skipped")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+
+	data, _ := engine.Snapshot()
+	items := data.Resources["rollouts.argoproj.io"]
+	if len(items) != 1 {
+		t.Fatalf("resources = %+v", data.Resources)
+	}
+	r := items[0]
+	want := map[string]interface{}{
+		"replicas": int64(3),
+		"images":   []interface{}{"nginx:1.27", "envoy:1.30"},
+		"phase":    nil,
+	}
+	if r.ResourceID != "test/default/Rollout/web" || !reflect.DeepEqual(r.Fields, want) {
+		t.Fatalf("resource = %+v", r)
+	}
+	if _, ok := data.Items()["rollouts.argoproj.io"]; !ok {
+		t.Fatal("custom resources should be written to redis")
+	}
+}
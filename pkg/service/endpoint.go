@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// endpointSliceServiceIndex EndpointSlice 按 namespace/service 名称建立的索引
+const endpointSliceServiceIndex = "service"
+
+// GetServiceEndpoints
+// @Summary 获取service的后端endpoint
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "service名称"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/services/{namespace}/{name}/endpoints [get]
+// @Router /api/v1/clusters/{cluster}/k8s/services/{namespace}/{name}/endpoints [get]
+func (k *K8sService) GetServiceEndpoints(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+
+	var svc vo.ServiceVo
+	if c.Engine.Cached(KindService) && c.Engine.endpointSlices != nil {
+		item, ok := c.Engine.Get(KindService, namespace+"/"+name)
+		if !ok {
+			notFound(ctx, fmt.Sprintf("service %s/%s not found", namespace, name))
+			return
+		}
+		svc = item.(vo.ServiceVo)
+	} else {
+		reqCtx := ctx.Request().Context()
+		service, err := c.ClientSet.CoreV1().Services(namespace).Get(reqCtx, name, metav1.GetOptions{})
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		sliceList, err := c.ClientSet.DiscoveryV1().EndpointSlices(namespace).List(reqCtx, metav1.ListOptions{
+			LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+		})
+		if err != nil {
+			ctx.JSON(resp.ErrorWithMsg(err.Error()))
+			return
+		}
+		svc = toServiceVo(c.ClusterInfo, service)
+		setEndpoints(&svc, service, sliceListItems(sliceList))
+	}
+
+	ctx.JSON(resp.OkWithData(vo.ServiceEndpointsVo{
+		ServiceID:        svc.ServiceID,
+		Name:             svc.Name,
+		Namespace:        svc.Namespace,
+		ClusterID:        svc.ClusterID,
+		Type:             svc.Type,
+		Endpoints:        svc.Endpoints,
+		ReadyEndpoints:   svc.ReadyEndpoints,
+		NoReadyEndpoints: svc.NoReadyEndpoints,
+	}))
+}
+
+// watchEndpointSlices 缓存 EndpointSlice，变化时重新计算所属 service 的后端
+func (e *SyncEngine) watchEndpointSlices(serviceInformer cache.SharedIndexInformer) {
+	sliceInformer := e.factory.Discovery().V1().EndpointSlices().Informer()
+	if err := sliceInformer.AddIndexers(cache.Indexers{endpointSliceServiceIndex: indexSliceByService}); err != nil {
+		logrus.Errorf("Failed to add endpointslice indexer: %v", err)
+	}
+	e.endpointSlices = sliceInformer.GetIndexer()
+	e.informers = append(e.informers, sliceInformer)
+
+	refresh := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		slice, ok := obj.(*discoveryv1.EndpointSlice)
+		if !ok || slice.Labels[discoveryv1.LabelServiceName] == "" {
+			return
+		}
+		svc, exists, err := serviceInformer.GetIndexer().GetByKey(slice.Namespace + "/" + slice.Labels[discoveryv1.LabelServiceName])
+		if err != nil || !exists {
+			return
+		}
+		e.refreshItem(KindService, svc, func(obj interface{}) interface{} {
+			return e.toServiceVo(obj.(*corev1.Service))
+		})
+	}
+	_, err := sliceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    refresh,
+		UpdateFunc: func(_, obj interface{}) { refresh(obj) },
+		DeleteFunc: refresh,
+	})
+	if err != nil {
+		logrus.Errorf("Failed to add endpointslice event handler: %v", err)
+	}
+}
+
+// toServiceVo 未开启 endpoint 同步时不填充后端信息
+func (e *SyncEngine) toServiceVo(svc *corev1.Service) vo.ServiceVo {
+	s := toServiceVo(e.Cluster, svc)
+	if e.endpointSlices == nil {
+		return s
+	}
+	objs, err := e.endpointSlices.ByIndex(endpointSliceServiceIndex, svc.Namespace+"/"+svc.Name)
+	if err != nil {
+		logrus.Errorf("Failed to get endpointslices of service %s/%s: %v", svc.Namespace, svc.Name, err)
+		return s
+	}
+	slices := make([]*discoveryv1.EndpointSlice, 0, len(objs))
+	for _, obj := range objs {
+		slices = append(slices, obj.(*discoveryv1.EndpointSlice))
+	}
+	setEndpoints(&s, svc, slices)
+	return s
+}
+
+// setEndpoints 根据 EndpointSlice 填充 service 的后端，端口按名称映射到 service 端口
+func setEndpoints(s *vo.ServiceVo, svc *corev1.Service, slices []*discoveryv1.EndpointSlice) {
+	servicePorts := map[string]int32{}
+	for _, port := range svc.Spec.Ports {
+		servicePorts[port.Name] = port.Port
+	}
+
+	s.Endpoints = []vo.EndpointVo{}
+	for _, slice := range slices {
+		var ports []vo.EndpointPortVo
+		for _, port := range slice.Ports {
+			p := vo.EndpointPortVo{}
+			if port.Name != nil {
+				p.Name = *port.Name
+			}
+			if port.Protocol != nil {
+				p.Protocol = string(*port.Protocol)
+			}
+			if port.Port != nil {
+				p.TargetPort = *port.Port
+			}
+			p.ServicePort = servicePorts[p.Name]
+			ports = append(ports, p)
+		}
+
+		for _, ep := range slice.Endpoints {
+			if len(ep.Addresses) == 0 {
+				continue
+			}
+			// 条件为空时按 EndpointSlice 约定：ready 视为 true，serving 同 ready
+			ready := ep.Conditions.Ready == nil || *ep.Conditions.Ready
+			endpoint := vo.EndpointVo{
+				Address:     ep.Addresses[0],
+				Ready:       ready,
+				Serving:     ready,
+				Terminating: ep.Conditions.Terminating != nil && *ep.Conditions.Terminating,
+				Ports:       ports,
+			}
+			if ep.Conditions.Serving != nil {
+				endpoint.Serving = *ep.Conditions.Serving
+			}
+			if ep.TargetRef != nil && ep.TargetRef.Kind == "Pod" {
+				endpoint.PodName = ep.TargetRef.Name
+			}
+			if ep.NodeName != nil {
+				endpoint.NodeName = *ep.NodeName
+			}
+			if ep.Zone != nil {
+				endpoint.Zone = *ep.Zone
+			}
+			if endpoint.Ready {
+				s.ReadyEndpoints++
+			}
+			s.Endpoints = append(s.Endpoints, endpoint)
+		}
+	}
+	// 不同 slice 的顺序不固定，排序后避免产生无意义的变更
+	sort.Slice(s.Endpoints, func(i, j int) bool {
+		if s.Endpoints[i].PodName != s.Endpoints[j].PodName {
+			return s.Endpoints[i].PodName < s.Endpoints[j].PodName
+		}
+		return s.Endpoints[i].Address < s.Endpoints[j].Address
+	})
+	s.NoReadyEndpoints = svc.Spec.Type != corev1.ServiceTypeExternalName && s.ReadyEndpoints == 0
+}
+
+// groupSlicesByService 将 EndpointSlice 按 namespace/service 分组，用于回退到 apiserver 的场景
+func groupSlicesByService(list *discoveryv1.EndpointSliceList) map[string][]*discoveryv1.EndpointSlice {
+	groups := map[string][]*discoveryv1.EndpointSlice{}
+	for _, slice := range sliceListItems(list) {
+		if name := slice.Labels[discoveryv1.LabelServiceName]; name != "" {
+			groups[slice.Namespace+"/"+name] = append(groups[slice.Namespace+"/"+name], slice)
+		}
+	}
+	return groups
+}
+
+func sliceListItems(list *discoveryv1.EndpointSliceList) []*discoveryv1.EndpointSlice {
+	slices := make([]*discoveryv1.EndpointSlice, 0, len(list.Items))
+	for i := range list.Items {
+		slices = append(slices, &list.Items[i])
+	}
+	return slices
+}
+
+func indexSliceByService(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok || slice.Labels[discoveryv1.LabelServiceName] == "" {
+		return nil, nil
+	}
+	return []string{slice.Namespace + "/" + slice.Labels[discoveryv1.LabelServiceName]}, nil
+}
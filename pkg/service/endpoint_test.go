@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServiceEndpoints(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+	}
+	client := fake.NewSimpleClientset(svc)
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{Endpoints: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+
+	get := func() vo.ServiceVo {
+		item, ok := engine.Get(KindService, "default/web")
+		if !ok {
+			t.Fatal("service not cached")
+		}
+		return item.(vo.ServiceVo)
+	}
+	if s := get(); !s.NoReadyEndpoints || len(s.Endpoints) != 0 {
+		t.Fatalf("service without endpoints = %+v", s)
+	}
+
+	portName, port, notReady := "http", int32(8080), false
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-abc",
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports:       []discoveryv1.EndpointPort{{Name: &portName, Port: &port}},
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.2"}, TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "web-2"}, Conditions: discoveryv1.EndpointConditions{Ready: &notReady}},
+			{Addresses: []string{"10.0.0.1"}, TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "web-1"}},
+		},
+	}
+	if _, err := client.DiscoveryV1().EndpointSlices("default").Create(ctx, slice, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(get().Endpoints) == 2 })
+
+	s := get()
+	if s.NoReadyEndpoints || s.ReadyEndpoints != 1 {
+		t.Fatalf("service = %+v", s)
+	}
+	first := s.Endpoints[0]
+	if first.PodName != "web-1" || !first.Ready || !first.Serving || first.Ports[0].ServicePort != 80 || first.Ports[0].TargetPort != 8080 {
+		t.Fatalf("endpoint = %+v", first)
+	}
+	if s.Endpoints[1].Ready {
+		t.Fatalf("endpoint = %+v", s.Endpoints[1])
+	}
+
+	if err := client.DiscoveryV1().EndpointSlices("default").Delete(ctx, "web-abc", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return get().NoReadyEndpoints })
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/model"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// 事件历史的存储类型
+const (
+	EventStoreRedis = "redis"
+	EventStoreMySQL = "mysql"
+)
+
+const (
+	eventBufferSize    = 1000             // 等待写入的事件数量上限，超过时丢弃
+	eventBatchSize     = 200              // 单次批量写入的事件数量
+	eventFlushInterval = 2 * time.Second  // 不足一批时的写入间隔
+	eventPruneInterval = 10 * time.Minute // 清理过期事件的间隔
+	eventScanBatch     = 500              // redis 查询时单次读取的事件数量
+	eventScanFactor    = 10               // redis 查询最多扫描 maxResults 的倍数，超过时视为截断
+)
+
+// EventHistory 事件历史存储，保存 apiserver 过期删除（默认 1h）之后的事件
+// 同一个 Event 对象重复发生时只保留最新状态
+type EventHistory interface {
+	Save(ctx context.Context, clusterID string, events []vo.K8sEventVo) error
+	// Query 按最近发生时间倒序返回，最多 eventHistory.maxResults 条，超过时 truncated 为 true
+	Query(ctx context.Context, clusterID string, q EventQuery) (events []vo.K8sEventVo, truncated bool, err error)
+	// Prune 删除最近发生时间早于 before 的事件
+	Prune(ctx context.Context, clusterID string, before time.Time) error
+}
+
+// NewEventHistory 根据 eventHistory.store 创建事件历史存储，未配置时返回 nil
+func NewEventHistory() (EventHistory, error) {
+	maxResults := int64(sysinit.GCF.UInt("eventHistory.maxResults", 5000))
+	switch store := sysinit.GCF.UString("eventHistory.store"); store {
+	case "":
+		return nil, nil
+	case EventStoreRedis:
+		return NewRedisEventHistory(sysinit.RedisCli, maxResults), nil
+	case EventStoreMySQL:
+		if sysinit.MysqlSession == nil {
+			return nil, fmt.Errorf("eventHistory.store is mysql but mysql.url is not configured")
+		}
+		return NewMySQLEventHistory(sysinit.MysqlSession, maxResults)
+	default:
+		return nil, fmt.Errorf("invalid eventHistory.store %q, must be %s or %s", store, EventStoreRedis, EventStoreMySQL)
+	}
+}
+
+// RedisEventHistory 每个集群按最近发生时间排序的 zset 索引和保存事件内容的 hash
+//
+//	{prefix}{clusterID}:k8sevents                 zset，member 为 eventID，score 为最近发生时间
+//	{prefix}{clusterID}:k8sevents:ns:{namespace}  zset，按命名空间的索引
+//	{prefix}{clusterID}:k8sevents:object:{name}   zset，按关联对象名称的索引
+//	{prefix}{clusterID}:k8sevents:data            hash，eventID -> 事件 JSON
+type RedisEventHistory struct {
+	client     *redis.Client
+	prefix     string
+	maxResults int64
+}
+
+func NewRedisEventHistory(client *redis.Client, maxResults int64) *RedisEventHistory {
+	return &RedisEventHistory{
+		client:     client,
+		prefix:     sysinit.GCF.UString("redis.keyPrefix"),
+		maxResults: maxResults,
+	}
+}
+
+func (h *RedisEventHistory) keys(clusterID string) (string, string) {
+	base := h.prefix + clusterID + ":k8sevents"
+	return base, base + ":data"
+}
+
+// indexKeys 事件所在的全部索引
+func (h *RedisEventHistory) indexKeys(base string, ev vo.K8sEventVo) []string {
+	keys := []string{base, base + ":ns:" + ev.Namespace}
+	if ev.ObjectName != "" {
+		keys = append(keys, base+":object:"+ev.ObjectName)
+	}
+	return keys
+}
+
+func (h *RedisEventHistory) Save(ctx context.Context, clusterID string, events []vo.K8sEventVo) error {
+	base, dataKey := h.keys(clusterID)
+	pipe := h.client.TxPipeline()
+	for _, ev := range events {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		member := &redis.Z{Score: float64(parseEventTime(ev.LastTime).Unix()), Member: ev.EventID}
+		for _, key := range h.indexKeys(base, ev) {
+			pipe.ZAdd(ctx, key, member)
+		}
+		pipe.HSet(ctx, dataKey, ev.EventID, data)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Query 按关联对象名称或命名空间选择索引，按最近发生时间倒序分批读取并过滤，
+// 直到匹配数达到 maxResults 或扫描数达到 maxResults 的 eventScanFactor 倍
+func (h *RedisEventHistory) Query(ctx context.Context, clusterID string, q EventQuery) ([]vo.K8sEventVo, bool, error) {
+	base, dataKey := h.keys(clusterID)
+	key := base
+	switch {
+	case q.ObjectName != "":
+		key = base + ":object:" + q.ObjectName
+	case q.Namespace != "":
+		key = base + ":ns:" + q.Namespace
+	}
+	minScore := "-inf"
+	if !q.From.IsZero() {
+		minScore = strconv.FormatInt(q.From.Unix(), 10)
+	}
+	// score 为最近发生时间，to 之后仍在发生的事件也与时间范围有交集，先读取这部分再读取 [from, to]
+	ranges := []redis.ZRangeBy{{Min: minScore, Max: "+inf"}}
+	if !q.To.IsZero() {
+		to := strconv.FormatInt(q.To.Unix(), 10)
+		ranges = []redis.ZRangeBy{{Min: "(" + to, Max: "+inf"}, {Min: minScore, Max: to}}
+	}
+
+	budget := h.maxResults * eventScanFactor
+	results := []vo.K8sEventVo{}
+	for _, r := range ranges {
+		for r.Offset = 0; ; r.Offset += r.Count {
+			r.Count = eventScanBatch
+			if h.maxResults > 0 {
+				if budget <= 0 {
+					return results, true, nil
+				}
+				r.Count = min(r.Count, budget)
+				budget -= r.Count
+			}
+			ids, err := h.client.ZRevRangeByScore(ctx, key, &r).Result()
+			if err != nil {
+				return nil, false, err
+			}
+			events, err := h.load(ctx, clusterID, dataKey, ids)
+			if err != nil {
+				return nil, false, err
+			}
+			for _, ev := range events {
+				if !q.Contains(ev) {
+					continue
+				}
+				if h.maxResults > 0 && int64(len(results)) == h.maxResults {
+					return results, true, nil
+				}
+				results = append(results, ev)
+			}
+			if int64(len(ids)) < r.Count {
+				break
+			}
+		}
+	}
+	return results, false, nil
+}
+
+// load 读取事件内容，已被清理的事件被忽略
+func (h *RedisEventHistory) load(ctx context.Context, clusterID, dataKey string, ids []string) ([]vo.K8sEventVo, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	values, err := h.client.HMGet(ctx, dataKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]vo.K8sEventVo, 0, len(values))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var ev vo.K8sEventVo
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			logrus.Warnf("Failed to decode event %s of cluster %s: %v", ids[i], clusterID, err)
+			continue
+		}
+		results = append(results, ev)
+	}
+	return results, nil
+}
+
+func (h *RedisEventHistory) Prune(ctx context.Context, clusterID string, before time.Time) error {
+	base, dataKey := h.keys(clusterID)
+	maxScore := "(" + strconv.FormatInt(before.Unix(), 10)
+	ids, err := h.client.ZRangeByScore(ctx, base, &redis.ZRangeBy{Min: "-inf", Max: maxScore}).Result()
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	// 从事件内容得到命名空间和关联对象的索引
+	events, err := h.load(ctx, clusterID, dataKey, ids)
+	if err != nil {
+		return err
+	}
+	pipe := h.client.TxPipeline()
+	for _, ev := range events {
+		for _, key := range h.indexKeys(base, ev)[1:] {
+			pipe.ZRem(ctx, key, ev.EventID)
+		}
+	}
+	pipe.HDel(ctx, dataKey, ids...)
+	pipe.ZRem(ctx, base, stringsToInterfaces(ids)...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	results := make([]interface{}, len(values))
+	for i, v := range values {
+		results[i] = v
+	}
+	return results
+}
+
+// likeEscaper 转义 LIKE 中的通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// MySQLEventHistory 保存在 k8s_event 表
+type MySQLEventHistory struct {
+	db         *gorm.DB
+	maxResults int64
+}
+
+func NewMySQLEventHistory(db *gorm.DB, maxResults int64) (*MySQLEventHistory, error) {
+	if err := db.AutoMigrate(&model.K8sEvent{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to migrate event table: %w", err)
+	}
+	return &MySQLEventHistory{db: db, maxResults: maxResults}, nil
+}
+
+// eventColumns 批量写入的列，顺序与 eventValues 一致
+var eventColumns = []string{
+	"event_id", "cluster_id", "cluster_name", "namespace", "name", "type", "reason", "message",
+	"object_kind", "object_name", "object_namespace", "object_uid", "field_path", "source",
+	"count", "first_time", "last_time",
+}
+
+func eventValues(ev vo.K8sEventVo) []interface{} {
+	return []interface{}{
+		ev.EventID, ev.ClusterID, ev.ClusterName, ev.Namespace, ev.Name, ev.Type, ev.Reason, ev.Message,
+		ev.ObjectKind, ev.ObjectName, ev.ObjectNamespace, ev.ObjectUID, ev.FieldPath, ev.Source,
+		ev.Count, parseEventTime(ev.FirstTime), parseEventTime(ev.LastTime),
+	}
+}
+
+// Save 按 event_id 批量写入，已存在时更新为最新状态
+func (h *MySQLEventHistory) Save(ctx context.Context, clusterID string, events []vo.K8sEventVo) error {
+	if len(events) == 0 {
+		return nil
+	}
+	row := "(" + strings.TrimSuffix(strings.Repeat("?,", len(eventColumns)), ",") + ")"
+	rows := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*len(eventColumns))
+	for _, ev := range events {
+		rows = append(rows, row)
+		args = append(args, eventValues(ev)...)
+	}
+	updates := make([]string, 0, len(eventColumns)-1)
+	for _, column := range eventColumns[1:] {
+		updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", column, column))
+	}
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON DUPLICATE KEY UPDATE %s",
+		model.K8sEvent{}.TableName(), strings.Join(eventColumns, ", "), strings.Join(rows, ", "), strings.Join(updates, ", "))
+	return h.db.Exec(sql, args...).Error
+}
+
+// Query 所有条件都在 SQL 中过滤，多查询一条用于判断是否截断
+func (h *MySQLEventHistory) Query(ctx context.Context, clusterID string, q EventQuery) ([]vo.K8sEventVo, bool, error) {
+	db := h.db.Where("cluster_id = ?", clusterID)
+	for column, value := range map[string]string{
+		"namespace":        q.Namespace,
+		"object_kind":      q.ObjectKind,
+		"object_name":      q.ObjectName,
+		"object_namespace": q.ObjectNamespace,
+		"object_uid":       q.ObjectUID,
+		"type":             q.Type,
+		"reason":           q.Reason,
+		"source":           q.Source,
+	} {
+		if value != "" {
+			db = db.Where(column+" = ?", value)
+		}
+	}
+	if q.Name != "" {
+		pattern := "%" + likeEscaper.Replace(q.Name) + "%"
+		if q.NamePrefix {
+			pattern = likeEscaper.Replace(q.Name) + "%"
+		}
+		db = db.Where("name LIKE ?", pattern)
+	}
+	if !q.From.IsZero() {
+		db = db.Where("last_time >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		db = db.Where("first_time <= ?", q.To)
+	}
+	if h.maxResults > 0 {
+		db = db.Limit(h.maxResults + 1)
+	}
+	var records []model.K8sEvent
+	if err := db.Order("last_time DESC").Find(&records).Error; err != nil {
+		return nil, false, err
+	}
+	truncated := h.maxResults > 0 && int64(len(records)) > h.maxResults
+	if truncated {
+		records = records[:h.maxResults]
+	}
+	results := make([]vo.K8sEventVo, 0, len(records))
+	for _, record := range records {
+		results = append(results, vo.K8sEventVo{
+			EventID:         record.EventID,
+			Name:            record.Name,
+			Namespace:       record.Namespace,
+			ClusterID:       record.ClusterID,
+			ClusterName:     record.ClusterName,
+			Type:            record.Type,
+			Reason:          record.Reason,
+			Message:         record.Message,
+			ObjectKind:      record.ObjectKind,
+			ObjectName:      record.ObjectName,
+			ObjectNamespace: record.ObjectNamespace,
+			ObjectUID:       record.ObjectUID,
+			FieldPath:       record.FieldPath,
+			Source:          record.Source,
+			Count:           record.Count,
+			FirstTime:       record.FirstTime.Local().Format(timeLayout),
+			LastTime:        record.LastTime.Local().Format(timeLayout),
+		})
+	}
+	return results, truncated, nil
+}
+
+func (h *MySQLEventHistory) Prune(ctx context.Context, clusterID string, before time.Time) error {
+	return h.db.Where("cluster_id = ? AND last_time < ?", clusterID, before).Delete(&model.K8sEvent{}).Error
+}
+
+// EventRecorder 将集群 informer 收到的 Event 批量写入事件历史，并定期清理超过保留时间的事件
+type EventRecorder struct {
+	history   EventHistory
+	cluster   ClusterInfo
+	retention time.Duration
+	events    chan vo.K8sEventVo
+}
+
+func NewEventRecorder(history EventHistory, cluster ClusterInfo) *EventRecorder {
+	return &EventRecorder{
+		history:   history,
+		cluster:   cluster,
+		retention: sysinit.UDuration("eventHistory.retention", 168*time.Hour),
+		events:    make(chan vo.K8sEventVo, eventBufferSize),
+	}
+}
+
+// Record 作为 SyncEngine.OnEvent 的回调，不阻塞 informer
+func (r *EventRecorder) Record(ev *corev1.Event) {
+	select {
+	case r.events <- toK8sEventVo(r.cluster, ev):
+	default:
+		logrus.Warnf("Event history buffer of cluster %s is full, dropping event %s/%s", r.cluster.ID, ev.Namespace, ev.Name)
+	}
+}
+
+// Run 持续写入直到 ctx 取消，退出前写入剩余的事件
+func (r *EventRecorder) Run(ctx context.Context) {
+	flush := time.NewTicker(eventFlushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(eventPruneInterval)
+	defer prune.Stop()
+
+	// 同一批次内同一个 Event 只保留最新状态
+	batch := map[string]vo.K8sEventVo{}
+	save := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		events := make([]vo.K8sEventVo, 0, len(batch))
+		for _, ev := range batch {
+			events = append(events, ev)
+		}
+		if err := r.history.Save(ctx, r.cluster.ID, events); err != nil {
+			logrus.Errorf("Failed to save %d events of cluster %s: %v", len(events), r.cluster.ID, err)
+		}
+		batch = map[string]vo.K8sEventVo{}
+	}
+
+	r.prune(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			timeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			save(timeout)
+			cancel()
+			return
+		case ev := <-r.events:
+			batch[ev.EventID] = ev
+			if len(batch) >= eventBatchSize {
+				save(ctx)
+			}
+		case <-flush.C:
+			save(ctx)
+		case <-prune.C:
+			r.prune(ctx)
+		}
+	}
+}
+
+func (r *EventRecorder) prune(ctx context.Context) {
+	if err := r.history.Prune(ctx, r.cluster.ID, time.Now().Add(-r.retention)); err != nil {
+		logrus.Errorf("Failed to prune event history of cluster %s: %v", r.cluster.ID, err)
+	}
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const gatewayGroup = "gateway.networking.k8s.io"
+
+// Gateway API 没有引入类型定义，通过 dynamic client 以 unstructured 方式读取
+var (
+	gatewayGVR   = schema.GroupVersionResource{Group: gatewayGroup, Version: "v1", Resource: "gateways"}
+	httpRouteGVR = schema.GroupVersionResource{Group: gatewayGroup, Version: "v1", Resource: "httproutes"}
+)
+
+// gatewayRef Gateway API 中 parentRefs、backendRefs、certificateRefs 的公共字段
+type gatewayRef struct {
+	Group     *string `json:"group"`
+	Kind      *string `json:"kind"`
+	Namespace *string `json:"namespace"`
+	Name      string  `json:"name"`
+	Port      *int32  `json:"port"`
+	Weight    *int32  `json:"weight"`
+}
+
+type gatewayObject struct {
+	Spec struct {
+		GatewayClassName string `json:"gatewayClassName"`
+		Listeners        []struct {
+			Name     string  `json:"name"`
+			Hostname *string `json:"hostname"`
+			Port     int64   `json:"port"`
+			Protocol string  `json:"protocol"`
+			TLS      *struct {
+				CertificateRefs []gatewayRef `json:"certificateRefs"`
+			} `json:"tls"`
+		} `json:"listeners"`
+	} `json:"spec"`
+	Status struct {
+		Addresses []struct {
+			Value string `json:"value"`
+		} `json:"addresses"`
+	} `json:"status"`
+}
+
+type httpRouteObject struct {
+	Spec struct {
+		ParentRefs []gatewayRef `json:"parentRefs"`
+		Hostnames  []string     `json:"hostnames"`
+		Rules      []struct {
+			Matches []struct {
+				Path *struct {
+					Type  string `json:"type"`
+					Value string `json:"value"`
+				} `json:"path"`
+			} `json:"matches"`
+			BackendRefs []gatewayRef `json:"backendRefs"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// GetGateways
+// @Summary 获取集群Gateway API的Gateway信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器"
+// @Param type query string false "gateway class"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/gateways [get]
+// @Router /api/v1/clusters/{cluster}/k8s/gateways [get]
+func (k *K8sService) GetGateways(ctx iris.Context) {
+	listKind(k, ctx, KindGateway, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.GatewayVo, metav1.ListMeta, error) {
+		items, meta, err := listGatewayResources(ctx, c, gatewayGVR, q)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.GatewayVo
+		for i := range items {
+			results = append(results, toGatewayVo(c.ClusterInfo, &items[i]))
+		}
+		return results, meta, nil
+	}, gatewayItem)
+}
+
+// GetHTTPRoutes
+// @Summary 获取集群Gateway API的HTTPRoute信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/httproutes [get]
+// @Router /api/v1/clusters/{cluster}/k8s/httproutes [get]
+func (k *K8sService) GetHTTPRoutes(ctx iris.Context) {
+	listKind(k, ctx, KindHTTPRoute, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.HTTPRouteVo, metav1.ListMeta, error) {
+		items, meta, err := listGatewayResources(ctx, c, httpRouteGVR, q)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		exists, err := liveServiceLookup(ctx, c)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.HTTPRouteVo
+		for i := range items {
+			results = append(results, toHTTPRouteVo(c.ClusterInfo, &items[i], exists))
+		}
+		return results, meta, nil
+	}, httpRouteItem)
+}
+
+// gatewayItem type 参数匹配 gateway class
+func gatewayItem(g vo.GatewayVo) req.ListItem {
+	return req.ListItem{
+		ID:         g.GatewayID,
+		CreateTime: g.CreateTime,
+		Name:       g.Name,
+		Namespace:  g.Namespace,
+		Type:       g.GatewayClass,
+		Labels:     g.Labels,
+		Fields:     fields.Set{"spec.gatewayClassName": g.GatewayClass},
+	}
+}
+
+func httpRouteItem(r vo.HTTPRouteVo) req.ListItem {
+	return req.ListItem{ID: r.RouteID, CreateTime: r.CreateTime, Name: r.Name, Namespace: r.Namespace, Labels: r.Labels}
+}
+
+// listGatewayResources 集群未安装 Gateway API CRD 时返回空列表
+func listGatewayResources(ctx context.Context, c *Cluster, gvr schema.GroupVersionResource, q req.ListQuery) ([]unstructured.Unstructured, metav1.ListMeta, error) {
+	list, err := c.Dynamic.Resource(gvr).Namespace(q.Namespace).List(ctx, q.ListOptions())
+	if apierrors.IsNotFound(err) {
+		return nil, metav1.ListMeta{}, nil
+	}
+	if err != nil {
+		return nil, metav1.ListMeta{}, err
+	}
+	return list.Items, unstructuredListMeta(list), nil
+}
+
+func unstructuredListMeta(list *unstructured.UnstructuredList) metav1.ListMeta {
+	return metav1.ListMeta{
+		ResourceVersion:    list.GetResourceVersion(),
+		Continue:           list.GetContinue(),
+		RemainingItemCount: list.GetRemainingItemCount(),
+	}
+}
+
+// WatchGatewayAPI 集群安装了 Gateway API CRD 时同步 Gateway 和 HTTPRoute，需在 Start 之前调用
+func (e *SyncEngine) WatchGatewayAPI(client dynamic.Interface, disc discovery.DiscoveryInterface) {
+	resources, err := disc.ServerResourcesForGroupVersion(gatewayGVR.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logrus.Infof("Gateway API is not installed in cluster %s", e.Cluster.ID)
+		} else {
+			logrus.Warnf("Failed to discover Gateway API in cluster %s: %v", e.Cluster.ID, err)
+		}
+		return
+	}
+	served := map[string]bool{}
+	for _, r := range resources.APIResources {
+		served[r.Name] = true
+	}
+
+	e.dynamicFactory = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	if served[gatewayGVR.Resource] {
+		informer := e.dynamicInformer(gatewayGVR)
+		e.watch(KindGateway, informer, func(obj interface{}) interface{} {
+			return toGatewayVo(e.Cluster, obj.(*unstructured.Unstructured))
+		})
+	}
+	if served[httpRouteGVR.Resource] {
+		informer := e.dynamicInformer(httpRouteGVR)
+		if err := informer.AddIndexers(cache.Indexers{routeServiceIndex: indexHTTPRouteByService}); err != nil {
+			logrus.Errorf("Failed to add httproute indexer: %v", err)
+		}
+		convert := func(obj interface{}) interface{} {
+			return toHTTPRouteVo(e.Cluster, obj.(*unstructured.Unstructured), e.serviceExists)
+		}
+		e.watch(KindHTTPRoute, informer, convert)
+		e.refreshOnServiceChange(KindHTTPRoute, informer, convert)
+	}
+}
+
+func (e *SyncEngine) dynamicInformer(gvr schema.GroupVersionResource) cache.SharedIndexInformer {
+	informer := e.dynamicFactory.ForResource(gvr).Informer()
+	if err := informer.SetTransform(stripManagedFields); err != nil {
+		logrus.Errorf("Failed to set %s transform: %v", gvr.Resource, err)
+	}
+	return informer
+}
+
+func toGatewayVo(cluster ClusterInfo, obj *unstructured.Unstructured) vo.GatewayVo {
+	g := vo.GatewayVo{
+		GatewayID:   fmt.Sprintf("%s/%s/Gateway/%s", cluster.ID, obj.GetNamespace(), obj.GetName()),
+		Name:        obj.GetName(),
+		Namespace:   obj.GetNamespace(),
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		NamespaceID: fmt.Sprintf("%s/%s", cluster.ID, obj.GetNamespace()),
+		Labels:      obj.GetLabels(),
+		CreateTime:  obj.GetCreationTimestamp().Local().Format(timeLayout),
+	}
+
+	var gw gatewayObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &gw); err != nil {
+		logrus.Errorf("Failed to convert gateway %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+		return g
+	}
+	g.GatewayClass = gw.Spec.GatewayClassName
+	for _, l := range gw.Spec.Listeners {
+		listener := vo.GatewayListenerVo{Name: l.Name, Port: l.Port, Protocol: l.Protocol}
+		if l.Hostname != nil {
+			listener.Hostname = *l.Hostname
+		}
+		if l.TLS != nil {
+			for _, ref := range l.TLS.CertificateRefs {
+				listener.TLSSecrets = append(listener.TLSSecrets, refNamespace(ref, obj.GetNamespace())+"/"+ref.Name)
+			}
+		}
+		g.Listeners = append(g.Listeners, listener)
+	}
+	for _, addr := range gw.Status.Addresses {
+		g.Addresses = append(g.Addresses, addr.Value)
+	}
+	return g
+}
+
+func toHTTPRouteVo(cluster ClusterInfo, obj *unstructured.Unstructured, exists serviceLookup) vo.HTTPRouteVo {
+	namespace := obj.GetNamespace()
+	r := vo.HTTPRouteVo{
+		RouteID:     fmt.Sprintf("%s/%s/HTTPRoute/%s", cluster.ID, namespace, obj.GetName()),
+		Name:        obj.GetName(),
+		Namespace:   namespace,
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		NamespaceID: fmt.Sprintf("%s/%s", cluster.ID, namespace),
+		Labels:      obj.GetLabels(),
+		CreateTime:  obj.GetCreationTimestamp().Local().Format(timeLayout),
+	}
+
+	var route httpRouteObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &route); err != nil {
+		logrus.Errorf("Failed to convert httproute %s/%s: %v", namespace, obj.GetName(), err)
+		return r
+	}
+	r.Hostnames = route.Spec.Hostnames
+	for _, ref := range route.Spec.ParentRefs {
+		if refGroup(ref, gatewayGroup) == gatewayGroup && refKind(ref, "Gateway") == "Gateway" {
+			r.Gateways = append(r.Gateways, fmt.Sprintf("%s/%s/Gateway/%s", cluster.ID, refNamespace(ref, namespace), ref.Name))
+		}
+	}
+
+	// 未指定 hostnames 时匹配所有 host，用空字符串表示
+	hosts := route.Spec.Hostnames
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+	for _, rule := range route.Spec.Rules {
+		type pathMatch struct{ path, pathType string }
+		paths := []pathMatch{}
+		for _, m := range rule.Matches {
+			if m.Path != nil {
+				paths = append(paths, pathMatch{m.Path.Value, m.Path.Type})
+			}
+		}
+		if len(paths) == 0 {
+			paths = append(paths, pathMatch{"/", "PathPrefix"})
+		}
+
+		for _, ref := range rule.BackendRefs {
+			var backend vo.RouteRuleVo
+			if isServiceRef(ref) {
+				port := ""
+				if ref.Port != nil {
+					port = fmt.Sprint(*ref.Port)
+				}
+				backend = serviceRule(cluster, refNamespace(ref, namespace), ref.Name, port, exists)
+			} else {
+				backend.Backend = refKind(ref, "Service") + "/" + ref.Name
+			}
+			backend.Weight = ref.Weight
+
+			for _, host := range hosts {
+				for _, p := range paths {
+					item := backend
+					item.Host, item.Path, item.PathType = host, p.path, p.pathType
+					r.Rules = append(r.Rules, item)
+				}
+			}
+			if backend.ServiceMissing {
+				r.MissingBackends = true
+			}
+		}
+	}
+	return r
+}
+
+func indexHTTPRouteByService(obj interface{}) ([]string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+	var route httpRouteObject
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &route); err != nil {
+		return nil, nil
+	}
+	var keys []string
+	for _, rule := range route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			if isServiceRef(ref) {
+				keys = append(keys, refNamespace(ref, u.GetNamespace())+"/"+ref.Name)
+			}
+		}
+	}
+	return uniqueStrings(keys), nil
+}
+
+// isServiceRef backendRef 默认 group 为 core、kind 为 Service
+func isServiceRef(ref gatewayRef) bool {
+	return refGroup(ref, "") == "" && refKind(ref, "Service") == "Service"
+}
+
+func refGroup(ref gatewayRef, def string) string {
+	if ref.Group == nil {
+		return def
+	}
+	return *ref.Group
+}
+
+func refKind(ref gatewayRef, def string) string {
+	if ref.Kind == nil {
+		return def
+	}
+	return *ref.Kind
+}
+
+func refNamespace(ref gatewayRef, def string) string {
+	if ref.Namespace == nil || *ref.Namespace == "" {
+		return def
+	}
+	return *ref.Namespace
+}
+
+// GetGateway
+// @Summary 获取单个gateway
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/gateways/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/gateways/{name} [get]
+func (k *K8sService) GetGateway(ctx iris.Context) {
+	k.getObject(ctx, KindGateway)
+}
+
+func liveGateway(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	obj, err := c.Dynamic.Resource(gatewayGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toGatewayVo(c.ClusterInfo, obj), nil
+}
+
+// GetHTTPRoute
+// @Summary 获取单个httproute
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/httproutes/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/httproutes/{name} [get]
+func (k *K8sService) GetHTTPRoute(ctx iris.Context) {
+	k.getObject(ctx, KindHTTPRoute)
+}
+
+func liveHTTPRoute(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	obj, err := c.Dynamic.Resource(httpRouteGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toHTTPRouteVo(c.ClusterInfo, obj, liveServiceGetter(ctx, c)), nil
+}
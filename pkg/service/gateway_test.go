@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHTTPRouteServiceResolution(t *testing.T) {
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"parentRefs": []interface{}{map[string]interface{}{"name": "public", "namespace": "infra"}},
+			"hostnames":  []interface{}{"example.com"},
+			"rules": []interface{}{map[string]interface{}{
+				"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/api"}}},
+				"backendRefs": []interface{}{map[string]interface{}{"name": "api", "port": int64(8080)}},
+			}},
+		},
+	}}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}}
+	client := fake.NewSimpleClientset(svc)
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: gatewayGVR.GroupVersion().String(),
+		APIResources: []metav1.APIResource{{Name: "httproutes", Namespaced: true, Kind: "HTTPRoute"}},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{httpRouteGVR: "HTTPRouteList"}, route)
+
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{})
+	engine.WatchGatewayAPI(dynamicClient, client.Discovery())
+	if engine.Watching(KindGateway) || !engine.Watching(KindHTTPRoute) {
+		t.Fatal("only httproutes are served")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+
+	item, ok := engine.Get(KindHTTPRoute, "default/web")
+	if !ok {
+		t.Fatal("httproute not cached")
+	}
+	r := item.(vo.HTTPRouteVo)
+	if r.MissingBackends || len(r.Gateways) != 1 || r.Gateways[0] != "test/infra/Gateway/public" || len(r.Rules) != 1 {
+		t.Fatalf("httproute = %+v", r)
+	}
+	rule := r.Rules[0]
+	if rule.Host != "example.com" || rule.Path != "/api" || rule.ServiceID != "test/default/api" || rule.ServicePort != "8080" {
+		t.Fatalf("rule = %+v", rule)
+	}
+
+	if err := client.CoreV1().Services("default").Delete(ctx, "api", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		item, _ := engine.Get(KindHTTPRoute, "default/web")
+		return item.(vo.HTTPRouteVo).MissingBackends
+	})
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// routeServiceIndex 路由按引用的 namespace/service 建立的索引，service 创建或删除时据此刷新路由
+const routeServiceIndex = "service"
+
+// ingressClassAnnotation 旧版本通过注解指定 ingress class
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// serviceLookup 判断 service 是否存在
+type serviceLookup func(namespace, name string) bool
+
+// GetIngresses
+// @Summary 获取集群ingress信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器"
+// @Param type query string false "ingress class"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/ingresses [get]
+// @Router /api/v1/clusters/{cluster}/k8s/ingresses [get]
+func (k *K8sService) GetIngresses(ctx iris.Context) {
+	listKind(k, ctx, KindIngress, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.IngressVo, metav1.ListMeta, error) {
+		list, err := c.ClientSet.NetworkingV1().Ingresses(q.Namespace).List(ctx, q.ListOptions())
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		exists, err := liveServiceLookup(ctx, c)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.IngressVo
+		for i := range list.Items {
+			results = append(results, toIngressVo(c.ClusterInfo, &list.Items[i], exists))
+		}
+		return results, list.ListMeta, nil
+	}, ingressItem)
+}
+
+// ingressItem type 参数匹配 ingress class
+func ingressItem(i vo.IngressVo) req.ListItem {
+	return req.ListItem{
+		ID:         i.IngressID,
+		CreateTime: i.CreateTime,
+		Name:       i.Name,
+		Namespace:  i.Namespace,
+		Type:       i.IngressClass,
+		Labels:     i.Labels,
+		Fields:     fields.Set{"spec.ingressClassName": i.IngressClass},
+	}
+}
+
+// watchIngresses 同步 ingress，引用的 service 创建或删除时重新计算 serviceMissing
+func (e *SyncEngine) watchIngresses() {
+	informer := e.factory.Networking().V1().Ingresses().Informer()
+	if err := informer.AddIndexers(cache.Indexers{routeServiceIndex: indexIngressByService}); err != nil {
+		logrus.Errorf("Failed to add ingress indexer: %v", err)
+	}
+	convert := func(obj interface{}) interface{} {
+		return toIngressVo(e.Cluster, obj.(*networkingv1.Ingress), e.serviceExists)
+	}
+	e.watch(KindIngress, informer, convert)
+	e.refreshOnServiceChange(KindIngress, informer, convert)
+}
+
+// refreshOnServiceChange service 创建或删除时，重新转换通过 routeServiceIndex 引用它的对象
+func (e *SyncEngine) refreshOnServiceChange(kind string, informer cache.SharedIndexInformer, convert convertFunc) {
+	refresh := func(obj interface{}) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			return
+		}
+		routes, err := informer.GetIndexer().ByIndex(routeServiceIndex, key)
+		if err != nil {
+			return
+		}
+		for _, route := range routes {
+			e.refreshItem(kind, route, convert)
+		}
+	}
+	_, err := e.factory.Core().V1().Services().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    refresh,
+		DeleteFunc: refresh,
+	})
+	if err != nil {
+		logrus.Errorf("Failed to add service event handler for %s: %v", kind, err)
+	}
+}
+
+// serviceExists 基于 service 缓存判断
+func (e *SyncEngine) serviceExists(namespace, name string) bool {
+	_, exists, err := e.services.GetByKey(namespace + "/" + name)
+	return err == nil && exists
+}
+
+// liveServiceLookup 回退到 apiserver 时一次性列出所有 service
+func liveServiceLookup(ctx context.Context, c *Cluster) (serviceLookup, error) {
+	list, err := c.ClientSet.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(list.Items))
+	for _, svc := range list.Items {
+		keys[svc.Namespace+"/"+svc.Name] = true
+	}
+	return func(namespace, name string) bool {
+		return keys[namespace+"/"+name]
+	}, nil
+}
+
+func toIngressVo(cluster ClusterInfo, ing *networkingv1.Ingress, exists serviceLookup) vo.IngressVo {
+	i := vo.IngressVo{
+		IngressID:   fmt.Sprintf("%s/%s/Ingress/%s", cluster.ID, ing.Namespace, ing.Name),
+		Name:        ing.Name,
+		Namespace:   ing.Namespace,
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		NamespaceID: fmt.Sprintf("%s/%s", cluster.ID, ing.Namespace),
+		Labels:      ing.Labels,
+		CreateTime:  ing.CreationTimestamp.Local().Format(timeLayout),
+	}
+	if ing.Spec.IngressClassName != nil {
+		i.IngressClass = *ing.Spec.IngressClassName
+	} else {
+		i.IngressClass = ing.Annotations[ingressClassAnnotation]
+	}
+
+	if ing.Spec.DefaultBackend != nil {
+		rule := ingressRule(cluster, ing.Namespace, ing.Spec.DefaultBackend, exists)
+		rule.DefaultBackend = true
+		i.Rules = append(i.Rules, rule)
+	}
+	for _, r := range ing.Spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+		for _, path := range r.HTTP.Paths {
+			rule := ingressRule(cluster, ing.Namespace, &path.Backend, exists)
+			rule.Host = r.Host
+			rule.Path = path.Path
+			if path.PathType != nil {
+				rule.PathType = string(*path.PathType)
+			}
+			i.Rules = append(i.Rules, rule)
+		}
+	}
+	for _, rule := range i.Rules {
+		if rule.ServiceMissing {
+			i.MissingBackends = true
+		}
+	}
+
+	for _, tls := range ing.Spec.TLS {
+		i.TLS = append(i.TLS, vo.IngressTLSVo{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			i.Addresses = append(i.Addresses, lb.IP)
+		} else if lb.Hostname != "" {
+			i.Addresses = append(i.Addresses, lb.Hostname)
+		}
+	}
+	return i
+}
+
+func ingressRule(cluster ClusterInfo, namespace string, backend *networkingv1.IngressBackend, exists serviceLookup) vo.RouteRuleVo {
+	if backend.Service == nil {
+		rule := vo.RouteRuleVo{}
+		if backend.Resource != nil {
+			rule.Backend = backend.Resource.Kind + "/" + backend.Resource.Name
+		}
+		return rule
+	}
+	port := backend.Service.Port.Name
+	if port == "" {
+		port = strconv.Itoa(int(backend.Service.Port.Number))
+	}
+	return serviceRule(cluster, namespace, backend.Service.Name, port, exists)
+}
+
+// serviceRule 将后端解析为 ServiceID，并标记 service 是否存在
+func serviceRule(cluster ClusterInfo, namespace, name, port string, exists serviceLookup) vo.RouteRuleVo {
+	return vo.RouteRuleVo{
+		ServiceName:      name,
+		ServiceNamespace: namespace,
+		ServicePort:      port,
+		ServiceID:        fmt.Sprintf("%s/%s/%s", cluster.ID, namespace, name),
+		ServiceMissing:   !exists(namespace, name),
+	}
+}
+
+func indexIngressByService(obj interface{}) ([]string, error) {
+	ing, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return nil, nil
+	}
+	var keys []string
+	if b := ing.Spec.DefaultBackend; b != nil && b.Service != nil {
+		keys = append(keys, ing.Namespace+"/"+b.Service.Name)
+	}
+	for _, r := range ing.Spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+		for _, path := range r.HTTP.Paths {
+			if path.Backend.Service != nil {
+				keys = append(keys, ing.Namespace+"/"+path.Backend.Service.Name)
+			}
+		}
+	}
+	return uniqueStrings(keys), nil
+}
+
+func uniqueStrings(items []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// GetIngress
+// @Summary 获取单个ingress
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/ingresses/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/ingresses/{name} [get]
+func (k *K8sService) GetIngress(ctx iris.Context) {
+	k.getObject(ctx, KindIngress)
+}
+
+func liveIngress(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	ing, err := c.ClientSet.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toIngressVo(c.ClusterInfo, ing, liveServiceGetter(ctx, c)), nil
+}
+
+// liveServiceGetter 单个对象只引用少量 service，逐个查询
+func liveServiceGetter(ctx context.Context, c *Cluster) serviceLookup {
+	return func(namespace, name string) bool {
+		_, err := c.ClientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err == nil
+	}
+}
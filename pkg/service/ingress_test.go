@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIngressServiceResolution(t *testing.T) {
+	className, pathType := "nginx", networkingv1.PathTypePrefix
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &className,
+			TLS:              []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}},
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/api",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: "api", Port: networkingv1.ServiceBackendPort{Number: 8080},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+	client := fake.NewSimpleClientset(ing)
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{Ingresses: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+
+	get := func() vo.IngressVo {
+		item, ok := engine.Get(KindIngress, "default/web")
+		if !ok {
+			t.Fatal("ingress not cached")
+		}
+		return item.(vo.IngressVo)
+	}
+	i := get()
+	if i.IngressClass != "nginx" || len(i.TLS) != 1 || i.TLS[0].SecretName != "example-tls" || !i.MissingBackends {
+		t.Fatalf("ingress = %+v", i)
+	}
+	rule := i.Rules[0]
+	if rule.Host != "example.com" || rule.Path != "/api" || rule.ServiceID != "test/default/api" || rule.ServicePort != "8080" || !rule.ServiceMissing {
+		t.Fatalf("rule = %+v", rule)
+	}
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}}
+	if _, err := client.CoreV1().Services("default").Create(ctx, svc, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return !get().MissingBackends })
+}
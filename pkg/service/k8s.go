@@ -2,38 +2,126 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+
 	"github.com/kataras/iris/v12"
-	"github.com/kataras/iris/v12/x/errors"
 	"github.com/sirupsen/logrus"
-	"github.com/yilei-pixocial/kubeapi/pkg/k8s"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
 	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
-	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
 	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"log"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"k8s.io/apimachinery/pkg/fields"
 )
 
 type K8sService struct {
-	ClientSet kubernetes.Interface
+	Registry   *ClusterRegistry
+	ReadMode   string
+	Watch      WatchOptions
+	Logs       LogOptions
+	Exec       ExecOptions
+	Proxy      ProxyOptions
+	Namespaces NamespaceOptions
+	Apply      ApplyOptions
+	Resources  ResourceOptions
 }
 
-func NewK8sService() (*K8sService, error) {
+func NewK8sService(registry *ClusterRegistry) *K8sService {
+	return &K8sService{
+		Registry:   registry,
+		ReadMode:   LoadReadMode(),
+		Watch:      LoadWatchOptions(),
+		Logs:       LoadLogOptions(),
+		Exec:       LoadExecOptions(),
+		Proxy:      LoadProxyOptions(),
+		Namespaces: LoadNamespaceOptions(),
+		Apply:      LoadApplyOptions(),
+		Resources:  LoadResourceOptions(),
+	}
+}
 
-	clientSet, err := k8s.NewClientSetFromConfig(sysinit.GCF.UString("kubernetes.kubeconfig"))
+// liveFunc 缓存不可用时从 apiserver 获取某类资源，返回的 ListMeta 用于分块读取
+type liveFunc[T any] func(ctx context.Context, c *Cluster, q req.ListQuery) ([]T, metav1.ListMeta, error)
+
+// listKind 解析通用过滤和分页参数，按集群聚合某类资源并返回
+func listKind[T any](k *K8sService, ctx iris.Context, kind string, live liveFunc[T], item func(T) req.ListItem) {
+	q, err := req.ParseListQuery(ctx)
 	if err != nil {
-		return nil, err
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
 	}
+	listKindWithQuery(k, ctx, q, kind, live, item)
+}
 
-	return &K8sService{
-		ClientSet: clientSet,
-	}, nil
+func listKindWithQuery[T any](k *K8sService, ctx iris.Context, q req.ListQuery, kind string, live liveFunc[T], item func(T) req.ListItem) {
+	clusters, err := k.Registry.Resolve(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	mode := k.ReadMode
+
+	// 单集群、缓存不可用且未指定排序时直接使用 apiserver 的分块读取
+	if mode != ReadCache && q.Paged() && q.Sort == "" && len(clusters) == 1 && (q.ChunkCursor() || !clusters[0].Engine.Cached(kind)) {
+		chunk := q
+		chunk.Chunk = true
+		items, meta, err := live(ctx.Request().Context(), clusters[0], chunk)
+		if err == nil {
+			ctx.JSON(resp.OkWithPage(filterItems(items, q, item), chunkPagination(chunk, meta)))
+			return
+		}
+		// apiserver 的分块游标无法在快照上继续
+		if mode != ReadCacheFallback || q.ChunkCursor() {
+			ctx.JSON(resp.ErrorWithMsg(err.Error()))
+			return
+		}
+		logrus.Warnf("Failed to list %s from cluster %s, using Redis snapshot: %v", kind, clusters[0].ID, err)
+		mode = ReadCache
+	}
+	if q.ChunkCursor() {
+		ctx.JSON(resp.BadResponse("continue token is only valid for a single cluster"))
+		return
+	}
+
+	// 缓存可用时读取缓存，否则从 apiserver 或 redis 快照获取全部数据，再统一在内存中过滤和分页
+	results := []T{}
+	var age snapshotAge
+	for _, c := range clusters {
+		items, at, err := readItems(mode, ctx.Request().Context(), c, q, kind, live, item)
+		if err != nil {
+			ctx.JSON(resp.ErrorWithMsg(err.Error()))
+			return
+		}
+		age.add(at)
+		results = append(results, filterItems(items, q, item)...)
+	}
+
+	results, page := req.Paginate(results, q, item)
+	age.setHeader(ctx)
+	if !q.Paged() {
+		ctx.JSON(resp.OkWithData(results))
+		return
+	}
+	ctx.JSON(resp.OkWithPage(results, resp.Pagination{Total: int64(page.Total), Limit: q.Limit, Continue: page.Continue}))
+}
+
+func filterItems[T any](items []T, q req.ListQuery, item func(T) req.ListItem) []T {
+	results := []T{}
+	for _, it := range items {
+		if q.Match(item(it)) {
+			results = append(results, it)
+		}
+	}
+	return results
+}
+
+// chunkPagination apiserver 分块读取时的分页信息，总数未知
+func chunkPagination(q req.ListQuery, meta metav1.ListMeta) resp.Pagination {
+	page := resp.Pagination{Total: -1, Limit: q.Limit, Remaining: meta.RemainingItemCount}
+	if meta.Continue != "" {
+		page.Continue = req.Cursor{Continue: meta.Continue}.Encode()
+	}
+	return page
 }
 
 // GetServices
@@ -41,44 +129,61 @@ func NewK8sService() (*K8sService, error) {
 // @Tags　k8s
 // @Accept application/json
 // @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器，支持 metadata.name、metadata.namespace、spec.type、spec.clusterIP"
+// @Param type query string false "service类型：ClusterIP/NodePort/LoadBalancer/ExternalName"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
 // @Success 200 {object} model.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
 // @Failure 400 {object} model.Message
 // @Router /api/v1/k8s/services [get]
+// @Router /api/v1/clusters/{cluster}/k8s/services [get]
 func (k *K8sService) GetServices(ctx iris.Context) {
+	listKind(k, ctx, KindService, liveServices, serviceItem)
+}
 
-	namespaceList, err := k.ClientSet.CoreV1().Namespaces().List(ctx.Request().Context(), metav1.ListOptions{})
+func liveServices(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.ServiceVo, metav1.ListMeta, error) {
+	serviceList, err := c.ClientSet.CoreV1().Services(q.Namespace).List(ctx, q.ListOptions())
 	if err != nil {
-		ctx.JSON(resp.ErrorWithMsg(err.Error()))
-		return
+		return nil, metav1.ListMeta{}, err
+	}
+	// 后端信息获取失败时仍然返回 service 本身
+	var slices map[string][]*discoveryv1.EndpointSlice
+	sliceList, err := c.ClientSet.DiscoveryV1().EndpointSlices(q.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logrus.Warnf("Failed to list endpointslices of cluster %s: %v", c.ID, err)
+	} else {
+		slices = groupSlicesByService(sliceList)
 	}
 
 	var results []vo.ServiceVo
-	for _, ns := range namespaceList.Items {
-		serviceList, err := k.ClientSet.CoreV1().Services(ns.Name).List(ctx.Request().Context(), metav1.ListOptions{})
-		if err != nil {
-			ctx.JSON(resp.ErrorWithMsg(err.Error()))
-			return
-		}
-
-		for _, svc := range serviceList.Items {
-			createAt := time.Unix(0, svc.CreationTimestamp.UnixMilli()*int64(time.Millisecond)).
-				Format("2006-01-02 15:04:05")
-			results = append(results, vo.ServiceVo{
-				Name:       svc.Name,
-				Namespace:  svc.Namespace,
-				Type:       string(svc.Spec.Type),
-				ClusterIP:  svc.Spec.ClusterIP,
-				Ports:      svc.Spec.Ports,
-				CreateTime: createAt,
-				Selector:   svc.Spec.Selector,
-			})
+	for i := range serviceList.Items {
+		svc := &serviceList.Items[i]
+		s := toServiceVo(c.ClusterInfo, svc)
+		if slices != nil {
+			setEndpoints(&s, svc, slices[svc.Namespace+"/"+svc.Name])
 		}
+		results = append(results, s)
 	}
+	return results, serviceList.ListMeta, nil
+}
 
-	err = ctx.JSON(resp.OkWithData(results))
-	if err != nil {
-		ctx.JSON(resp.ErrorWithMsg(err.Error()))
-		return
+func serviceItem(s vo.ServiceVo) req.ListItem {
+	return req.ListItem{
+		ID:         s.ServiceID,
+		CreateTime: s.CreateTime,
+		Name:       s.Name,
+		Namespace:  s.Namespace,
+		Type:       s.Type,
+		Labels:     s.Labels,
+		Fields:     fields.Set{"spec.type": s.Type, "spec.clusterIP": s.ClusterIP},
 	}
 }
 
@@ -86,65 +191,52 @@ func (k *K8sService) GetServices(ctx iris.Context) {
 // @Summary 获取集群命名空间信息
 // @Tags　k8s
 // @Accept application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器，支持 metadata.name、status.phase，未指定 status.phase 时只返回 Active"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
 // @Success 200 {object} model.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
 // @Failure 400 {object} model.Message
 // @Router /api/v1/k8s/namespaces [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces [get]
 func (k *K8sService) GetNamespaces(ctx iris.Context) {
-
-	namespaceList, err := k.ClientSet.CoreV1().Namespaces().List(ctx.Request().Context(), metav1.ListOptions{})
+	q, err := req.ParseListQuery(ctx)
 	if err != nil {
-		ctx.JSON(resp.ErrorWithMsg(err.Error()))
+		ctx.JSON(resp.BadResponse(err.Error()))
 		return
 	}
-
-	var results []vo.NamespaceVo
-	for _, ns := range namespaceList.Items {
-		if ns.Status.Phase == "Active" {
-			results = append(results, vo.NamespaceVo{
-				Name: ns.Name,
-				CreateTime: time.Unix(0, ns.CreationTimestamp.UnixMilli()*int64(time.Millisecond)).
-					Format("2006-01-02 15:04:05"),
-			})
-		}
+	// 未按 status.phase 过滤时保持只返回 Active 的行为
+	if _, ok := q.FieldSelector.RequiresExactMatch("status.phase"); !ok {
+		q.FieldSelector = fields.AndSelectors(q.FieldSelector, fields.OneTermEqualSelector("status.phase", string(corev1.NamespaceActive)))
 	}
-
-	err = ctx.JSON(resp.OkWithData(results))
-	if err != nil {
-		ctx.JSON(resp.ErrorWithMsg(err.Error()))
-		return
-	}
-	return
+	listKindWithQuery(k, ctx, q, KindNamespace, liveNamespaces, namespaceItem)
 }
 
-func SyncToRedis() {
-
-	kubeClient, err := k8s.NewClientSetFromConfig(sysinit.GCF.UString("kubernetes.kubeconfig"))
+func liveNamespaces(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.NamespaceVo, metav1.ListMeta, error) {
+	namespaceList, err := c.ClientSet.CoreV1().Namespaces().List(ctx, q.ListOptions("status.phase"))
 	if err != nil {
-		logrus.Errorf("Failed to create kubernetes clientSet from config. Err: %+v", err)
+		return nil, metav1.ListMeta{}, err
 	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	setupSignalHandling(cancel)
-
-	if err := syncData(ctx, kubeClient); err != nil {
-		logrus.Errorf("Initial sync failed: %v", err)
+	var results []vo.NamespaceVo
+	for i := range namespaceList.Items {
+		results = append(results, toNamespaceVo(c.ClusterInfo, &namespaceList.Items[i]))
 	}
+	return results, namespaceList.ListMeta, nil
+}
 
-	ticker := time.NewTicker(60 * time.Second) // 每60秒同步一次
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			if err := syncData(ctx, kubeClient); err != nil {
-				logrus.Errorf("Sync failed: %v", err)
-			}
-		case <-ctx.Done():
-			logrus.Errorf("Shutting down...")
-			return
-		}
+func namespaceItem(ns vo.NamespaceVo) req.ListItem {
+	return req.ListItem{
+		ID:         ns.NamespaceID,
+		CreateTime: ns.CreateTime,
+		Name:       ns.Name,
+		Labels:     ns.Labels,
+		Fields:     fields.Set{"status.phase": ns.Status},
 	}
 }
 
@@ -155,120 +247,142 @@ type ClusterData struct {
 	K8sVersion      string           `json:"k8sVersion"`
 	Namespaces      []vo.NamespaceVo `json:"namespaces"`
 	Services        []vo.ServiceVo   `json:"services"`
+	Deployments     []vo.WorkloadVo  `json:"deployments"`
+	StatefulSets    []vo.WorkloadVo  `json:"statefulSets"`
+	DaemonSets      []vo.WorkloadVo  `json:"daemonSets"`
+	Pods            []vo.PodVo       `json:"pods,omitempty"` // 数量大且变化频繁，不写入旧版整体 JSON
+	Nodes           []vo.NodeVo      `json:"nodes"`
+	Ingresses       []vo.IngressVo   `json:"ingresses"`
+	Gateways        []vo.GatewayVo   `json:"gateways"`
+	HTTPRoutes      []vo.HTTPRouteVo `json:"httpRoutes"`
+	// sync.resources 配置的自定义资源，按配置的名称分组
+	Resources map[string][]vo.CustomResourceVo `json:"resources,omitempty"`
 }
 
-func syncData(ctx context.Context, kubeClient *kubernetes.Clientset) error {
-	logrus.Info("Starting sync...")
-
-	var clusterID, clusterName, k8sVersion, clusterRegionID string
+// storeItem 快照中的单个对象，Namespace 为空表示集群级资源
+type storeItem struct {
+	ID        string
+	Namespace string
+	Object    interface{}
+}
 
-	if os.Getenv("kubernetes.clusterID") != "" {
-		clusterID = os.Getenv("kubernetes.clusterID")
-	} else if sysinit.GCF.UString("kubernetes.clusterID") != "" {
-		clusterID = sysinit.GCF.UString("kubernetes.clusterID")
-	} else {
-		return errors.New("kubernetes cluster ID is empty")
+// Items 按资源类型展开快照，新增资源类型时需在此登记
+func (d ClusterData) Items() map[string][]storeItem {
+	items := map[string][]storeItem{
+		KindNamespace: make([]storeItem, 0, len(d.Namespaces)),
+		KindService:   make([]storeItem, 0, len(d.Services)),
 	}
-
-	if os.Getenv("kubernetes.clusterName") != "" {
-		clusterName = os.Getenv("kubernetes.clusterName")
-	} else if sysinit.GCF.UString("kubernetes.clusterName") != "" {
-		clusterName = sysinit.GCF.UString("kubernetes.clusterName")
-	} else {
-		return errors.New("kubernetes clusterName is empty")
+	for _, ns := range d.Namespaces {
+		items[KindNamespace] = append(items[KindNamespace], storeItem{ID: ns.NamespaceID, Object: ns})
 	}
-
-	if os.Getenv("kubernetes.clusterRegionID") != "" {
-		clusterRegionID = os.Getenv("kubernetes.clusterRegionID")
-	} else if sysinit.GCF.UString("kubernetes.clusterRegionID") != "" {
-		clusterRegionID = sysinit.GCF.UString("kubernetes.clusterRegionID")
-	} else {
-		return errors.New("kubernetes clusterRegionID is empty")
+	for _, svc := range d.Services {
+		items[KindService] = append(items[KindService], storeItem{ID: svc.ServiceID, Namespace: svc.Namespace, Object: svc})
 	}
-
-	version, err := kubeClient.Discovery().ServerVersion()
-	if err != nil {
-		logrus.Errorf("Failed to get Kubernetes server version: %v", err)
-	} else {
-		k8sVersion = version.GitVersion
+	for kind, workloads := range map[string][]vo.WorkloadVo{
+		KindDeployment:  d.Deployments,
+		KindStatefulSet: d.StatefulSets,
+		KindDaemonSet:   d.DaemonSets,
+	} {
+		// 未开启同步的资源类型为 nil，不写入 redis
+		if workloads == nil {
+			continue
+		}
+		items[kind] = make([]storeItem, 0, len(workloads))
+		for _, w := range workloads {
+			items[kind] = append(items[kind], storeItem{ID: w.WorkloadID, Namespace: w.Namespace, Object: w})
+		}
 	}
-
-	namespaces, err := kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		logrus.Errorf("Failed to list namespaces: %v", err)
-		return fmt.Errorf("failed to list namespaces: %w", err)
+	if d.Pods != nil {
+		items[KindPod] = make([]storeItem, 0, len(d.Pods))
+		for _, pod := range d.Pods {
+			items[KindPod] = append(items[KindPod], storeItem{ID: pod.PodID, Namespace: pod.Namespace, Object: pod})
+		}
 	}
-
-	var serviceVos []vo.ServiceVo
-	for _, ns := range namespaces.Items {
-		services, err := kubeClient.CoreV1().Services(ns.Name).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			logrus.Errorf("Failed to list services in namespace %s: %v", ns.Name, err)
-			continue
+	if d.Nodes != nil {
+		items[KindNode] = make([]storeItem, 0, len(d.Nodes))
+		for _, node := range d.Nodes {
+			items[KindNode] = append(items[KindNode], storeItem{ID: node.NodeID, Object: node})
 		}
-
-		for _, svc := range services.Items {
-			serviceVos = append(serviceVos, vo.ServiceVo{
-				ServiceID: fmt.Sprintf("%s/%s/%s", clusterID, ns.Name, svc.Name),
-				Name:      svc.Name,
-				Namespace: svc.Namespace,
-				Type:      string(svc.Spec.Type),
-				ClusterIP: svc.Spec.ClusterIP,
-				Ports:     svc.Spec.Ports,
-				CreateTime: time.Unix(0, svc.CreationTimestamp.UnixMilli()*int64(time.Millisecond)).
-					Format("2006-01-02 15:04:05"),
-				Selector:    svc.Spec.Selector,
-				ClusterName: clusterName,
-				ClusterID:   clusterID,
-				NamespaceID: fmt.Sprintf("%s/%s", clusterID, ns.Name),
-			})
+	}
+	if d.Ingresses != nil {
+		items[KindIngress] = make([]storeItem, 0, len(d.Ingresses))
+		for _, ing := range d.Ingresses {
+			items[KindIngress] = append(items[KindIngress], storeItem{ID: ing.IngressID, Namespace: ing.Namespace, Object: ing})
 		}
 	}
-
-	var namespaceVos []vo.NamespaceVo
-	for _, ns := range namespaces.Items {
-		namespaceVos = append(namespaceVos, vo.NamespaceVo{
-			NamespaceID: fmt.Sprintf("%s/%s", clusterID, ns.Name),
-			Name:        ns.Name,
-			CreateTime: time.Unix(0, ns.CreationTimestamp.UnixMilli()*int64(time.Millisecond)).
-				Format("2006-01-02 15:04:05"),
-			ClusterID:   clusterID,
-			ClusterName: clusterName,
-			Status:      string(ns.Status.Phase),
-		})
+	if d.Gateways != nil {
+		items[KindGateway] = make([]storeItem, 0, len(d.Gateways))
+		for _, gw := range d.Gateways {
+			items[KindGateway] = append(items[KindGateway], storeItem{ID: gw.GatewayID, Namespace: gw.Namespace, Object: gw})
+		}
 	}
-
-	data := ClusterData{
-		ClusterId:       clusterID,
-		ClusterName:     clusterName,
-		ClusterRegionID: clusterRegionID,
-		K8sVersion:      k8sVersion,
-		Namespaces:      namespaceVos,
-		Services:        serviceVos,
+	if d.HTTPRoutes != nil {
+		items[KindHTTPRoute] = make([]storeItem, 0, len(d.HTTPRoutes))
+		for _, route := range d.HTTPRoutes {
+			items[KindHTTPRoute] = append(items[KindHTTPRoute], storeItem{ID: route.RouteID, Namespace: route.Namespace, Object: route})
+		}
 	}
-
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+	for kind, resources := range d.Resources {
+		items[kind] = make([]storeItem, 0, len(resources))
+		for _, r := range resources {
+			items[kind] = append(items[kind], storeItem{ID: r.ResourceID, Namespace: r.Namespace, Object: r})
+		}
 	}
+	return items
+}
 
-	key := sysinit.GCF.UString("redis.keyPrefix") + clusterID
-	err = sysinit.RedisCli.Set(ctx, key, jsonData, 3600*time.Second).Err()
+// GetNamespace
+// @Summary 获取单个命名空间
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{name} [get]
+func (k *K8sService) GetNamespace(ctx iris.Context) {
+	k.getObject(ctx, KindNamespace)
+}
+
+func liveNamespace(ctx context.Context, c *Cluster, _, name string) (interface{}, error) {
+	ns, err := c.ClientSet.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to set data in Redis: %w", err)
+		return nil, err
 	}
-
-	logrus.Infof("Sync completed for cluster %s, stored in key %s", clusterName, key)
-	return nil
+	return toNamespaceVo(c.ClusterInfo, ns), nil
 }
 
-func setupSignalHandling(cancel context.CancelFunc) {
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+// GetService
+// @Summary 获取单个service
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/services/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/services/{name} [get]
+func (k *K8sService) GetService(ctx iris.Context) {
+	k.getObject(ctx, KindService)
+}
 
-	go func() {
-		sig := <-sigs
-		log.Printf("Received signal: %v", sig)
-		cancel()
-	}()
+func liveService(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	svc, err := c.ClientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	s := toServiceVo(c.ClusterInfo, svc)
+	sliceList, err := c.ClientSet.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err != nil {
+		logrus.Warnf("Failed to list endpointslices of service %s/%s: %v", namespace, name, err)
+		return s, nil
+	}
+	setEndpoints(&s, svc, sliceListItems(sliceList))
+	return s, nil
 }
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// 事件接口的数据来源
+const (
+	EventSourceLive    = "live"    // informer 缓存或 apiserver，只包含 apiserver 尚未过期的事件
+	EventSourceHistory = "history" // eventHistory 配置的历史存储
+)
+
+// TruncatedHeader 事件历史中匹配的事件超过 eventHistory.maxResults 被截断时为 true
+const TruncatedHeader = "X-Truncated"
+
+// eventServerFields apiserver 支持的 event fieldSelector 字段
+var eventServerFields = []string{
+	"involvedObject.kind", "involvedObject.name", "involvedObject.namespace", "involvedObject.uid",
+	"reason", "type", "source",
+}
+
+// GetEvents
+// @Summary 获取Kubernetes事件
+// @Description 默认按最近发生时间倒序。配置了 eventHistory 时默认从历史存储读取，可查询 apiserver 已过期的事件。
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param objectKind query string false "关联对象类型，例如 Pod"
+// @Param objectName query string false "关联对象名称"
+// @Param objectUID query string false "关联对象UID"
+// @Param type query string false "事件类型：Normal/Warning"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器，支持 involvedObject.kind、involvedObject.name、involvedObject.namespace、involvedObject.uid、reason、type、source"
+// @Param name query string false "事件名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param since query string false "最近一段时间，例如 30m、24h"
+// @Param from query string false "开始时间，RFC3339 格式"
+// @Param to query string false "结束时间，RFC3339 格式"
+// @Param source query string false "数据来源：live/history，配置了 eventHistory 时默认 history"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime（最近发生时间，默认）"
+// @Param order query string false "排序方向：asc/desc（默认）"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Truncated "历史存储中匹配的事件超过 eventHistory.maxResults 被截断时为 true"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/events [get]
+// @Router /api/v1/clusters/{cluster}/k8s/events [get]
+func (k *K8sService) GetEvents(ctx iris.Context) {
+	clusters, err := k.Registry.Resolve(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	q, err := req.ParseListQueryWithSort(ctx, req.SortCreatedTime, req.OrderDesc)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	// 关联对象参数等价于 involvedObject 字段选择器
+	for param, field := range map[string]string{
+		"objectKind": "involvedObject.kind",
+		"objectName": "involvedObject.name",
+		"objectUID":  "involvedObject.uid",
+	} {
+		if value := ctx.URLParam(param); value != "" {
+			q.FieldSelector = fields.AndSelectors(q.FieldSelector, fields.OneTermEqualSelector(field, value))
+		}
+	}
+	eq, err := parseEventQuery(ctx, q)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	history := k.Registry.history
+	source := EventSourceLive
+	if history != nil {
+		source = EventSourceHistory
+	}
+	switch source = ctx.URLParamDefault("source", source); source {
+	case EventSourceLive:
+	case EventSourceHistory:
+		if history == nil {
+			ctx.JSON(resp.BadResponse("event history is not configured"))
+			return
+		}
+	default:
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("invalid source, must be %s or %s", EventSourceLive, EventSourceHistory)))
+		return
+	}
+
+	results := []vo.K8sEventVo{}
+	for _, c := range clusters {
+		var events []vo.K8sEventVo
+		if source == EventSourceHistory {
+			var truncated bool
+			events, truncated, err = history.Query(ctx.Request().Context(), c.ID, eq)
+			if truncated {
+				ctx.Header(TruncatedHeader, "true")
+			}
+		} else {
+			events, err = liveEvents(ctx.Request().Context(), c, q)
+		}
+		if err != nil {
+			ctx.JSON(resp.ErrorWithMsg(err.Error()))
+			return
+		}
+		for _, ev := range events {
+			if eq.Contains(ev) && q.Match(eventItem(ev)) {
+				results = append(results, ev)
+			}
+		}
+	}
+
+	results, page := req.Paginate(results, q, eventItem)
+	if !q.Paged() {
+		ctx.JSON(resp.OkWithData(results))
+		return
+	}
+	ctx.JSON(resp.OkWithPage(results, resp.Pagination{Total: int64(page.Total), Limit: q.Limit, Continue: page.Continue}))
+}
+
+// EventQuery 事件历史的查询条件，字符串为空或时间为零值表示不限制
+// 事件在 [FirstTime, LastTime] 内多次发生，与时间范围有交集即视为匹配
+type EventQuery struct {
+	Namespace       string
+	ObjectKind      string
+	ObjectName      string
+	ObjectNamespace string
+	ObjectUID       string
+	Type            string
+	Reason          string
+	Source          string
+	Name            string // 事件名称，默认包含匹配
+	NamePrefix      bool   // Name 按前缀匹配
+	From            time.Time
+	To              time.Time
+}
+
+// parseEventQuery 关联对象、类型、原因和来源取自 type 参数和 fieldSelector 中的精确匹配条件，
+// 连同名称由历史存储在截断之前过滤，其余条件由调用方过滤
+func parseEventQuery(ctx iris.Context, q req.ListQuery) (EventQuery, error) {
+	eq := EventQuery{Namespace: q.Namespace, Type: q.Type, Name: q.Name, NamePrefix: q.NameMatch == req.NameMatchPrefix}
+	if q.FieldSelector != nil {
+		for field, value := range map[string]*string{
+			"involvedObject.kind":      &eq.ObjectKind,
+			"involvedObject.name":      &eq.ObjectName,
+			"involvedObject.namespace": &eq.ObjectNamespace,
+			"involvedObject.uid":       &eq.ObjectUID,
+			"type":                     &eq.Type,
+			"reason":                   &eq.Reason,
+			"source":                   &eq.Source,
+		} {
+			if v, ok := q.FieldSelector.RequiresExactMatch(field); ok && *value == "" {
+				*value = v
+			}
+		}
+	}
+	if since := ctx.URLParam("since"); since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			return eq, fmt.Errorf("invalid since %q", since)
+		}
+		eq.From = time.Now().Add(-d)
+	}
+	for param, value := range map[string]*time.Time{"from": &eq.From, "to": &eq.To} {
+		if s := ctx.URLParam(param); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return eq, fmt.Errorf("invalid %s %q, must be RFC3339", param, s)
+			}
+			*value = t
+		}
+	}
+	if !eq.From.IsZero() && !eq.To.IsZero() && eq.From.After(eq.To) {
+		return eq, fmt.Errorf("from must be before to")
+	}
+	return eq, nil
+}
+
+func (eq EventQuery) Contains(ev vo.K8sEventVo) bool {
+	for _, f := range []struct{ want, got string }{
+		{eq.Namespace, ev.Namespace},
+		{eq.ObjectKind, ev.ObjectKind},
+		{eq.ObjectName, ev.ObjectName},
+		{eq.ObjectNamespace, ev.ObjectNamespace},
+		{eq.ObjectUID, ev.ObjectUID},
+		{eq.Reason, ev.Reason},
+		{eq.Source, ev.Source},
+	} {
+		if f.want != "" && f.got != f.want {
+			return false
+		}
+	}
+	if eq.Name != "" {
+		if eq.NamePrefix && !strings.HasPrefix(ev.Name, eq.Name) {
+			return false
+		}
+		if !eq.NamePrefix && !strings.Contains(ev.Name, eq.Name) {
+			return false
+		}
+	}
+	if eq.Type != "" && !strings.EqualFold(ev.Type, eq.Type) {
+		return false
+	}
+	if !eq.From.IsZero() && parseEventTime(ev.LastTime).Before(eq.From) {
+		return false
+	}
+	if !eq.To.IsZero() && parseEventTime(ev.FirstTime).After(eq.To) {
+		return false
+	}
+	return true
+}
+
+// parseEventTime VO 中的时间为本地时间，解析失败时为零值
+func parseEventTime(value string) time.Time {
+	t, _ := time.ParseInLocation(timeLayout, value, time.Local)
+	return t
+}
+
+// eventItem createdTime 排序使用最近发生时间
+func eventItem(ev vo.K8sEventVo) req.ListItem {
+	return req.ListItem{
+		ID:         ev.EventID,
+		CreateTime: ev.LastTime,
+		Name:       ev.Name,
+		Namespace:  ev.Namespace,
+		Type:       ev.Type,
+		Fields: fields.Set{
+			"involvedObject.kind":      ev.ObjectKind,
+			"involvedObject.name":      ev.ObjectName,
+			"involvedObject.namespace": ev.ObjectNamespace,
+			"involvedObject.uid":       ev.ObjectUID,
+			"reason":                   ev.Reason,
+			"type":                     ev.Type,
+			"source":                   ev.Source,
+		},
+	}
+}
+
+// liveEvents 事件缓存可用时读取缓存，否则查询 apiserver
+func liveEvents(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.K8sEventVo, error) {
+	if c.Engine.EventsCached() {
+		return c.Engine.ListEvents(q.Namespace), nil
+	}
+	list, err := c.ClientSet.CoreV1().Events(q.Namespace).List(ctx, q.ListOptions(eventServerFields...))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]vo.K8sEventVo, 0, len(list.Items))
+	for i := range list.Items {
+		results = append(results, toK8sEventVo(c.ClusterInfo, &list.Items[i]))
+	}
+	return results, nil
+}
+
+// eventInformer 需在 Start 之前调用，Event 不计入 HasSynced，也不写入 redis 快照
+func (e *SyncEngine) eventInformer() cache.SharedIndexInformer {
+	if e.events == nil {
+		e.events = e.factory.Core().V1().Events().Informer()
+	}
+	return e.events
+}
+
+// OnEvent 注册 Event 新增和更新的回调，需在 Start 之前调用
+func (e *SyncEngine) OnEvent(handler func(ev *corev1.Event)) {
+	_, err := e.eventInformer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if ev, ok := obj.(*corev1.Event); ok {
+				handler(ev)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if ev, ok := obj.(*corev1.Event); ok {
+				handler(ev)
+			}
+		},
+	})
+	if err != nil {
+		logrus.Errorf("Failed to add event handler of cluster %s: %v", e.Cluster.ID, err)
+	}
+}
+
+// EventsCached Event 可以直接从缓存读取
+func (e *SyncEngine) EventsCached() bool {
+	return e.events != nil && e.events.HasSynced()
+}
+
+// ListEvents 返回缓存中的 Event，namespace 为空时返回所有命名空间
+func (e *SyncEngine) ListEvents(namespace string) []vo.K8sEventVo {
+	indexer := e.events.GetIndexer()
+	objs := indexer.List()
+	if namespace != "" {
+		var err error
+		if objs, err = indexer.ByIndex(cache.NamespaceIndex, namespace); err != nil {
+			logrus.Errorf("Failed to list events of namespace %s: %v", namespace, err)
+			return nil
+		}
+	}
+	results := make([]vo.K8sEventVo, 0, len(objs))
+	for _, obj := range objs {
+		results = append(results, toK8sEventVo(e.Cluster, obj.(*corev1.Event)))
+	}
+	return results
+}
+
+func toK8sEventVo(cluster ClusterInfo, ev *corev1.Event) vo.K8sEventVo {
+	first, last, count := eventOccurrences(ev)
+	source := ev.Source.Component
+	if source == "" {
+		source = ev.ReportingController
+	}
+	return vo.K8sEventVo{
+		EventID:         fmt.Sprintf("%s/%s/%s", cluster.ID, ev.Namespace, ev.Name),
+		Name:            ev.Name,
+		Namespace:       ev.Namespace,
+		ClusterID:       cluster.ID,
+		ClusterName:     cluster.Name,
+		Type:            ev.Type,
+		Reason:          ev.Reason,
+		Message:         ev.Message,
+		ObjectKind:      ev.InvolvedObject.Kind,
+		ObjectName:      ev.InvolvedObject.Name,
+		ObjectNamespace: ev.InvolvedObject.Namespace,
+		ObjectUID:       string(ev.InvolvedObject.UID),
+		FieldPath:       ev.InvolvedObject.FieldPath,
+		Source:          source,
+		Count:           count,
+		FirstTime:       first.Local().Format(timeLayout),
+		LastTime:        last.Local().Format(timeLayout),
+	}
+}
+
+// eventOccurrences 兼容 core/v1 的 FirstTimestamp/LastTimestamp/Count
+// 与 events.k8s.io 写入的 EventTime/Series
+func eventOccurrences(ev *corev1.Event) (time.Time, time.Time, int32) {
+	first := ev.FirstTimestamp.Time
+	if first.IsZero() {
+		first = ev.EventTime.Time
+	}
+	if first.IsZero() {
+		first = ev.CreationTimestamp.Time
+	}
+	last, count := ev.LastTimestamp.Time, ev.Count
+	if ev.Series != nil {
+		if ev.Series.LastObservedTime.After(last) {
+			last = ev.Series.LastObservedTime.Time
+		}
+		if ev.Series.Count > count {
+			count = ev.Series.Count
+		}
+	}
+	if last.IsZero() {
+		last = first
+	}
+	if count == 0 {
+		count = 1
+	}
+	return first, last, count
+}
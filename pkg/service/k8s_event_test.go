@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestK8sEventCache(t *testing.T) {
+	first := time.Date(2026, 5, 1, 10, 0, 0, 0, time.Local)
+	client := fake.NewSimpleClientset(
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-abcde.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-abcde", Namespace: "default", UID: "uid-1", FieldPath: "spec.containers{web}"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Source:         corev1.EventSource{Component: "kubelet"},
+			Count:          5,
+			FirstTimestamp: metav1.NewTime(first),
+			LastTimestamp:  metav1.NewTime(first.Add(time.Hour)),
+		},
+		// events.k8s.io 写入的 Event 只有 EventTime 和 Series
+		&corev1.Event{
+			ObjectMeta:          metav1.ObjectMeta{Name: "web.2", Namespace: "kube-system"},
+			InvolvedObject:      corev1.ObjectReference{Kind: "Deployment", Name: "web", Namespace: "kube-system"},
+			Type:                corev1.EventTypeNormal,
+			Reason:              "ScalingReplicaSet",
+			ReportingController: "deployment-controller",
+			EventTime:           metav1.NewMicroTime(first),
+			Series:              &corev1.EventSeries{Count: 2, LastObservedTime: metav1.NewMicroTime(first.Add(time.Minute))},
+		},
+	)
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test", Name: "测试集群"}, SyncOptions{Events: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !cache.WaitForCacheSync(ctx.Done(), engine.events.HasSynced) || !engine.EventsCached() {
+		t.Fatal("event cache did not sync")
+	}
+
+	events := engine.ListEvents("default")
+	if len(events) != 1 {
+		t.Fatalf("ListEvents(default) returned %d events", len(events))
+	}
+	want := vo.K8sEventVo{
+		EventID:         "test/default/web-abcde.1",
+		Name:            "web-abcde.1",
+		Namespace:       "default",
+		ClusterID:       "test",
+		ClusterName:     "测试集群",
+		Type:            corev1.EventTypeWarning,
+		Reason:          "BackOff",
+		Message:         "Back-off restarting failed container",
+		ObjectKind:      "Pod",
+		ObjectName:      "web-abcde",
+		ObjectNamespace: "default",
+		ObjectUID:       "uid-1",
+		FieldPath:       "spec.containers{web}",
+		Source:          "kubelet",
+		Count:           5,
+		FirstTime:       "2026-05-01 10:00:00",
+		LastTime:        "2026-05-01 11:00:00",
+	}
+	if events[0] != want {
+		t.Errorf("event = %+v, want %+v", events[0], want)
+	}
+
+	events = engine.ListEvents("kube-system")
+	if len(events) != 1 {
+		t.Fatalf("ListEvents(kube-system) returned %d events", len(events))
+	}
+	if ev := events[0]; ev.Source != "deployment-controller" || ev.Count != 2 ||
+		ev.FirstTime != "2026-05-01 10:00:00" || ev.LastTime != "2026-05-01 10:01:00" {
+		t.Errorf("series event = %+v", ev)
+	}
+	if n := len(engine.ListEvents("")); n != 2 {
+		t.Errorf("ListEvents() returned %d events", n)
+	}
+}
+
+func TestEventQueryContains(t *testing.T) {
+	at := func(value string) time.Time {
+		return parseEventTime(value)
+	}
+	ev := vo.K8sEventVo{Name: "web.1", Namespace: "default", FirstTime: "2026-05-01 10:00:00", LastTime: "2026-05-01 12:00:00"}
+
+	tests := []struct {
+		name string
+		q    EventQuery
+		want bool
+	}{
+		{"no window", EventQuery{}, true},
+		{"other namespace", EventQuery{Namespace: "kube-system"}, false},
+		{"other reason", EventQuery{Reason: "BackOff"}, false},
+		{"name contains", EventQuery{Name: "eb"}, true},
+		{"name prefix", EventQuery{Name: "eb", NamePrefix: true}, false},
+		{"overlaps start", EventQuery{From: at("2026-05-01 11:00:00")}, true},
+		{"after last", EventQuery{From: at("2026-05-01 12:00:01")}, false},
+		{"before first", EventQuery{To: at("2026-05-01 09:59:59")}, false},
+		{"inside", EventQuery{From: at("2026-05-01 10:30:00"), To: at("2026-05-01 11:30:00")}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Contains(ev); got != tt.want {
+			t.Errorf("%s: Contains() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRedisEventHistory(t *testing.T) {
+	mr, client := newTestRedis(t)
+	h := &RedisEventHistory{client: client, prefix: "cmdb-k8s-", maxResults: 2}
+	ctx := context.Background()
+
+	events := []vo.K8sEventVo{
+		{EventID: "test/default/a", Name: "a", Namespace: "default", ObjectKind: "Pod", ObjectName: "web-0", FirstTime: "2026-05-01 08:00:00", LastTime: "2026-05-01 08:00:00"},
+		{EventID: "test/default/b", Name: "b", Namespace: "default", ObjectKind: "Pod", ObjectName: "web-1", Type: "Warning", Reason: "BackOff", Source: "kubelet", FirstTime: "2026-05-01 09:00:00", LastTime: "2026-05-01 10:00:00"},
+		{EventID: "test/kube-system/c", Name: "c", Namespace: "kube-system", ObjectKind: "Pod", ObjectName: "dns", FirstTime: "2026-05-01 11:00:00", LastTime: "2026-05-01 11:00:00"},
+	}
+	if err := h.Save(ctx, "test", events); err != nil {
+		t.Fatal(err)
+	}
+	// 同一个 Event 再次发生时覆盖
+	events[0].Count, events[0].LastTime = 2, "2026-05-01 12:00:00"
+	if err := h.Save(ctx, "test", events[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(q EventQuery) ([]string, bool) {
+		results, truncated, err := h.Query(ctx, "test", q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, ev := range results {
+			names = append(names, ev.Name)
+		}
+		return names, truncated
+	}
+	// 按最近发生时间倒序，最多 maxResults 条
+	if got, truncated := query(EventQuery{}); len(got) != 2 || got[0] != "a" || got[1] != "c" || !truncated {
+		t.Errorf("Query() = %v, %v, want [a c] truncated", got, truncated)
+	}
+	// 关联对象和类型在截断之前过滤
+	if got, truncated := query(EventQuery{Namespace: "default", ObjectName: "web-1"}); len(got) != 1 || got[0] != "b" || truncated {
+		t.Errorf("Query(web-1) = %v, %v, want [b]", got, truncated)
+	}
+	if got, _ := query(EventQuery{Type: "warning"}); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Query(Warning) = %v, want [b]", got)
+	}
+	// 原因、来源和名称同样在截断之前过滤，较早的匹配不会被丢弃
+	for _, q := range []EventQuery{{Reason: "BackOff"}, {Source: "kubelet"}, {Name: "b"}, {Name: "b", NamePrefix: true}} {
+		if got, truncated := query(q); len(got) != 1 || got[0] != "b" || truncated {
+			t.Errorf("Query(%+v) = %v, %v, want [b]", q, got, truncated)
+		}
+	}
+	// a 在 to 之前开始、之后仍在发生，与时间范围有交集
+	if got, _ := query(EventQuery{From: parseEventTime("2026-05-01 07:00:00"), To: parseEventTime("2026-05-01 09:30:00")}); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Query(07:00-09:30) = %v, want [a b]", got)
+	}
+	h.maxResults = 10
+	if got, _ := query(EventQuery{Namespace: "default", From: parseEventTime("2026-05-01 09:30:00")}); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Query(default, from 09:30) = %v, want [a b]", got)
+	}
+
+	if err := h.Prune(ctx, "test", parseEventTime("2026-05-01 11:00:00")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := query(EventQuery{}); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("Query() after Prune = %v, want [a c]", got)
+	}
+	if fields, _ := mr.HKeys("cmdb-k8s-test:k8sevents:data"); len(fields) != 2 {
+		t.Errorf("data hash has %d events after Prune", len(fields))
+	}
+	if mr.Exists("cmdb-k8s-test:k8sevents:object:web-1") {
+		t.Error("object index not pruned")
+	}
+}
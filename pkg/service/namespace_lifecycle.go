@@ -0,0 +1,479 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+)
+
+// 命名空间操作的审计 action
+const (
+	AuditCreate = "create"
+	AuditDelete = "delete"
+)
+
+const (
+	// namespaceTemplateAnnotation 记录创建命名空间时使用的模板
+	namespaceTemplateAnnotation = "kubeapi/template"
+	// managedByLabel 标记由 kubeapi 按模板创建的对象
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "kubeapi"
+
+	defaultConfirmTTL = 5 * time.Minute
+	// previewNames 删除预览中每类资源最多返回的名称数量
+	previewNames = 50
+	// previewConcurrency 删除预览时并发列出资源的数量
+	previewConcurrency = 8
+)
+
+// defaultProtectedNamespaces 未配置 namespaces.protected 时禁止删除的命名空间
+var defaultProtectedNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease"}
+
+// NamespaceTemplate namespaces.templates 中的模板，各字段格式与对应 Kubernetes 资源的 spec 相同
+type NamespaceTemplate struct {
+	Labels          map[string]string                         `json:"labels"`
+	Annotations     map[string]string                         `json:"annotations"`
+	ResourceQuota   *corev1.ResourceQuotaSpec                 `json:"resourceQuota"`   // 名称与模板相同
+	LimitRange      *corev1.LimitRangeSpec                    `json:"limitRange"`      // 名称与模板相同
+	NetworkPolicies map[string]networkingv1.NetworkPolicySpec `json:"networkPolicies"` // 名称 -> spec
+	RoleBindings    map[string]RoleBindingTemplate            `json:"roleBindings"`    // 名称 -> roleRef 和 subjects
+}
+
+// RoleBindingTemplate 模板中的 RoleBinding
+type RoleBindingTemplate struct {
+	RoleRef  rbacv1.RoleRef   `json:"roleRef"`
+	Subjects []rbacv1.Subject `json:"subjects"`
+}
+
+// NamespaceOptions 命名空间创建和删除的配置
+type NamespaceOptions struct {
+	Templates  map[string]NamespaceTemplate
+	Protected  sets.Set[string] // 禁止删除的命名空间
+	ConfirmTTL time.Duration    // 删除确认 token 的有效期
+	secret     []byte           // 签名删除确认 token 的密钥
+}
+
+func LoadNamespaceOptions() NamespaceOptions {
+	options := NamespaceOptions{
+		Templates:  map[string]NamespaceTemplate{},
+		Protected:  sets.New(defaultProtectedNamespaces...),
+		ConfirmTTL: sysinit.UDuration("namespaces.confirmTTL", defaultConfirmTTL),
+		secret:     []byte(sysinit.GCF.UString("namespaces.confirmSecret")),
+	}
+	for name, value := range sysinit.GCF.UMap("namespaces.templates") {
+		template, err := parseNamespaceTemplate(value)
+		if err != nil {
+			logrus.Errorf("Invalid namespaces.templates.%s: %v", name, err)
+			continue
+		}
+		options.Templates[name] = template
+	}
+	if list, err := sysinit.GCF.List("namespaces.protected"); err == nil {
+		options.Protected = sets.New[string]()
+		for _, item := range list {
+			if value, ok := item.(string); ok && value != "" {
+				options.Protected.Insert(value)
+			}
+		}
+	}
+	// 未配置密钥时使用随机密钥，多实例部署时需要配置相同的密钥
+	if len(options.secret) == 0 {
+		options.secret = make([]byte, 32)
+		rand.Read(options.secret)
+	}
+	return options
+}
+
+// parseNamespaceTemplate 将配置转换为模板，未知字段视为配置错误
+func parseNamespaceTemplate(value interface{}) (NamespaceTemplate, error) {
+	var template NamespaceTemplate
+	data, err := json.Marshal(value)
+	if err != nil {
+		return template, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&template); err != nil {
+		return template, err
+	}
+	for name, binding := range template.RoleBindings {
+		if binding.RoleRef.Name == "" || (binding.RoleRef.Kind != "Role" && binding.RoleRef.Kind != "ClusterRole") {
+			return template, fmt.Errorf("roleBindings.%s: roleRef must have a name and kind Role or ClusterRole", name)
+		}
+		if binding.RoleRef.APIGroup == "" {
+			binding.RoleRef.APIGroup = rbacv1.GroupName
+			template.RoleBindings[name] = binding
+		}
+	}
+	return template, nil
+}
+
+// namespaceObject 随命名空间创建的对象
+type namespaceObject struct {
+	kind   string
+	name   string
+	create func(ctx context.Context, c *Cluster) error
+}
+
+// objects 模板中命名空间下的对象，按 ResourceQuota、LimitRange、NetworkPolicy、RoleBinding 的顺序创建
+func (t NamespaceTemplate) objects(template, namespace string) []namespaceObject {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{managedByLabel: managedByValue}}
+	}
+	var objects []namespaceObject
+	if t.ResourceQuota != nil {
+		quota := &corev1.ResourceQuota{ObjectMeta: meta(template), Spec: *t.ResourceQuota}
+		objects = append(objects, namespaceObject{"ResourceQuota", template, func(ctx context.Context, c *Cluster) error {
+			_, err := c.ClientSet.CoreV1().ResourceQuotas(namespace).Create(ctx, quota, metav1.CreateOptions{})
+			return err
+		}})
+	}
+	if t.LimitRange != nil {
+		limitRange := &corev1.LimitRange{ObjectMeta: meta(template), Spec: *t.LimitRange}
+		objects = append(objects, namespaceObject{"LimitRange", template, func(ctx context.Context, c *Cluster) error {
+			_, err := c.ClientSet.CoreV1().LimitRanges(namespace).Create(ctx, limitRange, metav1.CreateOptions{})
+			return err
+		}})
+	}
+	for _, name := range sets.List(sets.KeySet(t.NetworkPolicies)) {
+		policy := &networkingv1.NetworkPolicy{ObjectMeta: meta(name), Spec: t.NetworkPolicies[name]}
+		objects = append(objects, namespaceObject{"NetworkPolicy", name, func(ctx context.Context, c *Cluster) error {
+			_, err := c.ClientSet.NetworkingV1().NetworkPolicies(namespace).Create(ctx, policy, metav1.CreateOptions{})
+			return err
+		}})
+	}
+	for _, name := range sets.List(sets.KeySet(t.RoleBindings)) {
+		binding := &rbacv1.RoleBinding{ObjectMeta: meta(name), RoleRef: t.RoleBindings[name].RoleRef, Subjects: t.RoleBindings[name].Subjects}
+		objects = append(objects, namespaceObject{"RoleBinding", name, func(ctx context.Context, c *Cluster) error {
+			_, err := c.ClientSet.RbacV1().RoleBindings(namespace).Create(ctx, binding, metav1.CreateOptions{})
+			return err
+		}})
+	}
+	return objects
+}
+
+// mergeStringMap 合并多个 map，后面的覆盖前面的
+func mergeStringMap(maps ...map[string]string) map[string]string {
+	result := map[string]string{}
+	for _, m := range maps {
+		for key, value := range m {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// templateConflicts 返回请求中与模板取值不同的 key，按名称排序
+func templateConflicts(requested map[string]string, templates ...map[string]string) []string {
+	var conflicts []string
+	for key, value := range requested {
+		for _, template := range templates {
+			if fixed, ok := template[key]; ok && fixed != value {
+				conflicts = append(conflicts, key)
+				break
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// CreateNamespace
+// @Summary 按模板创建命名空间
+// @Description 模板在 namespaces.templates 中配置，包括标签、注解、ResourceQuota、LimitRange、NetworkPolicy 和 RoleBinding。
+// @Description 请求中的标签和注解不能覆盖模板中取值不同的同名 key。命名空间创建后依次创建模板中的对象，部分对象失败时返回 500，data 中列出每个对象的结果。操作写入审计记录。
+// @Tags　k8s
+// @Accept application/json
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param body body vo.NamespaceRequestVo true "命名空间名称和模板"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 500 {object} resp.Message
+// @Router /api/v1/k8s/namespaces [post]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces [post]
+func (k *K8sService) CreateNamespace(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	var body vo.NamespaceRequestVo
+	if err := ctx.ReadJSON(&body); err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	if body.Name == "" {
+		ctx.JSON(resp.BadResponse("name is required"))
+		return
+	}
+	template, ok := k.Namespaces.Templates[body.Template]
+	if !ok {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("unknown template %q, available: %s",
+			body.Template, strings.Join(sets.List(sets.KeySet(k.Namespaces.Templates)), ", "))))
+		return
+	}
+
+	// 模板中的标签和注解（例如 pod-security 策略）由平台维护，请求不能修改
+	conflicts := append(templateConflicts(body.Labels, template.Labels),
+		templateConflicts(body.Annotations, template.Annotations, map[string]string{namespaceTemplateAnnotation: body.Template})...)
+	if len(conflicts) > 0 {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("%s defined by template %s cannot be overridden", strings.Join(conflicts, ", "), body.Template)))
+		return
+	}
+
+	record := k.Registry.audit.Begin(ctx, c.ID, AuditCreate, "Namespace", "", body.Name)
+	auditDetail(record, body)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        body.Name,
+		Labels:      mergeStringMap(template.Labels, body.Labels),
+		Annotations: mergeStringMap(template.Annotations, body.Annotations, map[string]string{namespaceTemplateAnnotation: body.Template}),
+	}}
+	ns, err = c.ClientSet.CoreV1().Namespaces().Create(ctx.Request().Context(), ns, metav1.CreateOptions{})
+	if err != nil {
+		k.Registry.audit.End(record, err)
+		if apierrors.IsAlreadyExists(err) || apierrors.IsInvalid(err) {
+			ctx.JSON(resp.BadResponse(err.Error()))
+			return
+		}
+		ctx.JSON(resp.ErrorWithMsg(err.Error()))
+		return
+	}
+
+	result := vo.NamespaceCreateVo{Namespace: toNamespaceVo(c.ClusterInfo, ns), Template: body.Template, Objects: []vo.NamespaceObjectVo{}}
+	var failed []string
+	for _, object := range template.objects(body.Template, body.Name) {
+		item := vo.NamespaceObjectVo{Kind: object.kind, Name: object.name}
+		if err := object.create(ctx.Request().Context(), c); err != nil {
+			item.Error = err.Error()
+			failed = append(failed, object.kind+"/"+object.name)
+		}
+		result.Objects = append(result.Objects, item)
+	}
+	if len(failed) > 0 {
+		err = fmt.Errorf("namespace %s created but failed to create %s", body.Name, strings.Join(failed, ", "))
+	}
+	k.Registry.audit.End(record, err)
+	if err != nil {
+		ctx.JSON(resp.Message{Code: resp.MESSAGE_ERROR, Message: err.Error(), Data: result})
+		return
+	}
+	ctx.JSON(resp.OkWithData(result))
+}
+
+// PreviewNamespaceDeletion
+// @Summary 预览删除命名空间
+// @Description 列出命名空间中将被删除的对象（不含 events），并返回删除确认 token，token 在 namespaces.confirmTTL 内有效
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param name path string true "名称"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{name}/deletion-preview [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{name}/deletion-preview [get]
+func (k *K8sService) PreviewNamespaceDeletion(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	name := ctx.Params().Get("name")
+	if k.Namespaces.Protected.Has(name) {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("namespace %s is protected", name)))
+		return
+	}
+	ns, err := c.ClientSet.CoreV1().Namespaces().Get(ctx.Request().Context(), name, metav1.GetOptions{})
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	resources, errs, err := namespaceResources(ctx.Request().Context(), c, name)
+	if err != nil {
+		ctx.JSON(resp.ErrorWithMsg(err.Error()))
+		return
+	}
+
+	expire := time.Now().Add(k.Namespaces.ConfirmTTL)
+	preview := vo.NamespaceDeletionPreviewVo{
+		Name:       name,
+		ClusterID:  c.ID,
+		Resources:  resources,
+		Errors:     errs,
+		Token:      k.Namespaces.confirmToken(c.ID, ns, expire),
+		ExpireTime: expire.Format(timeLayout),
+	}
+	for _, r := range resources {
+		preview.Total += r.Count
+	}
+	ctx.JSON(resp.OkWithData(preview))
+}
+
+// DeleteNamespace
+// @Summary 删除命名空间
+// @Description 需要携带删除预览返回的 token，命名空间被重建或 token 过期后需要重新预览。namespaces.protected 中的命名空间不能删除。操作写入审计记录。
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param name path string true "名称"
+// @Param token query string true "删除预览返回的确认 token"
+// @Param dryRun query string false "All 表示只校验不删除"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{name} [delete]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{name} [delete]
+func (k *K8sService) DeleteNamespace(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	name := ctx.Params().Get("name")
+	if k.Namespaces.Protected.Has(name) {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("namespace %s is protected", name)))
+		return
+	}
+	ns, err := c.ClientSet.CoreV1().Namespaces().Get(ctx.Request().Context(), name, metav1.GetOptions{})
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	if !k.Namespaces.verifyToken(c.ID, ns, ctx.URLParam("token"), time.Now()) {
+		ctx.JSON(resp.BadResponse("invalid or expired confirmation token, preview the deletion first"))
+		return
+	}
+
+	record := k.Registry.audit.Begin(ctx, c.ID, AuditDelete, "Namespace", "", name)
+	record.DryRun = dryRun != nil
+	// 只删除预览时的命名空间，期间被重建的不删除
+	err = c.ClientSet.CoreV1().Namespaces().Delete(ctx.Request().Context(), name, metav1.DeleteOptions{
+		DryRun:        dryRun,
+		Preconditions: metav1.NewUIDPreconditions(string(ns.UID)),
+	})
+	k.Registry.audit.End(record, err)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(resp.Ok())
+}
+
+// confirmToken 删除确认 token，格式为 过期时间.签名，绑定集群、命名空间名称和 UID
+func (o NamespaceOptions) confirmToken(clusterID string, ns *corev1.Namespace, expire time.Time) string {
+	mac := hmac.New(sha256.New, o.secret)
+	fmt.Fprintf(mac, "%s/%s/%s/%d", clusterID, ns.Name, ns.UID, expire.Unix())
+	return strconv.FormatInt(expire.Unix(), 10) + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (o NamespaceOptions) verifyToken(clusterID string, ns *corev1.Namespace, token string, now time.Time) bool {
+	value, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expire, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || now.Unix() > expire {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(o.confirmToken(clusterID, ns, time.Unix(expire, 0))))
+}
+
+// namespaceResources 通过 discovery 列出命名空间中所有可删除的资源，返回对象数大于 0 的资源类型和无法列出的资源类型
+func namespaceResources(ctx context.Context, c *Cluster, namespace string) ([]vo.NamespaceResourceVo, []string, error) {
+	lists, err := c.Discovery.ServerPreferredNamespacedResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, nil, err
+		}
+		logrus.Warnf("Partial discovery failure in cluster %s: %v", c.ID, err)
+	}
+
+	var targets []vo.NamespaceResourceVo
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			// events 随命名空间删除，数量多且没有预览价值
+			if strings.Contains(r.Name, "/") || r.Name == "events" || !sets.New(r.Verbs...).HasAll("list", "delete") {
+				continue
+			}
+			targets = append(targets, vo.NamespaceResourceVo{Group: gv.Group, Version: gv.Version, Resource: r.Name, Kind: r.Kind})
+		}
+	}
+
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, previewConcurrency)
+	for i := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(target *vo.NamespaceResourceVo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			gvr := schema.GroupVersionResource{Group: target.Group, Version: target.Version, Resource: target.Resource}
+			list, err := c.Metadata.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", gvr.GroupResource(), err)
+				return
+			}
+			target.Count = len(list.Items)
+			for j := 0; j < len(list.Items) && j < previewNames; j++ {
+				target.Names = append(target.Names, list.Items[j].GetName())
+			}
+		}(&targets[i])
+	}
+	wg.Wait()
+
+	resources := []vo.NamespaceResourceVo{}
+	for _, target := range targets {
+		if target.Count > 0 {
+			resources = append(resources, target)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Group != resources[j].Group {
+			return resources[i].Group < resources[j].Group
+		}
+		return resources[i].Resource < resources[j].Resource
+	})
+	var messages []string
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			messages = append(messages, err.Error())
+		}
+	}
+	return resources, messages, nil
+}
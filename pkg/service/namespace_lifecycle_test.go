@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/olebedev/config"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+// TestLoadNamespaceOptions 校验 application.yml 中的示例模板
+func TestLoadNamespaceOptions(t *testing.T) {
+	cfg, err := config.ParseYamlFile("../../configs/application.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(old *config.Config) { sysinit.GCF = old }(sysinit.GCF)
+	sysinit.GCF = cfg
+
+	options := LoadNamespaceOptions()
+	template, ok := options.Templates["standard"]
+	if !ok {
+		t.Fatalf("templates = %v", options.Templates)
+	}
+	if q := template.ResourceQuota.Hard[corev1.ResourceRequestsCPU]; q.String() != "8" {
+		t.Errorf("requests.cpu = %s", q.String())
+	}
+	if binding := template.RoleBindings["namespace-admin"]; binding.RoleRef.APIGroup != "rbac.authorization.k8s.io" || len(binding.Subjects) != 1 {
+		t.Errorf("roleBinding = %+v", binding)
+	}
+	if len(template.objects("standard", "team-a")) != 5 {
+		t.Errorf("objects = %d, want 5", len(template.objects("standard", "team-a")))
+	}
+	if !options.Protected.Has("kube-system") || options.ConfirmTTL != 5*time.Minute {
+		t.Errorf("protected = %v, confirmTTL = %s", options.Protected, options.ConfirmTTL)
+	}
+
+	if _, err := parseNamespaceTemplate(map[string]interface{}{"resourceQuotas": map[string]interface{}{}}); err == nil {
+		t.Error("unknown field should be rejected")
+	}
+	if _, err := parseNamespaceTemplate(map[string]interface{}{"roleBindings": map[string]interface{}{"x": map[string]interface{}{}}}); err == nil {
+		t.Error("roleBinding without roleRef should be rejected")
+	}
+}
+
+func TestConfirmToken(t *testing.T) {
+	o := NamespaceOptions{secret: []byte("secret")}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", UID: "uid-1"}}
+	now := time.Now()
+	token := o.confirmToken("test", ns, now.Add(time.Minute))
+	if !o.verifyToken("test", ns, token, now) {
+		t.Error("valid token rejected")
+	}
+	if o.verifyToken("test", ns, token, now.Add(2*time.Minute)) {
+		t.Error("expired token accepted")
+	}
+	if o.verifyToken("prod", ns, token, now) {
+		t.Error("token accepted for another cluster")
+	}
+	recreated := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", UID: "uid-2"}}
+	if o.verifyToken("test", recreated, token, now) {
+		t.Error("token accepted for recreated namespace")
+	}
+}
+
+func TestNamespaceLifecycle(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}})
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: metav1.Verbs{"list", "delete"}},
+			{Name: "events", Namespaced: true, Kind: "Event", Verbs: metav1.Verbs{"list", "delete"}},
+			{Name: "pods", Namespaced: true, Kind: "Pod", Verbs: metav1.Verbs{"list", "delete"}},
+		},
+	}}
+	configMap := &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "team-a"},
+	}
+	scheme := metadatafake.NewTestScheme()
+	metav1.AddMetaToScheme(scheme)
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {
+			ClusterInfo: ClusterInfo{ID: "test"},
+			ClientSet:   client,
+			Metadata:    metadatafake.NewSimpleMetadataClient(scheme, configMap),
+			Discovery:   memory.NewMemCacheClient(client.Discovery()),
+		}},
+		order: []string{"test"},
+	}
+	template, err := parseNamespaceTemplate(map[string]interface{}{
+		"labels":        map[string]interface{}{"env": "dev", "team": "default"},
+		"resourceQuota": map[string]interface{}{"hard": map[string]interface{}{"pods": 10}},
+		"networkPolicies": map[string]interface{}{
+			"default-deny": map[string]interface{}{"podSelector": map[string]interface{}{}, "policyTypes": []interface{}{"Ingress"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &K8sService{Registry: reg, Namespaces: NamespaceOptions{
+		Templates:  map[string]NamespaceTemplate{"standard": template},
+		Protected:  sets.New("kube-system"),
+		ConfirmTTL: time.Minute,
+		secret:     []byte("secret"),
+	}}
+	app := iris.New()
+	app.Post("/namespaces", k.CreateNamespace)
+	app.Get("/namespaces/{name}/deletion-preview", k.PreviewNamespaceDeletion)
+	app.Delete("/namespaces/{name}", k.DeleteNamespace)
+	e := httptest.New(t, app)
+
+	// 模板中的标签不能被请求覆盖
+	e.POST("/namespaces").WithJSON(map[string]interface{}{"name": "team-a", "template": "standard", "labels": map[string]string{"team": "a"}}).
+		Expect().JSON().Object().Value("message").String().Contains("team")
+	data := e.POST("/namespaces").WithJSON(map[string]interface{}{"name": "team-a", "template": "standard", "labels": map[string]string{"team": "default", "owner": "a"}}).
+		Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	data.Path("$.namespace.labels").Object().IsEqual(map[string]string{"env": "dev", "team": "default", "owner": "a"})
+	data.Value("objects").Array().Length().IsEqual(2)
+	ns, err := client.CoreV1().Namespaces().Get(context.Background(), "team-a", metav1.GetOptions{})
+	if err != nil || ns.Annotations[namespaceTemplateAnnotation] != "standard" {
+		t.Fatalf("namespace = %+v, %v", ns, err)
+	}
+	if _, err := client.NetworkingV1().NetworkPolicies("team-a").Get(context.Background(), "default-deny", metav1.GetOptions{}); err != nil {
+		t.Error(err)
+	}
+	e.POST("/namespaces").WithJSON(map[string]string{"name": "team-a", "template": "standard"}).Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+	e.POST("/namespaces").WithJSON(map[string]string{"name": "team-b", "template": "missing"}).Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+
+	preview := e.GET("/namespaces/team-a/deletion-preview").Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	preview.Value("total").IsEqual(1)
+	preview.Path("$.resources[0].names").IsEqual([]string{"settings"})
+	token := preview.Value("token").String().Raw()
+
+	e.DELETE("/namespaces/team-a").Expect().JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+	e.DELETE("/namespaces/team-a").WithQuery("token", token+"0").Expect().JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+	e.DELETE("/namespaces/team-a").WithQuery("token", token).Expect().Status(httptest.StatusOK)
+	if _, err := client.CoreV1().Namespaces().Get(context.Background(), "team-a", metav1.GetOptions{}); err == nil {
+		t.Error("namespace was not deleted")
+	}
+	e.GET("/namespaces/kube-system/deletion-preview").Expect().JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+	e.DELETE("/namespaces/missing").WithQuery("token", token).Expect().Status(httptest.StatusNotFound)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// GetNodes
+// @Summary 获取集群node信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器，支持 spec.unschedulable"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/nodes [get]
+// @Router /api/v1/clusters/{cluster}/k8s/nodes [get]
+func (k *K8sService) GetNodes(ctx iris.Context) {
+	listKind(k, ctx, KindNode, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.NodeVo, metav1.ListMeta, error) {
+		nodeList, err := c.ClientSet.CoreV1().Nodes().List(ctx, q.ListOptions("spec.unschedulable"))
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.NodeVo
+		for i := range nodeList.Items {
+			results = append(results, toNodeVo(c.ClusterInfo, &nodeList.Items[i]))
+		}
+		return results, nodeList.ListMeta, nil
+	}, nodeItem)
+}
+
+func nodeItem(n vo.NodeVo) req.ListItem {
+	return req.ListItem{
+		ID:         n.NodeID,
+		CreateTime: n.CreateTime,
+		Name:       n.Name,
+		Labels:     n.Labels,
+		Fields:     fields.Set{"spec.unschedulable": strconv.FormatBool(n.Unschedulable)},
+	}
+}
+
+func toNodeVo(cluster ClusterInfo, node *corev1.Node) vo.NodeVo {
+	info := node.Status.NodeInfo
+	n := vo.NodeVo{
+		NodeID:           fmt.Sprintf("%s/Node/%s", cluster.ID, node.Name),
+		Name:             node.Name,
+		ClusterID:        cluster.ID,
+		ClusterName:      cluster.Name,
+		Roles:            nodeRoles(node.Labels),
+		Unschedulable:    node.Spec.Unschedulable,
+		KubeletVersion:   info.KubeletVersion,
+		ContainerRuntime: info.ContainerRuntimeVersion,
+		OSImage:          info.OSImage,
+		KernelVersion:    info.KernelVersion,
+		OS:               info.OperatingSystem,
+		Arch:             info.Architecture,
+		ProviderID:       node.Spec.ProviderID,
+		Region:           firstLabel(node.Labels, corev1.LabelTopologyRegion, corev1.LabelFailureDomainBetaRegion),
+		Zone:             firstLabel(node.Labels, corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone),
+		InstanceType:     firstLabel(node.Labels, corev1.LabelInstanceTypeStable, corev1.LabelInstanceType),
+		Capacity:         nodeResources(node.Status.Capacity),
+		Allocatable:      nodeResources(node.Status.Allocatable),
+		Labels:           node.Labels,
+		CreateTime:       node.CreationTimestamp.Local().Format(timeLayout),
+	}
+
+	for _, addr := range node.Status.Addresses {
+		n.Addresses = append(n.Addresses, vo.NodeAddressVo{Type: string(addr.Type), Address: addr.Address})
+		switch addr.Type {
+		case corev1.NodeInternalIP:
+			if n.InternalIP == "" {
+				n.InternalIP = addr.Address
+			}
+		case corev1.NodeExternalIP:
+			if n.ExternalIP == "" {
+				n.ExternalIP = addr.Address
+			}
+		case corev1.NodeHostName:
+			n.Hostname = addr.Address
+		}
+	}
+	// 心跳时间等字段频繁变化，只保留类型、状态和原因
+	for _, cond := range node.Status.Conditions {
+		n.Conditions = append(n.Conditions, vo.NodeConditionVo{
+			Type:   string(cond.Type),
+			Status: string(cond.Status),
+			Reason: cond.Reason,
+		})
+		if cond.Type == corev1.NodeReady {
+			n.Ready = cond.Status == corev1.ConditionTrue
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		n.Taints = append(n.Taints, vo.NodeTaintVo{Key: taint.Key, Value: taint.Value, Effect: string(taint.Effect)})
+	}
+	return n
+}
+
+// nodeRoles 从 node-role.kubernetes.io/<role> 标签解析角色
+func nodeRoles(labels map[string]string) []string {
+	var roles []string
+	for key := range labels {
+		if role := strings.TrimPrefix(key, nodeRoleLabelPrefix); role != key && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if role := labels["kubernetes.io/role"]; role != "" {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func firstLabel(labels map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func nodeResources(list corev1.ResourceList) vo.NodeResourceVo {
+	quantity := func(name corev1.ResourceName) string {
+		if q, ok := list[name]; ok {
+			return q.String()
+		}
+		return ""
+	}
+	return vo.NodeResourceVo{
+		CPU:              quantity(corev1.ResourceCPU),
+		Memory:           quantity(corev1.ResourceMemory),
+		Pods:             quantity(corev1.ResourcePods),
+		EphemeralStorage: quantity(corev1.ResourceEphemeralStorage),
+	}
+}
+
+// GetNode
+// @Summary 获取单个node
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/nodes/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/nodes/{name} [get]
+func (k *K8sService) GetNode(ctx iris.Context) {
+	k.getObject(ctx, KindNode)
+}
+
+func liveNode(ctx context.Context, c *Cluster, _, name string) (interface{}, error) {
+	node, err := c.ClientSet.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toNodeVo(c.ClusterInfo, node), nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestToNodeVo(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				"node-role.kubernetes.io/control-plane": "",
+				"node-role.kubernetes.io/worker":        "",
+				corev1.LabelTopologyZone:                "ap-east-1a",
+				corev1.LabelInstanceTypeStable:          "m5.large",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "aws:///ap-east-1a/i-123",
+			Taints:     []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3800m"),
+				corev1.ResourceMemory: resource.MustParse("15Gi"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady"},
+			},
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeExternalIP, Address: "1.2.3.4"},
+			},
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.33.3", Architecture: "amd64"},
+		},
+	}
+
+	n := toNodeVo(ClusterInfo{ID: "test"}, node)
+	if n.NodeID != "test/Node/node-1" || !n.Ready || n.KubeletVersion != "v1.33.3" || n.Arch != "amd64" {
+		t.Fatalf("toNodeVo() = %+v", n)
+	}
+	if !reflect.DeepEqual(n.Roles, []string{"control-plane", "worker"}) {
+		t.Fatalf("Roles = %v", n.Roles)
+	}
+	if n.Zone != "ap-east-1a" || n.InstanceType != "m5.large" || n.InternalIP != "10.0.0.1" || n.ExternalIP != "1.2.3.4" {
+		t.Fatalf("toNodeVo() = %+v", n)
+	}
+	if n.Capacity.CPU != "4" || n.Capacity.Memory != "16Gi" || n.Allocatable.CPU != "3800m" || n.Allocatable.Pods != "" {
+		t.Fatalf("capacity = %+v, allocatable = %+v", n.Capacity, n.Allocatable)
+	}
+	if len(n.Taints) != 1 || n.Taints[0].Effect != "NoSchedule" || len(n.Conditions) != 2 {
+		t.Fatalf("taints = %+v, conditions = %+v", n.Taints, n.Conditions)
+	}
+}
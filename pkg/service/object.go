@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kataras/iris/v12"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// view 参数
+const (
+	ViewSummary = "summary"
+	ViewRaw     = "raw"
+)
+
+// objectKind 单个对象查询所需的信息
+type objectKind struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+	// live 缓存不可用时从 apiserver 获取对象并转换为 VO
+	live func(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error)
+}
+
+var objectKinds = map[string]objectKind{
+	KindNamespace:   {gvr: corev1.SchemeGroupVersion.WithResource("namespaces"), live: liveNamespace},
+	KindNode:        {gvr: corev1.SchemeGroupVersion.WithResource("nodes"), live: liveNode},
+	KindService:     {gvr: corev1.SchemeGroupVersion.WithResource("services"), namespaced: true, live: liveService},
+	KindPod:         {gvr: corev1.SchemeGroupVersion.WithResource("pods"), namespaced: true, live: livePod},
+	KindDeployment:  {gvr: appsv1.SchemeGroupVersion.WithResource("deployments"), namespaced: true, live: liveDeployment},
+	KindStatefulSet: {gvr: appsv1.SchemeGroupVersion.WithResource("statefulsets"), namespaced: true, live: liveStatefulSet},
+	KindDaemonSet:   {gvr: appsv1.SchemeGroupVersion.WithResource("daemonsets"), namespaced: true, live: liveDaemonSet},
+	KindIngress:     {gvr: networkingv1.SchemeGroupVersion.WithResource("ingresses"), namespaced: true, live: liveIngress},
+	KindGateway:     {gvr: gatewayGVR, namespaced: true, live: liveGateway},
+	KindHTTPRoute:   {gvr: httpRouteGVR, namespaced: true, live: liveHTTPRoute},
+}
+
+// getObject 获取单个对象，默认返回 VO，view=raw 时返回 apiserver 中的原始对象（去掉 managedFields）
+func (k *K8sService) getObject(ctx iris.Context, kind string) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	spec := objectKinds[kind]
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+	key := name
+	if spec.namespaced {
+		key = namespace + "/" + name
+	}
+
+	switch ctx.URLParamDefault("view", ViewSummary) {
+	case ViewSummary:
+	case ViewRaw:
+		var ri dynamic.ResourceInterface = c.Dynamic.Resource(spec.gvr)
+		if spec.namespaced {
+			ri = c.Dynamic.Resource(spec.gvr).Namespace(namespace)
+		}
+		obj, err := ri.Get(ctx.Request().Context(), name, metav1.GetOptions{})
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		obj.SetManagedFields(nil)
+		ctx.JSON(resp.OkWithData(obj.Object))
+		return
+	default:
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("invalid view, must be %s or %s", ViewSummary, ViewRaw)))
+		return
+	}
+
+	if c.Engine.Cached(kind) {
+		item, found := c.Engine.Get(kind, key)
+		if !found {
+			notFound(ctx, fmt.Sprintf("%s %s not found", kind, key))
+			return
+		}
+		ctx.JSON(resp.OkWithData(item))
+		return
+	}
+
+	item, err := spec.live(ctx.Request().Context(), c, namespace, name)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(resp.OkWithData(item))
+}
+
+// respondError apiserver 返回 NotFound 时响应 404，其余为 500
+func respondError(ctx iris.Context, err error) {
+	if apierrors.IsNotFound(err) {
+		notFound(ctx, err.Error())
+		return
+	}
+	ctx.JSON(resp.ErrorWithMsg(err.Error()))
+}
+
+func notFound(ctx iris.Context, msg string) {
+	ctx.StatusCode(iris.StatusNotFound)
+	ctx.JSON(resp.NotFound(msg))
+}
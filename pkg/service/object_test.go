@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestGetObject(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "web",
+			Namespace:     "default",
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+	}
+	client := fake.NewSimpleClientset(svc)
+	dyn := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, []runtime.Object{svc.DeepCopy()}...)
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{})
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {ClusterInfo: ClusterInfo{ID: "test"}, ClientSet: client, Dynamic: dyn, Engine: engine}},
+		order:    []string{"test"},
+	}
+	k := NewK8sService(reg)
+	app := iris.New()
+	app.Get("/namespaces/{namespace}/services/{name}", k.GetService)
+	e := httptest.New(t, app)
+
+	// 缓存未就绪时直接查询 apiserver
+	e.GET("/namespaces/default/services/web").Expect().Status(httptest.StatusOK).
+		JSON().Object().Value("data").Object().Value("name").IsEqual("web")
+	e.GET("/namespaces/default/services/missing").Expect().Status(httptest.StatusNotFound).
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_NOT_FOUND)
+
+	raw := e.GET("/namespaces/default/services/web").WithQuery("view", ViewRaw).Expect().Status(httptest.StatusOK).
+		JSON().Object().Value("data").Object()
+	raw.Value("kind").IsEqual("Service")
+	raw.Value("metadata").Object().NotContainsKey("managedFields")
+	e.GET("/namespaces/default/services/web").WithQuery("view", "yaml").Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+	e.GET("/namespaces/default/services/web").Expect().Status(httptest.StatusOK).
+		JSON().Object().Value("data").Object().Value("name").IsEqual("web")
+	e.GET("/namespaces/other/services/web").Expect().Status(httptest.StatusNotFound)
+}
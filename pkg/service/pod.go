@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podOwnerIndex pod 按 namespace/controller 名称建立的索引，ReplicaSet 变化时据此刷新 pod 的 owner
+const podOwnerIndex = "owner"
+
+// GetPods
+// @Summary 获取集群pod信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器，支持 spec.nodeName、status.phase、status.podIP"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/pods [get]
+// @Router /api/v1/clusters/{cluster}/k8s/pods [get]
+func (k *K8sService) GetPods(ctx iris.Context) {
+	listKind(k, ctx, KindPod, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.PodVo, metav1.ListMeta, error) {
+		podList, err := c.ClientSet.CoreV1().Pods(q.Namespace).List(ctx, q.ListOptions(podServerFields...))
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.PodVo
+		for i := range podList.Items {
+			results = append(results, c.Engine.toPodVo(&podList.Items[i]))
+		}
+		return results, podList.ListMeta, nil
+	}, podItem)
+}
+
+// podServerFields apiserver 支持的 pod fieldSelector 字段
+var podServerFields = []string{"spec.nodeName", "status.phase", "status.podIP"}
+
+func podItem(p vo.PodVo) req.ListItem {
+	return req.ListItem{
+		ID:         p.PodID,
+		CreateTime: p.CreateTime,
+		Name:       p.Name,
+		Namespace:  p.Namespace,
+		Labels:     p.Labels,
+		Fields:     fields.Set{"spec.nodeName": p.NodeName, "status.phase": p.Phase, "status.podIP": p.PodIP},
+	}
+}
+
+// watchPods 同步 pod，并通过只保留元数据的 ReplicaSet 缓存将 owner 解析到 Deployment
+func (e *SyncEngine) watchPods() {
+	rsInformer := e.factory.Apps().V1().ReplicaSets().Informer()
+	if err := rsInformer.SetTransform(replicaSetMetaOnly); err != nil {
+		logrus.Errorf("Failed to set replicaset transform: %v", err)
+	}
+	e.replicaSets = rsInformer.GetIndexer()
+	e.informers = append(e.informers, rsInformer)
+
+	podInformer := e.factory.Core().V1().Pods().Informer()
+	if err := podInformer.AddIndexers(cache.Indexers{podOwnerIndex: indexPodByOwner}); err != nil {
+		logrus.Errorf("Failed to add pod indexer: %v", err)
+	}
+	e.watch(KindPod, podInformer, func(obj interface{}) interface{} {
+		return e.toPodVo(obj.(*corev1.Pod))
+	})
+
+	// pod 可能先于所属 ReplicaSet 进入缓存，ReplicaSet 变化时重新计算其下 pod 的 owner
+	refresh := func(obj interface{}) {
+		rs, ok := obj.(*appsv1.ReplicaSet)
+		if !ok {
+			return
+		}
+		pods, err := podInformer.GetIndexer().ByIndex(podOwnerIndex, rs.Namespace+"/"+rs.Name)
+		if err != nil {
+			return
+		}
+		for _, pod := range pods {
+			e.refreshItem(KindPod, pod, func(obj interface{}) interface{} {
+				return e.toPodVo(obj.(*corev1.Pod))
+			})
+		}
+	}
+	_, err := rsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    refresh,
+		UpdateFunc: func(_, obj interface{}) { refresh(obj) },
+	})
+	if err != nil {
+		logrus.Errorf("Failed to add replicaset event handler: %v", err)
+	}
+}
+
+func (e *SyncEngine) toPodVo(pod *corev1.Pod) vo.PodVo {
+	p := vo.PodVo{
+		PodID:       fmt.Sprintf("%s/%s/Pod/%s", e.Cluster.ID, pod.Namespace, pod.Name),
+		Name:        pod.Name,
+		Namespace:   pod.Namespace,
+		ClusterID:   e.Cluster.ID,
+		ClusterName: e.Cluster.Name,
+		NamespaceID: fmt.Sprintf("%s/%s", e.Cluster.ID, pod.Namespace),
+		Phase:       string(pod.Status.Phase),
+		PodIP:       pod.Status.PodIP,
+		HostIP:      pod.Status.HostIP,
+		NodeName:    pod.Spec.NodeName,
+		QOSClass:    string(pod.Status.QOSClass),
+		Labels:      pod.Labels,
+		CreateTime:  pod.CreationTimestamp.Local().Format(timeLayout),
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			p.Ready = cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	p.Containers = append(containerStatuses(pod.Spec.InitContainers, pod.Status.InitContainerStatuses, true),
+		containerStatuses(pod.Spec.Containers, pod.Status.ContainerStatuses, false)...)
+	for _, c := range p.Containers {
+		p.RestartCount += c.RestartCount
+	}
+
+	if kind, name := e.resolveOwner(pod.Namespace, &pod.ObjectMeta); kind != "" {
+		p.Owner = kind + "/" + name
+		p.WorkloadID = fmt.Sprintf("%s/%s/%s/%s", e.Cluster.ID, pod.Namespace, kind, name)
+	}
+	return p
+}
+
+// resolveOwner 返回对象的控制器，ReplicaSet 继续向上解析一层（通常是 Deployment）
+func (e *SyncEngine) resolveOwner(namespace string, meta *metav1.ObjectMeta) (string, string) {
+	ref := metav1.GetControllerOfNoCopy(meta)
+	if ref == nil {
+		return "", ""
+	}
+	if ref.Kind != "ReplicaSet" || e.replicaSets == nil {
+		return ref.Kind, ref.Name
+	}
+
+	obj, exists, err := e.replicaSets.GetByKey(namespace + "/" + ref.Name)
+	if err != nil || !exists {
+		return ref.Kind, ref.Name
+	}
+	rs := obj.(*appsv1.ReplicaSet)
+	if parent := metav1.GetControllerOfNoCopy(rs); parent != nil {
+		return parent.Kind, parent.Name
+	}
+	return ref.Kind, ref.Name
+}
+
+// containerStatuses 按容器名合并 spec 和 status
+func containerStatuses(containers []corev1.Container, statuses []corev1.ContainerStatus, init bool) []vo.ContainerStatusVo {
+	byName := make(map[string]corev1.ContainerStatus, len(statuses))
+	for _, status := range statuses {
+		byName[status.Name] = status
+	}
+
+	results := make([]vo.ContainerStatusVo, 0, len(containers))
+	for _, c := range containers {
+		result := vo.ContainerStatusVo{Name: c.Name, Image: c.Image, Init: init}
+		if status, ok := byName[c.Name]; ok {
+			result.Ready = status.Ready
+			result.RestartCount = status.RestartCount
+			switch {
+			case status.State.Running != nil:
+				result.State = "running"
+			case status.State.Waiting != nil:
+				result.State = "waiting"
+				result.Reason = status.State.Waiting.Reason
+			case status.State.Terminated != nil:
+				result.State = "terminated"
+				result.Reason = status.State.Terminated.Reason
+			}
+			if last := status.LastTerminationState.Terminated; last != nil {
+				result.LastTerminationReason = last.Reason
+				result.LastTerminationCode = last.ExitCode
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func indexPodByOwner(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	if ref := metav1.GetControllerOfNoCopy(pod); ref != nil {
+		return []string{pod.Namespace + "/" + ref.Name}, nil
+	}
+	return nil, nil
+}
+
+// replicaSetMetaOnly ReplicaSet 只用于解析 owner，缓存中只保留元数据
+func replicaSetMetaOnly(obj interface{}) (interface{}, error) {
+	rs, ok := obj.(*appsv1.ReplicaSet)
+	if !ok {
+		return obj, nil
+	}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            rs.Name,
+			Namespace:       rs.Namespace,
+			UID:             rs.UID,
+			ResourceVersion: rs.ResourceVersion,
+			OwnerReferences: rs.OwnerReferences,
+		},
+	}, nil
+}
+
+// GetPod
+// @Summary 获取单个pod
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/pods/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/pods/{name} [get]
+func (k *K8sService) GetPod(ctx iris.Context) {
+	k.getObject(ctx, KindPod)
+}
+
+func livePod(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	pod, err := c.ClientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return c.Engine.toPodVo(pod), nil
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// AuditExec exec 会话的审计动作
+const AuditExec = "exec"
+
+const (
+	defaultExecIdleTimeout = 10 * time.Minute
+	execReadLimit          = 1 << 20 // 单条客户端消息的最大长度
+	// defaultContainerAnnotation 与 kubectl 一致，未指定容器时使用的容器
+	defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+)
+
+var errExecClientClosed = errors.New("connection closed by client")
+
+// ExecOptions exec 接口配置
+type ExecOptions struct {
+	IdleTimeout time.Duration // 没有输入和输出超过该时间时关闭会话
+	Command     []string      // 请求未指定 command 时执行的命令
+}
+
+func LoadExecOptions() ExecOptions {
+	options := ExecOptions{IdleTimeout: sysinit.UDuration("exec.idleTimeout", defaultExecIdleTimeout)}
+	for _, arg := range sysinit.GCF.UList("exec.command") {
+		if value, ok := arg.(string); ok && value != "" {
+			options.Command = append(options.Command, value)
+		}
+	}
+	if len(options.Command) == 0 {
+		options.Command = []string{"/bin/sh"}
+	}
+	return options
+}
+
+// ExecPod
+// @Summary 在pod中执行命令
+// @Description 升级为 WebSocket 后通过 apiserver 的 exec 子资源建立会话，消息为 vo.ExecMessageVo 格式的 JSON 文本帧。
+// @Description 客户端发送 stdin 和 resize，服务端推送 stdout、stderr，命令退出时推送 exit，异常结束（包括空闲超时）时推送 error。
+// @Description 会话结束后写入审计记录，用户取自 audit.userHeader 请求头。
+// @Tags　k8s
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "pod名称"
+// @Param container query string false "容器名称，默认为 kubectl.kubernetes.io/default-container 注解指定的容器或第一个容器"
+// @Param command query []string false "命令及参数，可重复，默认为 exec.command" collectionFormat(multi)
+// @Param tty query bool false "是否分配终端，默认 true；为 false 时 stderr 单独推送"
+// @Success 101 {object} vo.ExecMessageVo
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/pods/{name}/exec [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/pods/{name}/exec [get]
+func (k *K8sService) ExecPod(ctx iris.Context) {
+	if !websocket.IsWebSocketUpgrade(ctx.Request()) {
+		ctx.JSON(resp.BadResponse("websocket upgrade is required"))
+		return
+	}
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+	pod, err := c.ClientSet.CoreV1().Pods(namespace).Get(ctx.Request().Context(), name, metav1.GetOptions{})
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	opts, err := k.parseExecOptions(ctx, pod)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	executor, err := newExecutor(c, namespace, name, opts)
+	if err != nil {
+		ctx.JSON(resp.ErrorWithMsg(err.Error()))
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: originChecker(k.Watch.AllowedOrigins)}
+	conn, err := upgrader.Upgrade(ctx.ResponseWriter(), ctx.Request(), nil)
+	if err != nil {
+		logrus.Warnf("Failed to upgrade exec session: %v", err)
+		return
+	}
+
+	record := k.Registry.audit.Begin(ctx, c.ID, AuditExec, "Pod", namespace, name)
+	auditDetail(record, map[string]interface{}{"container": opts.Container, "command": opts.Command, "tty": opts.TTY})
+	logrus.Infof("Exec session started by %s: %s/%s/%s %v", record.User, c.ID, namespace, name, opts.Command)
+
+	// 客户端断开、空闲超时或进程退出时结束会话
+	execCtx, cancel := context.WithCancel(ctx.Request().Context())
+	defer cancel()
+	if parent := k.Registry.ctx; parent != nil {
+		defer context.AfterFunc(parent, cancel)()
+	}
+	s := newExecSession(execCtx, cancel, conn)
+	go s.readLoop()
+	go s.watchIdle(k.Exec.IdleTimeout)
+
+	streamOpts := remotecommand.StreamOptions{Stdin: s.stdin, Stdout: s.output(vo.ExecStdout), Tty: opts.TTY}
+	if opts.TTY {
+		streamOpts.TerminalSizeQueue = s
+	} else {
+		streamOpts.Stderr = s.output(vo.ExecStderr)
+	}
+	err = s.finish(executor.StreamWithContext(execCtx, streamOpts))
+	k.Registry.audit.End(record, err)
+}
+
+// parseExecOptions 解析 container、command、tty 参数
+func (k *K8sService) parseExecOptions(ctx iris.Context, pod *corev1.Pod) (*corev1.PodExecOptions, error) {
+	container, err := execContainer(pod, ctx.URLParam("container"))
+	if err != nil {
+		return nil, err
+	}
+	opts := &corev1.PodExecOptions{
+		Container: container,
+		Command:   ctx.Request().URL.Query()["command"],
+		Stdin:     true,
+		Stdout:    true,
+		TTY:       true,
+	}
+	if len(opts.Command) == 0 {
+		opts.Command = k.Exec.Command
+	}
+	if tty := ctx.URLParam("tty"); tty != "" {
+		if opts.TTY, err = strconv.ParseBool(tty); err != nil {
+			return nil, fmt.Errorf("invalid tty %q", tty)
+		}
+	}
+	// 分配终端时 stderr 合并到 stdout
+	opts.Stderr = !opts.TTY
+	return opts, nil
+}
+
+// execContainer 未指定容器时与 kubectl 一致，优先使用 default-container 注解
+func execContainer(pod *corev1.Pod, name string) (string, error) {
+	if name == "" {
+		name = pod.Annotations[defaultContainerAnnotation]
+	}
+	if name == "" && len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name, nil
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return name, nil
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("container %s not found in pod %s", name, pod.Name)
+}
+
+// newExecutor 优先使用 WebSocket 协议，apiserver 不支持时回退到 SPDY
+func newExecutor(c *Cluster, namespace, name string, opts *corev1.PodExecOptions) (remotecommand.Executor, error) {
+	url := c.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(name).SubResource("exec").
+		VersionedParams(opts, scheme.ParameterCodec).URL()
+	spdyExec, err := remotecommand.NewSPDYExecutor(c.Config, "POST", url)
+	if err != nil {
+		return nil, err
+	}
+	wsExec, err := remotecommand.NewWebSocketExecutor(c.Config, "GET", url.String())
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(wsExec, spdyExec, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
+
+// execSession 在客户端 WebSocket 与 apiserver 的 exec 流之间转发数据
+type execSession struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	conn   *websocket.Conn
+
+	writeMu sync.Mutex // 保护 conn 的写入，stdout 和 stderr 可能并发写
+	stdin   *io.PipeReader
+	stdinW  *io.PipeWriter
+	sizes   chan remotecommand.TerminalSize
+	active  atomic.Int64 // 最近一次输入或输出的时间（UnixNano）
+
+	stopOnce sync.Once
+	reason   error // 会话被客户端或空闲超时结束的原因
+}
+
+func newExecSession(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) *execSession {
+	stdin, stdinW := io.Pipe()
+	s := &execSession{
+		ctx:    ctx,
+		cancel: cancel,
+		conn:   conn,
+		stdin:  stdin,
+		stdinW: stdinW,
+		sizes:  make(chan remotecommand.TerminalSize, 1),
+	}
+	s.touch()
+	conn.SetReadLimit(execReadLimit)
+	return s
+}
+
+func (s *execSession) touch() {
+	s.active.Store(time.Now().UnixNano())
+}
+
+// stop 记录结束原因并取消会话，只有第一次调用生效
+func (s *execSession) stop(reason error) {
+	s.stopOnce.Do(func() {
+		s.reason = reason
+		s.stdinW.CloseWithError(reason)
+		s.cancel()
+	})
+}
+
+// readLoop 读取客户端的 stdin 和 resize 消息，连接断开时结束会话
+func (s *execSession) readLoop() {
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.stop(errExecClientClosed)
+			return
+		}
+		var msg vo.ExecMessageVo
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logrus.Warnf("Invalid exec message: %v", err)
+			continue
+		}
+		s.touch()
+		switch msg.Op {
+		case vo.ExecStdin:
+			if _, err := io.WriteString(s.stdinW, msg.Data); err != nil {
+				return
+			}
+		case vo.ExecResize:
+			if msg.Cols > 0 && msg.Rows > 0 {
+				s.resize(remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows})
+			}
+		}
+	}
+}
+
+// resize 只保留最新的终端大小
+func (s *execSession) resize(size remotecommand.TerminalSize) {
+	for {
+		select {
+		case s.sizes <- size:
+			return
+		default:
+			select {
+			case <-s.sizes:
+			default:
+			}
+		}
+	}
+}
+
+// Next 实现 remotecommand.TerminalSizeQueue，会话结束时返回 nil
+func (s *execSession) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-s.sizes:
+		return &size
+	case <-s.ctx.Done():
+		return nil
+	}
+}
+
+// watchIdle 没有输入和输出超过 timeout 时结束会话
+func (s *execSession) watchIdle(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	interval := timeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if time.Since(time.Unix(0, s.active.Load())) >= timeout {
+				s.stop(fmt.Errorf("idle timeout after %s", timeout))
+				return
+			}
+		}
+	}
+}
+
+func (s *execSession) send(msg vo.ExecMessageVo) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout)); err != nil {
+		return err
+	}
+	return s.conn.WriteJSON(msg)
+}
+
+// finish 向客户端推送结束消息并关闭连接，返回写入审计的结果
+func (s *execSession) finish(err error) error {
+	s.stop(nil)
+	if s.reason != nil {
+		err = s.reason
+	}
+	var exitErr utilexec.ExitError
+	switch {
+	case err == nil:
+		code := 0
+		s.send(vo.ExecMessageVo{Op: vo.ExecExit, Code: &code})
+	case errors.As(err, &exitErr):
+		code := exitErr.ExitStatus()
+		s.send(vo.ExecMessageVo{Op: vo.ExecExit, Code: &code})
+	case err != errExecClientClosed:
+		s.send(vo.ExecMessageVo{Op: vo.ExecError, Data: err.Error()})
+	}
+	closeWebSocket(s.conn)
+	return err
+}
+
+// output 返回 stdout/stderr 的 writer
+func (s *execSession) output(op string) io.Writer {
+	return &execOutput{session: s, op: op}
+}
+
+// execOutput 将输出转为消息，跨两次写入的 UTF-8 字符留到下次一起发送
+type execOutput struct {
+	session *execSession
+	op      string
+	pending []byte
+}
+
+func (w *execOutput) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	n := completeUTF8(data)
+	w.pending = append([]byte(nil), data[n:]...)
+	if n > 0 {
+		if err := w.session.send(vo.ExecMessageVo{Op: w.op, Data: string(data[:n])}); err != nil {
+			return 0, err
+		}
+	}
+	w.session.touch()
+	return len(p), nil
+}
+
+// completeUTF8 返回去掉末尾不完整 UTF-8 字符后的长度
+func completeUTF8(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
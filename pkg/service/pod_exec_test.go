@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func TestExecContainer(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.PodSpec{
+			Containers:          []corev1.Container{{Name: "istio-proxy"}, {Name: "app"}},
+			EphemeralContainers: []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger"}}},
+		},
+	}
+	tests := []struct {
+		name       string
+		annotation string
+		want       string
+		wantErr    bool
+	}{
+		{"", "", "istio-proxy", false},
+		{"", "app", "app", false},
+		{"debugger", "app", "debugger", false},
+		{"missing", "", "", true},
+	}
+	for _, tt := range tests {
+		pod.Annotations = map[string]string{defaultContainerAnnotation: tt.annotation}
+		got, err := execContainer(pod, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("execContainer(%q) with annotation %q = %q, %v", tt.name, tt.annotation, got, err)
+		}
+	}
+}
+
+func TestCompleteUTF8(t *testing.T) {
+	b := []byte("终端")
+	for i := 0; i <= len(b); i++ {
+		want := i / 3 * 3
+		if got := completeUTF8(b[:i]); got != want {
+			t.Errorf("completeUTF8(%d bytes) = %d, want %d", i, got, want)
+		}
+	}
+}
+
+// TestExecSession 用回显 stdin 的流代替 apiserver，验证消息转发、resize 和退出码
+func TestExecSession(t *testing.T) {
+	sizes := make(chan uint16, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		s := newExecSession(ctx, cancel, conn)
+		go s.readLoop()
+
+		stdout := s.output(vo.ExecStdout)
+		line, _ := bufio.NewReader(s.stdin).ReadString('\n')
+		// resize 先于 stdin 发送，读到 stdin 时已在队列中
+		if size := s.Next(); size != nil {
+			sizes <- size.Width
+		}
+		stdout.Write([]byte(line[:2])) // 拆开多字节字符
+		stdout.Write([]byte(line[2:]))
+		s.finish(utilexec.CodeExitError{Err: context.Canceled, Code: 3})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	conn.WriteJSON(vo.ExecMessageVo{Op: vo.ExecResize, Cols: 120, Rows: 40})
+	conn.WriteJSON(vo.ExecMessageVo{Op: vo.ExecStdin, Data: "终端\n"})
+
+	var output string
+	for {
+		var msg vo.ExecMessageVo
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v, output %q", err, output)
+		}
+		if msg.Op == vo.ExecStdout {
+			output += msg.Data
+			continue
+		}
+		if msg.Op != vo.ExecExit || msg.Code == nil || *msg.Code != 3 {
+			t.Errorf("last message = %+v, want exit 3", msg)
+		}
+		break
+	}
+	if output != "终端\n" {
+		t.Errorf("output = %q", output)
+	}
+	if width := <-sizes; width != 120 {
+		t.Errorf("terminal width = %d, want 120", width)
+	}
+}
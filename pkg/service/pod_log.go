@@ -0,0 +1,362 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// logLineBuffer 多个日志流合并时等待写出的行数
+const logLineBuffer = 256
+
+// LogOptions 日志接口配置
+type LogOptions struct {
+	MaxPods int // 按标签选择器聚合日志时最多匹配的 pod 数量
+}
+
+func LoadLogOptions() LogOptions {
+	return LogOptions{MaxPods: sysinit.GCF.UInt("logs.maxPods", 20)}
+}
+
+// logTarget 一个容器的日志流，prefix 为聚合输出时每行的前缀
+type logTarget struct {
+	pod       string
+	container string
+	prefix    string
+}
+
+// GetPodLogs
+// @Summary 获取pod日志
+// @Description 返回 text/plain 格式的日志，未指定 container 且 pod 有多个容器时输出所有容器，每行带 [pod/container] 前缀。
+// @Description follow=true 时持续推送，请求带 WebSocket 升级头时每行为一个文本帧，否则为 chunked 响应。
+// @Tags　k8s
+// @Produce text/plain
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "pod名称"
+// @Param container query string false "容器名称"
+// @Param tailLines query int false "只返回最后的行数"
+// @Param sinceSeconds query int false "只返回最近若干秒的日志"
+// @Param previous query bool false "返回上一次终止的容器的日志"
+// @Param timestamps query bool false "每行带上时间戳"
+// @Param follow query bool false "持续推送新日志"
+// @Success 200 {string} string
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/pods/{name}/logs [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/pods/{name}/logs [get]
+func (k *K8sService) GetPodLogs(ctx iris.Context) {
+	k.podLogs(ctx)
+}
+
+// GetNamespaceLogs
+// @Summary 聚合多个pod的日志
+// @Description 按 labelSelector 聚合命名空间下多个 pod 的日志，每行带 [pod/container] 前缀；指定 container 时跳过没有该容器的 pod。
+// @Description follow=true 时并发读取并按到达顺序交错输出，请求带 WebSocket 升级头时每行为一个文本帧，否则为 chunked 响应。
+// @Tags　k8s
+// @Produce text/plain
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param labelSelector query string true "标签选择器"
+// @Param container query string false "容器名称"
+// @Param tailLines query int false "每个容器只返回最后的行数"
+// @Param sinceSeconds query int false "只返回最近若干秒的日志"
+// @Param previous query bool false "返回上一次终止的容器的日志"
+// @Param timestamps query bool false "每行带上时间戳"
+// @Param follow query bool false "持续推送新日志"
+// @Success 200 {string} string
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/logs [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/logs [get]
+func (k *K8sService) GetNamespaceLogs(ctx iris.Context) {
+	k.podLogs(ctx)
+}
+
+// podLogs 路径中有 pod 名称时输出单个 pod 的日志，否则按 labelSelector 聚合
+func (k *K8sService) podLogs(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	opts, err := parsePodLogOptions(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+
+	var pods []corev1.Pod
+	if name != "" {
+		pod, err := c.ClientSet.CoreV1().Pods(namespace).Get(ctx.Request().Context(), name, metav1.GetOptions{})
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		pods = []corev1.Pod{*pod}
+	} else {
+		selector := ctx.URLParam("labelSelector")
+		if selector == "" {
+			ctx.JSON(resp.BadResponse("labelSelector is required when pod name is not specified"))
+			return
+		}
+		if _, err := labels.Parse(selector); err != nil {
+			ctx.JSON(resp.BadResponse(fmt.Sprintf("invalid labelSelector: %v", err)))
+			return
+		}
+		list, err := c.ClientSet.CoreV1().Pods(namespace).List(ctx.Request().Context(), metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			ctx.JSON(resp.ErrorWithMsg(err.Error()))
+			return
+		}
+		if len(list.Items) == 0 {
+			notFound(ctx, fmt.Sprintf("no pods match %s in namespace %s", selector, namespace))
+			return
+		}
+		if maxPods := k.Logs.MaxPods; maxPods > 0 && len(list.Items) > maxPods {
+			ctx.JSON(resp.BadResponse(fmt.Sprintf("%d pods match %s, more than logs.maxPods %d", len(list.Items), selector, maxPods)))
+			return
+		}
+		pods = list.Items
+	}
+
+	container := ctx.URLParam("container")
+	targets := logTargets(pods, container, name == "")
+	if len(targets) == 0 {
+		notFound(ctx, fmt.Sprintf("container %s not found", container))
+		return
+	}
+
+	// 请求结束、客户端断开或进程退出时停止
+	logCtx, cancel := context.WithCancel(ctx.Request().Context())
+	defer cancel()
+	if parent := k.Registry.ctx; parent != nil {
+		defer context.AfterFunc(parent, cancel)()
+	}
+
+	// 先打开所有日志流，单个容器的错误（例如 previous 不存在）以 JSON 响应返回
+	streams := make([]io.ReadCloser, len(targets))
+	for i, target := range targets {
+		podOpts := opts
+		podOpts.Container = target.container
+		stream, err := c.ClientSet.CoreV1().Pods(namespace).GetLogs(target.pod, &podOpts).Stream(logCtx)
+		if err != nil && len(targets) == 1 {
+			respondError(ctx, err)
+			return
+		}
+		if err != nil {
+			logrus.Warnf("Failed to get logs of %s/%s/%s: %v", namespace, target.pod, target.container, err)
+			// 聚合日志时无法获取的容器输出一行错误
+			streams[i] = io.NopCloser(strings.NewReader(fmt.Sprintf("failed to get logs: %v\n", err)))
+			continue
+		}
+		streams[i] = stream
+	}
+
+	w, err := openLogWriter(ctx, cancel, opts.Follow, k.Watch.AllowedOrigins)
+	if err != nil {
+		logrus.Warnf("Failed to open log stream: %v", err)
+		for _, stream := range streams {
+			stream.Close()
+		}
+		return
+	}
+	defer w.Close()
+	copyLogs(logCtx, cancel, w, targets, streams, opts.Follow)
+}
+
+// parsePodLogOptions 解析 tailLines、sinceSeconds、previous、timestamps、follow 参数
+func parsePodLogOptions(ctx iris.Context) (corev1.PodLogOptions, error) {
+	var opts corev1.PodLogOptions
+	for param, value := range map[string]**int64{"tailLines": &opts.TailLines, "sinceSeconds": &opts.SinceSeconds} {
+		if s := ctx.URLParam(param); s != "" {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || n < 0 || (param == "sinceSeconds" && n == 0) {
+				return opts, fmt.Errorf("invalid %s %q", param, s)
+			}
+			*value = &n
+		}
+	}
+	for param, value := range map[string]*bool{"previous": &opts.Previous, "timestamps": &opts.Timestamps, "follow": &opts.Follow} {
+		if s := ctx.URLParam(param); s != "" {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s %q", param, s)
+			}
+			*value = b
+		}
+	}
+	if opts.Previous && opts.Follow {
+		return opts, fmt.Errorf("previous and follow cannot be used together")
+	}
+	return opts, nil
+}
+
+// logTargets 指定 container 时只输出该容器，跳过没有该容器的 pod；否则输出所有容器
+// 聚合多个 pod 或多个容器时每行带上 [pod/container] 前缀
+func logTargets(pods []corev1.Pod, container string, aggregate bool) []logTarget {
+	var targets []logTarget
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			if container == "" || c.Name == container {
+				targets = append(targets, logTarget{pod: pod.Name, container: c.Name})
+			}
+		}
+		// 只有 init 容器或 sidecar 以 init 容器形式运行时需要显式指定
+		if container != "" {
+			for _, c := range pod.Spec.InitContainers {
+				if c.Name == container {
+					targets = append(targets, logTarget{pod: pod.Name, container: c.Name})
+				}
+			}
+		}
+	}
+	if aggregate || len(targets) > 1 {
+		for i := range targets {
+			targets[i].prefix = fmt.Sprintf("[%s/%s] ", targets[i].pod, targets[i].container)
+		}
+	}
+	return targets
+}
+
+// copyLogs 按行写出日志；follow 时并发读取各个日志流并按到达顺序交错输出，否则依次输出每个日志流
+func copyLogs(ctx context.Context, cancel context.CancelFunc, w logWriter, targets []logTarget, streams []io.ReadCloser, follow bool) {
+	lines := make(chan string, logLineBuffer)
+	read := func(target logTarget, stream io.ReadCloser) {
+		defer stream.Close()
+		reader := bufio.NewReader(stream)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				if !strings.HasSuffix(line, "\n") {
+					line += "\n"
+				}
+				select {
+				case lines <- target.prefix + line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+					logrus.Warnf("Failed to read logs of %s/%s: %v", target.pod, target.container, err)
+				}
+				return
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	if follow {
+		for i := range targets {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				read(targets[i], streams[i])
+			}(i)
+		}
+	} else {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range targets {
+				read(targets[i], streams[i])
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	for line := range lines {
+		if err := w.WriteLine(line); err != nil {
+			// 客户端已断开，取消后读取协程随之退出
+			cancel()
+			return
+		}
+	}
+}
+
+// logWriter 向客户端写出日志行，只在一个 goroutine 中使用
+type logWriter interface {
+	WriteLine(line string) error
+	Close()
+}
+
+// openLogWriter follow 且请求带 WebSocket 升级头时使用 WebSocket，否则使用 text/plain 响应
+func openLogWriter(ctx iris.Context, cancel context.CancelFunc, follow bool, allowedOrigins []string) (logWriter, error) {
+	if follow && websocket.IsWebSocketUpgrade(ctx.Request()) {
+		conn, err := upgradeWebSocket(ctx, cancel, allowedOrigins)
+		if err != nil {
+			return nil, err
+		}
+		return &webSocketLogWriter{conn: conn}, nil
+	}
+	w := ctx.ResponseWriter()
+	header := w.Header()
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	lw := &httpLogWriter{w: w}
+	if follow {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return nil, errors.New("response writer does not support streaming")
+		}
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no") // 关闭 nginx 的响应缓冲
+		lw.flusher = flusher
+	}
+	w.WriteHeader(http.StatusOK)
+	return lw, nil
+}
+
+// httpLogWriter follow 时每行写出后立即 flush
+type httpLogWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (h *httpLogWriter) WriteLine(line string) error {
+	if _, err := io.WriteString(h.w, line); err != nil {
+		return err
+	}
+	if h.flusher != nil {
+		h.flusher.Flush()
+	}
+	return nil
+}
+
+func (h *httpLogWriter) Close() {}
+
+// webSocketLogWriter 每行日志为一个文本帧，包含结尾的换行符
+type webSocketLogWriter struct {
+	conn *websocket.Conn
+}
+
+func (w *webSocketLogWriter) WriteLine(line string) error {
+	if err := w.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout)); err != nil {
+		return err
+	}
+	return w.conn.WriteMessage(websocket.TextMessage, []byte(line))
+}
+
+func (w *webSocketLogWriter) Close() {
+	closeWebSocket(w.conn)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetPodLogs(t *testing.T) {
+	pod := func(name string, containers ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}}}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+		}
+		return p
+	}
+	// fake clientset 的日志内容固定为 "fake logs"
+	client := fake.NewSimpleClientset(pod("web-1", "app"), pod("web-2", "app", "sidecar"))
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {ClusterInfo: ClusterInfo{ID: "test"}, ClientSet: client}},
+		order:    []string{"test"},
+	}
+	k := &K8sService{Registry: reg, Logs: LogOptions{MaxPods: 20}}
+	app := iris.New()
+	app.Get("/namespaces/{namespace}/pods/{name}/logs", k.GetPodLogs)
+	app.Get("/namespaces/{namespace}/logs", k.GetNamespaceLogs)
+	e := httptest.New(t, app)
+
+	e.GET("/namespaces/default/pods/web-1/logs").WithQuery("tailLines", 10).Expect().Status(httptest.StatusOK).
+		ContentType("text/plain").Body().IsEqual("fake logs\n")
+	e.GET("/namespaces/default/pods/web-2/logs").Expect().Status(httptest.StatusOK).
+		Body().IsEqual("[web-2/app] fake logs\n[web-2/sidecar] fake logs\n")
+	e.GET("/namespaces/default/pods/web-2/logs").WithQuery("container", "sidecar").Expect().Status(httptest.StatusOK).
+		Body().IsEqual("fake logs\n")
+	e.GET("/namespaces/default/pods/web-2/logs").WithQuery("container", "missing").Expect().Status(httptest.StatusNotFound)
+	e.GET("/namespaces/default/pods/missing/logs").Expect().Status(httptest.StatusNotFound)
+
+	// 按标签选择器聚合，跳过没有指定容器的 pod
+	body := e.GET("/namespaces/default/logs").WithQuery("labelSelector", "app=web").WithQuery("container", "app").
+		Expect().Status(httptest.StatusOK).Body()
+	body.Contains("[web-1/app] fake logs\n")
+	body.Contains("[web-2/app] fake logs\n")
+	body.NotContains("sidecar")
+	// follow 时并发读取，行按到达顺序交错
+	body = e.GET("/namespaces/default/logs").WithQuery("labelSelector", "app=web").WithQuery("follow", true).
+		Expect().Status(httptest.StatusOK).Body()
+	body.Contains("[web-2/sidecar] fake logs\n")
+	body.Length().IsEqual(len("[web-1/app] fake logs\n[web-2/app] fake logs\n[web-2/sidecar] fake logs\n"))
+
+	for name, query := range map[string]map[string]interface{}{
+		"no selector":       {},
+		"invalid tailLines": {"labelSelector": "app=web", "tailLines": "-1"},
+		"zero sinceSeconds": {"labelSelector": "app=web", "sinceSeconds": "0"},
+		"previous follow":   {"labelSelector": "app=web", "previous": true, "follow": true},
+	} {
+		code := e.GET("/namespaces/default/logs").WithQueryObject(query).Expect().JSON().Object().Value("code")
+		if code.Raw() != float64(resp.MESSAGE_BAD) {
+			t.Errorf("%s: code = %v, want %d", name, code.Raw(), resp.MESSAGE_BAD)
+		}
+	}
+
+	k.Logs.MaxPods = 1
+	e.GET("/namespaces/default/logs").WithQuery("labelSelector", "app=web").Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodOwnerResolution(t *testing.T) {
+	isController := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-5d9c7b-abcde",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "web-5d9c7b", Controller: &isController},
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:   "node-1",
+			Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:    corev1.PodRunning,
+			PodIP:    "10.0.0.10",
+			QOSClass: corev1.PodQOSBurstable,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "web",
+				Ready:        true,
+				RestartCount: 3,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+				},
+			}},
+		},
+	}
+	client := fake.NewSimpleClientset(pod)
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{Pods: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+
+	pods := engine.ListPods()
+	if len(pods) != 1 {
+		t.Fatalf("ListPods() = %+v", pods)
+	}
+	p := pods[0]
+	if p.PodID != "test/default/Pod/web-5d9c7b-abcde" || !p.Ready || p.NodeName != "node-1" || p.QOSClass != "Burstable" {
+		t.Errorf("pod = %+v", p)
+	}
+	if p.RestartCount != 3 || p.Containers[0].LastTerminationReason != "OOMKilled" || p.Containers[0].State != "running" {
+		t.Errorf("containers = %+v", p.Containers)
+	}
+	// ReplicaSet 尚未同步时 owner 为 ReplicaSet
+	if p.Owner != "ReplicaSet/web-5d9c7b" {
+		t.Errorf("Owner = %q", p.Owner)
+	}
+
+	// ReplicaSet 出现后 owner 解析到 Deployment
+	_, err := client.AppsV1().ReplicaSets("default").Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-5d9c7b",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "web", Controller: &isController},
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return engine.ListPods()[0].Owner == "Deployment/web" })
+	if id := engine.ListPods()[0].WorkloadID; id != "test/default/Deployment/web" {
+		t.Errorf("WorkloadID = %q", id)
+	}
+}
+
+func TestPodRefreshAfterDelete(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-5d9c7b-abcde", Namespace: "default"}}
+	engine := NewSyncEngine(fake.NewSimpleClientset(pod), ClusterInfo{ID: "test"}, SyncOptions{Pods: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+	convert := func(obj interface{}) interface{} {
+		return engine.toPodVo(obj.(*corev1.Pod))
+	}
+
+	// ReplicaSet 变化时读取的快照中 pod 尚未删除，删除事件先于刷新处理
+	engine.remove(KindPod, pod)
+	engine.refreshItem(KindPod, pod, convert)
+	if pods := engine.ListPods(); len(pods) != 0 {
+		t.Errorf("deleted pod written back: %+v", pods)
+	}
+
+	engine.upsert(KindPod, pod, convert)
+	updated := pod.DeepCopy()
+	updated.Spec.NodeName = "node-1"
+	engine.refreshItem(KindPod, updated, convert)
+	if pods := engine.ListPods(); len(pods) != 1 || pods[0].NodeName != "node-1" {
+		t.Errorf("ListPods() = %+v", pods)
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"k8s.io/client-go/rest"
+)
+
+// proxyFlushInterval 代理响应的刷新间隔，SSE 等流式响应会立即刷新
+const proxyFlushInterval = 100 * time.Millisecond
+
+// proxyResources 支持代理的资源
+var proxyResources = map[string]bool{"pods": true, "services": true}
+
+// ProxyOptions 代理接口配置，命名空间和端口都在白名单内才允许代理
+type ProxyOptions struct {
+	Namespaces []string // "*" 表示所有命名空间
+	Ports      []string // 端口号或端口名称，"*" 表示所有端口
+}
+
+func LoadProxyOptions() ProxyOptions {
+	var options ProxyOptions
+	for _, item := range sysinit.GCF.UList("proxy.namespaces") {
+		if value, ok := item.(string); ok && value != "" {
+			options.Namespaces = append(options.Namespaces, value)
+		}
+	}
+	for _, item := range sysinit.GCF.UList("proxy.ports") {
+		// 端口号在 yaml 中为数字
+		if value := fmt.Sprint(item); value != "" {
+			options.Ports = append(options.Ports, value)
+		}
+	}
+	return options
+}
+
+// Allowed 白名单为空时不允许代理
+func (o ProxyOptions) Allowed(namespace, port string) bool {
+	return allowlisted(o.Namespaces, namespace) && allowlisted(o.Ports, port)
+}
+
+func allowlisted(allowed []string, value string) bool {
+	for _, item := range allowed {
+		if item == "*" || item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyTarget apiserver proxy 子资源的目标，格式为 [scheme:]name:port
+type proxyTarget struct {
+	resource string
+	scheme   string
+	name     string
+	port     string
+}
+
+func parseProxyTarget(resource, target string) (proxyTarget, error) {
+	if !proxyResources[resource] {
+		return proxyTarget{}, fmt.Errorf("unsupported resource %s, must be pods or services", resource)
+	}
+	t := proxyTarget{resource: resource}
+	parts := strings.Split(target, ":")
+	switch len(parts) {
+	case 2:
+		t.name, t.port = parts[0], parts[1]
+	case 3:
+		t.scheme, t.name, t.port = parts[0], parts[1], parts[2]
+		if t.scheme != "http" && t.scheme != "https" {
+			return t, fmt.Errorf("invalid scheme %q, must be http or https", t.scheme)
+		}
+	}
+	if t.name == "" || t.port == "" {
+		return t, fmt.Errorf("invalid target %q, must be [scheme:]name:port", target)
+	}
+	return t, nil
+}
+
+// apiName apiserver 路径中的名称
+func (t proxyTarget) apiName() string {
+	if t.scheme != "" {
+		return t.scheme + ":" + t.name + ":" + t.port
+	}
+	return t.name + ":" + t.port
+}
+
+// ProxyRequest
+// @Summary 代理HTTP请求到pod或service
+// @Description 通过 apiserver 的 proxy 子资源转发请求，请求和响应的 body 均为流式转发，支持所有 HTTP 方法。
+// @Description 响应的 Location 头和 HTML 中指向 apiserver 代理路径的链接会改写为本接口的路径。
+// @Description 响应去掉 Set-Cookie 并加上 Content-Security-Policy: sandbox，页面中的脚本不会执行。
+// @Description 命名空间和端口需在 proxy.namespaces、proxy.ports 白名单内，否则返回 403。
+// @Tags　k8s
+// @Param cluster query string false "集群ID，不会转发给后端"
+// @Param namespace path string true "命名空间"
+// @Param resource path string true "pods 或 services"
+// @Param target path string true "[scheme:]name:port，端口可以是端口号或端口名称"
+// @Param path path string true "转发给后端的路径，可以为空"
+// @Success 200 {string} string "后端的响应"
+// @Failure 400 {object} resp.Message
+// @Failure 403 {object} resp.Message
+// @Failure 502 {object} resp.Message
+// @Router /api/v1/k8s/proxy/namespaces/{namespace}/{resource}/{target}/{path} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/proxy/namespaces/{namespace}/{resource}/{target}/{path} [get]
+func (k *K8sService) ProxyRequest(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	namespace := ctx.Params().Get("namespace")
+	target, err := parseProxyTarget(ctx.Params().Get("resource"), ctx.Params().Get("target"))
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	if !k.Proxy.Allowed(namespace, target.port) {
+		ctx.StatusCode(iris.StatusForbidden)
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("proxy to namespace %s port %s is not allowed", namespace, target.port)))
+		return
+	}
+	transport, err := rest.TransportFor(c.Config)
+	if err != nil {
+		ctx.JSON(resp.ErrorWithMsg(err.Error()))
+		return
+	}
+
+	// upstream 例如 https://apiserver/api/v1/namespaces/default/pods/web:8080/proxy
+	upstream := c.ClientSet.CoreV1().RESTClient().Get().
+		Namespace(namespace).Resource(target.resource).Name(target.apiName()).SubResource("proxy").URL()
+	path := ctx.Params().Get("path")
+	suffix := "/" + path
+	if path != "" && hasProxyTrailingSlash(ctx.Request()) {
+		suffix += "/"
+	}
+	// localPrefix 例如 /api/v1/k8s/proxy/namespaces/default/pods/web:8080
+	localPrefix := strings.TrimSuffix(ctx.Request().URL.Path, "/")
+	if path != "" {
+		localPrefix = strings.TrimSuffix(localPrefix, "/"+path)
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.Out.URL.Scheme = upstream.Scheme
+			r.Out.URL.Host = upstream.Host
+			r.Out.URL.Path = upstream.Path + suffix
+			r.Out.URL.RawPath = ""
+			query := r.In.URL.Query()
+			query.Del("cluster")
+			r.Out.URL.RawQuery = query.Encode()
+			r.Out.Host = ""
+			// 使用 kubeapi 的集群凭据访问 apiserver，客户端的 Authorization 会覆盖 token 认证
+			r.Out.Header.Del("Authorization")
+			// 客户端不能借 kubeapi 的凭据以其他身份访问 apiserver，Cookie 属于 kubeapi 的会话也不转发
+			for key := range r.Out.Header {
+				if strings.HasPrefix(key, "Impersonate-") {
+					r.Out.Header.Del(key)
+				}
+			}
+			r.Out.Header.Del("Cookie")
+			// 由 Transport 协商压缩并自动解压，HTML 响应才能改写链接
+			r.Out.Header.Del("Accept-Encoding")
+			r.SetXForwarded()
+		},
+		Transport:      transport,
+		FlushInterval:  proxyFlushInterval,
+		ModifyResponse: rewriteProxyResponse(upstream, localPrefix),
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if r.Context().Err() != nil {
+				return // 客户端已断开
+			}
+			logrus.Warnf("Failed to proxy %s %s: %v", r.Method, r.URL.Path, err)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusBadGateway)
+			json.NewEncoder(w).Encode(resp.ErrorWithMsg(err.Error()))
+		},
+	}
+	proxy.ServeHTTP(ctx.ResponseWriter(), ctx.Request())
+}
+
+// rewriteProxyResponse 将 Location 头和 HTML 中 apiserver 的代理路径改写为本接口的路径
+func rewriteProxyResponse(upstream *url.URL, localPrefix string) func(*http.Response) error {
+	return func(r *http.Response) error {
+		// 代理的页面与 kubeapi 同源，sandbox 使其作为独立的源运行且不执行脚本，
+		// 不能借用访问者的身份调用 exec、apply 等接口；后端的 Cookie 也不能写到 kubeapi 的域名下
+		r.Header.Del("Set-Cookie")
+		r.Header.Add("Content-Security-Policy", "sandbox")
+		if location := r.Header.Get("Location"); location != "" {
+			if u, err := url.Parse(location); err == nil && (u.Host == "" || u.Host == upstream.Host) &&
+				(u.Path == upstream.Path || strings.HasPrefix(u.Path, upstream.Path+"/")) {
+				u.Scheme, u.Host = "", ""
+				u.Path = localPrefix + strings.TrimPrefix(u.Path, upstream.Path)
+				u.RawPath = ""
+				r.Header.Set("Location", u.String())
+			}
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "text/html") && r.Header.Get("Content-Encoding") == "" {
+			r.Body = newReplaceReader(r.Body, []byte(upstream.Path+"/"), []byte(localPrefix+"/"))
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+		}
+		return nil
+	}
+}
+
+// replaceReader 流式替换 body 中的字符串，保留末尾可能是 old 前缀的部分等待下一次读取
+type replaceReader struct {
+	src      io.ReadCloser
+	old, new []byte
+	pending  []byte // 尚未处理的输入
+	out      []byte // 已处理待返回的输出
+	eof      bool
+	chunk    []byte
+}
+
+func newReplaceReader(src io.ReadCloser, old, new []byte) *replaceReader {
+	return &replaceReader{src: src, old: old, new: new, chunk: make([]byte, 32*1024)}
+}
+
+func (r *replaceReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		n, err := r.src.Read(r.chunk)
+		r.pending = append(r.pending, r.chunk[:n]...)
+		if err == io.EOF {
+			r.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+		r.replace()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// replace 替换 pending 中完整的匹配，读到结尾之前保留最后 len(old)-1 个字节
+func (r *replaceReader) replace() {
+	var out []byte
+	i := 0
+	for {
+		j := bytes.Index(r.pending[i:], r.old)
+		if j < 0 {
+			break
+		}
+		out = append(out, r.pending[i:i+j]...)
+		out = append(out, r.new...)
+		i += j + len(r.old)
+	}
+	safe := len(r.pending)
+	if !r.eof {
+		safe -= len(r.old) - 1
+	}
+	if safe < i {
+		safe = i
+	}
+	r.out = append(out, r.pending[i:safe]...)
+	r.pending = append([]byte(nil), r.pending[safe:]...)
+}
+
+func (r *replaceReader) Close() error {
+	return r.src.Close()
+}
+
+type proxyTrailingSlashKey struct{}
+
+// PreserveProxyTrailingSlash 作为 iris 的 WrapRouter，iris 会将末尾带 / 的路径重定向到不带 / 的路径，
+// 代理请求需要原样转发，因此先去掉末尾的 / 并记录在请求的 context 中
+func PreserveProxyTrailingSlash(w http.ResponseWriter, r *http.Request, router http.HandlerFunc) {
+	if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") && strings.Contains(r.URL.Path, "/k8s/proxy/") {
+		r = r.WithContext(context.WithValue(r.Context(), proxyTrailingSlashKey{}, true))
+		r.URL.Path = strings.TrimRight(r.URL.Path, "/")
+		r.URL.RawPath = strings.TrimRight(r.URL.RawPath, "/")
+	}
+	router(w, r)
+}
+
+func hasProxyTrailingSlash(r *http.Request) bool {
+	value, _ := r.Context().Value(proxyTrailingSlashKey{}).(bool)
+	return value
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/iris-contrib/httpexpect/v2"
+	"github.com/kataras/iris/v12"
+	irishttptest "github.com/kataras/iris/v12/httptest"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestParseProxyTarget(t *testing.T) {
+	tests := []struct {
+		resource, target string
+		want             string
+		wantErr          bool
+	}{
+		{"pods", "web:8080", "web:8080", false},
+		{"services", "https:web:metrics", "https:web:metrics", false},
+		{"services", "web", "", true},
+		{"services", "web:", "", true},
+		{"services", "ftp:web:21", "", true},
+		{"nodes", "node-1:10250", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseProxyTarget(tt.resource, tt.target)
+		if (err != nil) != tt.wantErr || (err == nil && got.apiName() != tt.want) {
+			t.Errorf("parseProxyTarget(%q, %q) = %q, %v", tt.resource, tt.target, got.apiName(), err)
+		}
+	}
+}
+
+func TestProxyOptionsAllowed(t *testing.T) {
+	if (ProxyOptions{}).Allowed("default", "80") {
+		t.Error("empty allowlist should deny")
+	}
+	o := ProxyOptions{Namespaces: []string{"default"}, Ports: []string{"*"}}
+	if !o.Allowed("default", "http") || o.Allowed("kube-system", "80") {
+		t.Errorf("%+v: unexpected result", o)
+	}
+}
+
+func TestReplaceReader(t *testing.T) {
+	body := strings.Repeat(`<a href="/api/v1/proxy/x">终端</a>`, 100)
+	want := strings.ReplaceAll(body, "/api/v1/proxy/", "/k8s/proxy/")
+	// 每次只读一个字节，匹配会跨越多次读取
+	r := newReplaceReader(io.NopCloser(iotest.OneByteReader(strings.NewReader(body))), []byte("/api/v1/proxy/"), []byte("/k8s/proxy/"))
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != want {
+		t.Errorf("replaceReader = %q, %v", got, err)
+	}
+}
+
+// TestProxyRequest 用 httptest 服务代替 apiserver，验证路径、请求头和响应的改写
+func TestProxyRequest(t *testing.T) {
+	const upstream = "/api/v1/namespaces/default/services/web:80/proxy"
+	apiserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer cluster-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		for key := range r.Header {
+			if strings.HasPrefix(key, "Impersonate-") || key == "Cookie" {
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, key+" forwarded")
+				return
+			}
+		}
+		switch r.URL.Path {
+		case upstream + "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Set-Cookie", "session=pod")
+			io.WriteString(w, `<a href="`+upstream+`/ui/">ui</a>`)
+		case upstream + "/login":
+			w.Header().Set("Location", upstream+"/ui/")
+			w.WriteHeader(http.StatusFound)
+		default:
+			io.WriteString(w, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		}
+	}))
+	defer apiserver.Close()
+
+	config := &rest.Config{Host: apiserver.URL, BearerToken: "cluster-token"}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {ClusterInfo: ClusterInfo{ID: "test"}, Config: config, ClientSet: client}},
+		order:    []string{"test"},
+	}
+	k := &K8sService{Registry: reg, Proxy: ProxyOptions{Namespaces: []string{"default"}, Ports: []string{"80"}}}
+	app := iris.New()
+	app.WrapRouter(PreserveProxyTrailingSlash)
+	app.Any("/k8s/proxy/namespaces/{namespace}/{resource}/{target}", k.ProxyRequest)
+	app.Any("/k8s/proxy/namespaces/{namespace}/{resource}/{target}/{path:path}", k.ProxyRequest)
+	e := irishttptest.New(t, app, irishttptest.URL("http://example.com"))
+
+	const local = "/k8s/proxy/namespaces/default/services/web:80"
+	e.GET(local+"/").WithHeader("Authorization", "Bearer client").Expect().Status(http.StatusOK).
+		Body().IsEqual(`<a href="` + local + `/ui/">ui</a>`)
+	page := e.GET(local + "/").Expect()
+	page.Header("Set-Cookie").IsEmpty()
+	page.Header("Content-Security-Policy").IsEqual("sandbox")
+	e.POST(local+"/api/items/").WithQuery("cluster", "test").WithQuery("q", "1").Expect().Status(http.StatusOK).
+		Body().IsEqual("POST " + upstream + "/api/items/?q=1")
+	e.GET(local+"/headers").WithHeader("Impersonate-User", "admin").WithHeader("Impersonate-Group", "system:masters").
+		WithHeader("Impersonate-Extra-Scopes", "view").WithHeader("Impersonate-Uid", "1").WithHeader("Cookie", "session=1").
+		Expect().Status(http.StatusOK).Body().IsEqual("GET " + upstream + "/headers?")
+	e.GET(local + "/login").WithRedirectPolicy(httpexpect.DontFollowRedirects).Expect().Status(http.StatusFound).
+		Header("Location").IsEqual(local + "/ui/")
+
+	e.GET("/k8s/proxy/namespaces/default/services/web:8080/").Expect().Status(http.StatusForbidden)
+	e.GET("/k8s/proxy/namespaces/kube-system/services/web:80/").Expect().Status(http.StatusForbidden)
+}
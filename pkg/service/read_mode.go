@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+)
+
+// 列表接口的数据来源，由 read.mode 配置
+const (
+	ReadLive          = "live"           // informer 缓存，缓存不可用时查询 apiserver
+	ReadCache         = "cache"          // 只读取 redis 快照
+	ReadCacheFallback = "cache-fallback" // 同 live，查询 apiserver 失败时读取 redis 快照
+)
+
+// SnapshotAgeHeader 数据来自 redis 快照时返回快照的时间（秒），多集群时取最旧的快照。
+// 非 live 模式下 informer 缓存因集群不可达或 watch 中断而停止更新时，同样返回缓存的时间
+const SnapshotAgeHeader = "X-Snapshot-Age"
+
+func LoadReadMode() string {
+	mode := sysinit.GCF.UString("read.mode", ReadLive)
+	switch mode {
+	case ReadLive, ReadCache, ReadCacheFallback:
+		return mode
+	}
+	logrus.Warnf("Unknown read.mode %q, using %s", mode, ReadLive)
+	return ReadLive
+}
+
+// snapshotAge 记录本次响应中最旧的快照时间
+type snapshotAge struct {
+	oldest time.Time
+}
+
+func (a *snapshotAge) add(at time.Time) {
+	if !at.IsZero() && (a.oldest.IsZero() || at.Before(a.oldest)) {
+		a.oldest = at
+	}
+}
+
+func (a *snapshotAge) setHeader(ctx iris.Context) {
+	if a.oldest.IsZero() {
+		return
+	}
+	ctx.Header(SnapshotAgeHeader, strconv.FormatInt(int64(time.Since(a.oldest)/time.Second), 10))
+}
+
+// readItems 按读取模式获取单个集群的某类资源，返回值来自快照或已停止更新的缓存时同时返回数据的时间
+func readItems[T any](mode string, ctx context.Context, c *Cluster, q req.ListQuery, kind string, live liveFunc[T], item func(T) req.ListItem) ([]T, time.Time, error) {
+	if mode == ReadCache {
+		return readSnapshot(ctx, c, kind, item)
+	}
+	if c.Engine.Cached(kind) {
+		var at time.Time
+		if syncedAt, stale := c.Engine.Stale(kind); stale && mode != ReadLive {
+			at = syncedAt
+		}
+		return listCached[T](c.Engine, kind), at, nil
+	}
+	items, _, err := live(ctx, c, q)
+	if err != nil && mode == ReadCacheFallback {
+		logrus.Warnf("Failed to list %s from cluster %s, using Redis snapshot: %v", kind, c.ID, err)
+		snapshot, at, serr := readSnapshot(ctx, c, kind, item)
+		if serr != nil {
+			return nil, time.Time{}, fmt.Errorf("%w (snapshot unavailable: %v)", err, serr)
+		}
+		return snapshot, at, nil
+	}
+	return items, time.Time{}, err
+}
+
+// readSnapshot 从 redis 快照读取某类资源，按命名空间和名称排序
+func readSnapshot[T any](ctx context.Context, c *Cluster, kind string, item func(T) req.ListItem) ([]T, time.Time, error) {
+	if c.Syncer == nil || !c.Engine.Watching(kind) {
+		return nil, time.Time{}, fmt.Errorf("%s of cluster %s is not synced to Redis", kind, c.ID)
+	}
+	values, at, err := c.Syncer.store.ReadKind(ctx, kind)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	items := make([]T, 0, len(values))
+	for _, value := range values {
+		var it T
+		if err := json.Unmarshal([]byte(value), &it); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to decode %s from Redis: %w", kind, err)
+		}
+		items = append(items, it)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := item(items[i]), item(items[j])
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return items, at, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReadModeFallback(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	store := &RedisStore{client: rdb, prefix: "cmdb-k8s-", clusterID: "test"}
+	data := ClusterData{
+		ClusterId: "test",
+		Services: []vo.ServiceVo{
+			{ServiceID: "test/default/web", Name: "web", Namespace: "default"},
+			{ServiceID: "test/data/db", Name: "db", Namespace: "data"},
+		},
+	}
+	if err := store.Write(context.Background(), data, 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// apiserver 不可用
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{})
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {
+			ClusterInfo: ClusterInfo{ID: "test"},
+			ClientSet:   client,
+			Engine:      engine,
+			Syncer:      &SyncManager{engine: engine, store: store},
+		}},
+		order: []string{"test"},
+	}
+	k := &K8sService{Registry: reg, ReadMode: ReadLive}
+	app := iris.New()
+	app.Get("/services", k.GetServices)
+	app.Get("/pods", k.GetPods)
+	e := httptest.New(t, app)
+
+	r := e.GET("/services").Expect()
+	r.JSON().Object().Value("code").IsEqual(resp.MESSAGE_ERROR)
+	r.Header(SnapshotAgeHeader).IsEmpty()
+
+	k.ReadMode = ReadCacheFallback
+	r = e.GET("/services").WithQuery("namespace", "default").Expect()
+	r.Header(SnapshotAgeHeader).IsEqual("0")
+	items := r.JSON().Object().Value("data").Array()
+	items.Length().IsEqual(1)
+	items.Value(0).Object().Value("name").IsEqual("web")
+
+	// 分块读取失败时同样回退到快照，并在内存中分页
+	r = e.GET("/services").WithQuery("limit", 1).Expect()
+	r.Header(SnapshotAgeHeader).NotEmpty()
+	page := r.JSON().Object()
+	page.Value("data").Array().Value(0).Object().Value("name").IsEqual("db")
+	page.Value("pagination").Object().Value("total").IsEqual(2)
+
+	k.ReadMode = ReadCache
+	e.GET("/services").Expect().Header(SnapshotAgeHeader).NotEmpty()
+	// 未同步的资源类型没有快照
+	e.GET("/pods").Expect().JSON().Object().Value("code").IsEqual(resp.MESSAGE_ERROR)
+}
+
+func TestReadModeStaleCache(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("informer not synced")
+	}
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {ClusterInfo: ClusterInfo{ID: "test"}, ClientSet: client, Engine: engine}},
+		order:    []string{"test"},
+	}
+	k := &K8sService{Registry: reg, ReadMode: ReadCacheFallback}
+	app := iris.New()
+	app.Get("/services", k.GetServices)
+	e := httptest.New(t, app)
+
+	engine.RefreshVersion()
+	e.GET("/services").Expect().Status(httptest.StatusOK).Header(SnapshotAgeHeader).IsEmpty()
+
+	// watch 中断后缓存可能已过期
+	engine.mu.Lock()
+	engine.syncedAt = time.Now().Add(-time.Minute)
+	engine.watchErrAt[KindService] = time.Now()
+	engine.mu.Unlock()
+	r := e.GET("/services").Expect().Status(httptest.StatusOK)
+	r.Header(SnapshotAgeHeader).IsEqual("60")
+	r.JSON().Object().Value("data").Array().Length().IsEqual(1)
+
+	// apiserver 不可达
+	engine.mu.Lock()
+	delete(engine.watchErrAt, KindService)
+	engine.probeErr = errors.New("connection refused")
+	engine.mu.Unlock()
+	e.GET("/services").Expect().Header(SnapshotAgeHeader).IsEqual("60")
+
+	k.ReadMode = ReadLive
+	e.GET("/services").Expect().Header(SnapshotAgeHeader).IsEmpty()
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ErrNoSnapshot redis 中没有集群快照（尚未写入或已过期）
+var ErrNoSnapshot = errors.New("no snapshot in Redis")
+
+// RedisStore 以结构化的方式将集群快照写入 redis
+//
+//	{prefix}{clusterID}:meta                   集群元数据 hash，kinds 为写入时有对象的资源类型
+//	{prefix}{clusterID}:{kind}                 每类资源一个 hash，field 为 ServiceID/NamespaceID 等
+//	{prefix}{clusterID}:ns:{namespace}:{kind}  按命名空间建立的二级索引 set
+//	{prefix}{clusterID}                        旧版整体 JSON，由 redis.legacyBlob 控制
+//
+// 每次写入只提交与上次写入相比发生变化的对象，并在同一个 MULTI/EXEC 事务中完成，
+// 读取方不会看到写了一半的数据。配置了 publisher 时，变化事件也在同一事务中发布。
+type RedisStore struct {
+	client    *redis.Client
+	prefix    string
+	clusterID string
+	legacy    bool
+	publisher *ChangePublisher
+
+	loaded   bool
+	baseline bool                             // redis 中已有上次写入的数据，首次全量写入不产生变化事件
+	written  map[string]map[string]storedItem // kind -> id -> 已写入的对象
+}
+
+type storedItem struct {
+	Namespace string
+	Data      string
+}
+
+// itemChange 两次快照之间单个对象的变化，Old 为空表示新增，New 为空表示删除
+type itemChange struct {
+	Kind string
+	ID   string
+	Old  *storedItem
+	New  *storedItem
+}
+
+func NewRedisStore(client *redis.Client, clusterID string) *RedisStore {
+	s := &RedisStore{
+		client:    client,
+		prefix:    sysinit.GCF.UString("redis.keyPrefix"),
+		clusterID: clusterID,
+		legacy:    sysinit.GCF.UBool("redis.legacyBlob", true),
+	}
+	if sysinit.GCF.UBool("events.enabled", true) {
+		s.publisher = NewChangePublisher(client, clusterID)
+	}
+	return s
+}
+
+func (s *RedisStore) LegacyKey() string {
+	return s.prefix + s.clusterID
+}
+
+func (s *RedisStore) MetaKey() string {
+	return s.LegacyKey() + ":meta"
+}
+
+func (s *RedisStore) KindKey(kind string) string {
+	return s.LegacyKey() + ":" + kind
+}
+
+func (s *RedisStore) IndexKey(kind, namespace string) string {
+	return s.LegacyKey() + ":ns:" + namespace + ":" + kind
+}
+
+// EnsureGroups 创建事件 stream 的消费组
+func (s *RedisStore) EnsureGroups(ctx context.Context) error {
+	if s.publisher == nil {
+		return nil
+	}
+	return s.publisher.EnsureGroups(ctx)
+}
+
+// Write 增量写入快照，失败后下次写入会重新从 redis 加载现状
+func (s *RedisStore) Write(ctx context.Context, data ClusterData, generation uint64, ttl time.Duration) error {
+	snapshot := data.Items()
+	if !s.loaded {
+		if err := s.load(ctx, snapshot); err != nil {
+			return err
+		}
+	}
+
+	next := make(map[string]map[string]storedItem, len(snapshot))
+	for kind, items := range snapshot {
+		next[kind] = make(map[string]storedItem, len(items))
+		for _, item := range items {
+			b, err := json.Marshal(item.Object)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s %s: %w", kind, item.ID, err)
+			}
+			next[kind][item.ID] = storedItem{Namespace: item.Namespace, Data: string(b)}
+		}
+	}
+
+	var legacyData []byte
+	if s.legacy {
+		legacy := data
+		legacy.Pods = nil
+		b, err := json.Marshal(legacy)
+		if err != nil {
+			return fmt.Errorf("failed to marshal data: %w", err)
+		}
+		legacyData = b
+	}
+
+	changes := diffItems(s.written, next)
+	var events []vo.ChangeEventVo
+	if s.publisher != nil && s.baseline {
+		var err error
+		if events, err = s.publisher.Events(ctx, changes); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().Format(timeLayout)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		s.applyChanges(ctx, pipe, changes)
+
+		meta := map[string]interface{}{
+			"clusterID":       data.ClusterId,
+			"clusterName":     data.ClusterName,
+			"clusterRegionID": data.ClusterRegionID,
+			"k8sVersion":      data.K8sVersion,
+			"generation":      strconv.FormatUint(generation, 10),
+			"syncedAt":        now,
+			"refreshedAt":     now,
+			"kinds":           strings.Join(writtenKinds(next), ","),
+		}
+		if len(events) > 0 {
+			meta["revision"] = strconv.FormatInt(events[len(events)-1].Revision, 10)
+			s.publisher.Publish(ctx, pipe, events)
+		}
+		pipe.HSet(ctx, s.MetaKey(), meta)
+		pipe.Expire(ctx, s.MetaKey(), ttl)
+		if s.legacy {
+			pipe.Set(ctx, s.LegacyKey(), legacyData, ttl)
+		}
+		for _, key := range s.keys(next) {
+			pipe.Expire(ctx, key, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		s.loaded = false
+		return fmt.Errorf("failed to write data to Redis: %w", err)
+	}
+
+	s.written = next
+	s.baseline = true
+	return nil
+}
+
+// Touch 数据未变化时延长所有 key 的过期时间，元数据已不存在时返回 false
+func (s *RedisStore) Touch(ctx context.Context, ttl time.Duration) (bool, error) {
+	ok, err := s.client.Expire(ctx, s.MetaKey(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh ttl in Redis: %w", err)
+	}
+	if !ok {
+		s.loaded = false
+		return false, nil
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		// 数据未变化也说明快照仍与缓存一致
+		pipe.HSet(ctx, s.MetaKey(), "refreshedAt", time.Now().Format(timeLayout))
+		if s.legacy {
+			pipe.Expire(ctx, s.LegacyKey(), ttl)
+		}
+		for _, key := range s.keys(s.written) {
+			pipe.Expire(ctx, key, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh ttl in Redis: %w", err)
+	}
+	return true, nil
+}
+
+// ReadKind 读取某类资源的快照，同时返回快照最后一次确认与缓存一致的时间
+// 写入时有对象的资源类型 hash 已不存在（过期）时同样返回 ErrNoSnapshot，不把空列表当作快照
+func (s *RedisStore) ReadKind(ctx context.Context, kind string) ([]string, time.Time, error) {
+	var meta *redis.SliceCmd
+	var values *redis.StringSliceCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		meta = pipe.HMGet(ctx, s.MetaKey(), "refreshedAt", "syncedAt", "kinds")
+		values = pipe.HVals(ctx, s.KindKey(kind))
+		return nil
+	})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read %s from Redis: %w", kind, err)
+	}
+
+	fields := meta.Val()
+	kinds, _ := fields[2].(string)
+	if len(values.Val()) == 0 && kinds != "" && sets.New(strings.Split(kinds, ",")...).Has(kind) {
+		return nil, time.Time{}, ErrNoSnapshot
+	}
+	for _, v := range fields[:2] {
+		str, _ := v.(string)
+		if at, err := time.ParseInLocation(timeLayout, str, time.Local); err == nil {
+			return values.Val(), at, nil
+		}
+	}
+	return nil, time.Time{}, ErrNoSnapshot
+}
+
+// writtenKinds 返回有对象的资源类型，按名称排序
+func writtenKinds(items map[string]map[string]storedItem) []string {
+	var kinds []string
+	for kind, objs := range items {
+		if len(objs) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// diffItems 比较两次快照，结果按 kind、id 排序
+func diffItems(old, next map[string]map[string]storedItem) []itemChange {
+	var changes []itemChange
+	for kind, items := range next {
+		for id, item := range items {
+			item := item
+			prev, ok := old[kind][id]
+			if !ok {
+				changes = append(changes, itemChange{Kind: kind, ID: id, New: &item})
+			} else if prev != item {
+				changes = append(changes, itemChange{Kind: kind, ID: id, Old: &prev, New: &item})
+			}
+		}
+	}
+	for kind, items := range old {
+		for id, item := range items {
+			item := item
+			if _, ok := next[kind][id]; !ok {
+				changes = append(changes, itemChange{Kind: kind, ID: id, Old: &item})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// applyChanges 新增或变化的对象 HSET，消失的对象 HDEL，同时维护命名空间索引
+func (s *RedisStore) applyChanges(ctx context.Context, pipe redis.Pipeliner, changes []itemChange) {
+	changed := map[string]map[string]interface{}{}
+	removed := map[string][]string{}
+	for _, change := range changes {
+		if change.Old != nil && change.Old.Namespace != "" &&
+			(change.New == nil || change.New.Namespace != change.Old.Namespace) {
+			pipe.SRem(ctx, s.IndexKey(change.Kind, change.Old.Namespace), change.ID)
+		}
+		if change.New == nil {
+			removed[change.Kind] = append(removed[change.Kind], change.ID)
+			continue
+		}
+		if change.New.Namespace != "" {
+			pipe.SAdd(ctx, s.IndexKey(change.Kind, change.New.Namespace), change.ID)
+		}
+		if changed[change.Kind] == nil {
+			changed[change.Kind] = map[string]interface{}{}
+		}
+		changed[change.Kind][change.ID] = change.New.Data
+	}
+
+	for kind, fields := range changed {
+		pipe.HSet(ctx, s.KindKey(kind), fields)
+	}
+	for kind, ids := range removed {
+		pipe.HDel(ctx, s.KindKey(kind), ids...)
+	}
+}
+
+// load 从 redis 读取已写入的数据作为增量比较的基准，进程重启后也能清理过期对象
+func (s *RedisStore) load(ctx context.Context, snapshot map[string][]storeItem) error {
+	written := make(map[string]map[string]storedItem, len(snapshot))
+	for kind := range snapshot {
+		values, err := s.client.HGetAll(ctx, s.KindKey(kind)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to load %s from Redis: %w", kind, err)
+		}
+
+		written[kind] = make(map[string]storedItem, len(values))
+		for id, value := range values {
+			var obj struct {
+				Namespace string `json:"namespace"`
+			}
+			_ = json.Unmarshal([]byte(value), &obj)
+			written[kind][id] = storedItem{Namespace: obj.Namespace, Data: value}
+		}
+	}
+
+	exists, err := s.client.Exists(ctx, s.MetaKey()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load meta from Redis: %w", err)
+	}
+
+	s.written = written
+	s.loaded = true
+	s.baseline = exists > 0
+	return nil
+}
+
+// keys 返回需要设置过期时间的 key（不含 meta 和旧版 key）
+func (s *RedisStore) keys(items map[string]map[string]storedItem) []string {
+	seen := map[string]struct{}{}
+	var keys []string
+	add := func(key string) {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	for kind, objs := range items {
+		add(s.KindKey(kind))
+		for _, item := range objs {
+			if item.Namespace != "" {
+				add(s.IndexKey(kind, item.Namespace))
+			}
+		}
+	}
+	return keys
+}
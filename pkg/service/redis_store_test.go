@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return mr, redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestRedisStoreWrite(t *testing.T) {
+	mr, client := newTestRedis(t)
+	store := &RedisStore{client: client, prefix: "cmdb-k8s-", clusterID: "test", legacy: true}
+	ctx := context.Background()
+
+	data := ClusterData{
+		ClusterId:  "test",
+		Namespaces: []vo.NamespaceVo{{NamespaceID: "test/default", Name: "default"}},
+		Services: []vo.ServiceVo{
+			{ServiceID: "test/default/web", Name: "web", Namespace: "default"},
+			{ServiceID: "test/default/api", Name: "api", Namespace: "default"},
+		},
+	}
+	if err := store.Write(ctx, data, 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if fields, _ := mr.HKeys("cmdb-k8s-test:service"); len(fields) != 2 {
+		t.Errorf("service hash fields = %v", fields)
+	}
+	if members, _ := mr.Members("cmdb-k8s-test:ns:default:service"); len(members) != 2 {
+		t.Errorf("namespace index = %v", members)
+	}
+	if mr.HGet("cmdb-k8s-test:meta", "generation") != "1" {
+		t.Errorf("meta generation = %q", mr.HGet("cmdb-k8s-test:meta", "generation"))
+	}
+	if !mr.Exists("cmdb-k8s-test") {
+		t.Errorf("legacy blob not written")
+	}
+	for _, key := range []string{"cmdb-k8s-test:service", "cmdb-k8s-test:meta"} {
+		if ttl := mr.TTL(key); ttl != time.Hour {
+			t.Errorf("%s ttl = %v", key, ttl)
+		}
+	}
+
+	// 删除一个 service 后只应移除对应的 field 和索引
+	data.Services = data.Services[:1]
+	if err := store.Write(ctx, data, 2, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if fields, _ := mr.HKeys("cmdb-k8s-test:service"); len(fields) != 1 || fields[0] != "test/default/web" {
+		t.Errorf("service hash fields = %v", fields)
+	}
+	if members, _ := mr.Members("cmdb-k8s-test:ns:default:service"); len(members) != 1 {
+		t.Errorf("namespace index = %v", members)
+	}
+
+	// 新的 store 实例从 redis 加载现状，能清理重启期间消失的对象
+	restarted := &RedisStore{client: client, prefix: "cmdb-k8s-", clusterID: "test"}
+	data.Services = nil
+	if err := restarted.Write(ctx, data, 3, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("cmdb-k8s-test:service") || mr.Exists("cmdb-k8s-test:ns:default:service") {
+		t.Errorf("stale services not removed")
+	}
+
+	if ok, err := restarted.Touch(ctx, time.Minute); err != nil || !ok {
+		t.Errorf("Touch() = %v, %v", ok, err)
+	}
+	mr.FlushAll()
+	if ok, _ := restarted.Touch(ctx, time.Minute); ok {
+		t.Errorf("Touch() after flush = true")
+	}
+}
+
+func TestRedisStoreReadKind(t *testing.T) {
+	mr, client := newTestRedis(t)
+	store := &RedisStore{client: client, prefix: "cmdb-k8s-", clusterID: "test"}
+	ctx := context.Background()
+
+	data := ClusterData{
+		ClusterId:  "test",
+		Namespaces: []vo.NamespaceVo{{NamespaceID: "test/default", Name: "default"}},
+	}
+	if err := store.Write(ctx, data, 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if values, at, err := store.ReadKind(ctx, KindNamespace); err != nil || len(values) != 1 || at.IsZero() {
+		t.Fatalf("ReadKind(namespace) = %v, %v, %v", values, at, err)
+	}
+	// 写入时没有对象的资源类型返回空列表
+	if values, _, err := store.ReadKind(ctx, KindService); err != nil || len(values) != 0 {
+		t.Errorf("ReadKind(service) = %v, %v", values, err)
+	}
+	// 资源 hash 过期而 meta 仍在时不能当作空快照
+	mr.Del(store.KindKey(KindNamespace))
+	if _, _, err := store.ReadKind(ctx, KindNamespace); err != ErrNoSnapshot {
+		t.Errorf("ReadKind() after kind expired = %v, want ErrNoSnapshot", err)
+	}
+}
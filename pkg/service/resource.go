@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// coreGroup 路径中用 core 表示核心 API 组（组名为空）
+const coreGroup = "core"
+
+// discoveryRefreshInterval 查找资源未命中时刷新 discovery 缓存的最小间隔，避免未知资源的请求反复触发全量 discovery
+const discoveryRefreshInterval = 30 * time.Second
+
+// ResourceOptions 通用资源接口配置
+type ResourceOptions struct {
+	Denylist sets.Set[string] // 禁止读取的资源，格式同 schema.GroupResource.String()，例如 secrets、rollouts.argoproj.io
+}
+
+func LoadResourceOptions() ResourceOptions {
+	options := ResourceOptions{Denylist: sets.New[string]()}
+	for _, item := range sysinit.GCF.UList("resources.denylist", []interface{}{"secrets"}) {
+		if value, ok := item.(string); ok && value != "" {
+			options.Denylist.Insert(value)
+		}
+	}
+	return options
+}
+
+// Denied 资源是否在禁止读取的列表中
+func (o ResourceOptions) Denied(gvr schema.GroupVersionResource) bool {
+	return o.Denylist.Has(gvr.GroupResource().String())
+}
+
+// GetAPIResources
+// @Summary 获取集群支持的API资源
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param refresh query bool false "刷新discovery缓存"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/api-resources [get]
+// @Router /api/v1/clusters/{cluster}/k8s/api-resources [get]
+func (k *K8sService) GetAPIResources(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	if ctx.URLParamDefault("refresh", "false") == "true" {
+		c.invalidateDiscovery()
+	}
+
+	lists, err := c.Discovery.ServerPreferredResources()
+	if err != nil {
+		// 部分 API 组（如不可用的 aggregated API）失败时仍返回其余结果
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			ctx.JSON(resp.ErrorWithMsg(err.Error()))
+			return
+		}
+		logrus.Warnf("Partial discovery failure in cluster %s: %v", c.ID, err)
+	}
+
+	results := []vo.APIResourceVo{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			results = append(results, vo.APIResourceVo{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   r.Name,
+				Kind:       r.Kind,
+				Namespaced: r.Namespaced,
+				Verbs:      r.Verbs,
+				ShortNames: r.ShortNames,
+				Categories: r.Categories,
+			})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Group != results[j].Group {
+			return results[i].Group < results[j].Group
+		}
+		return results[i].Resource < results[j].Resource
+	})
+	ctx.JSON(resp.OkWithData(results))
+}
+
+// GetResources
+// @Summary 通过dynamic client获取任意资源
+// @Description resources.denylist 中的资源（默认 secrets）返回 403
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param group path string true "API组，核心组使用 core"
+// @Param version path string true "API版本"
+// @Param resource path string true "资源名称（复数形式）"
+// @Param namespace query string false "命名空间，仅对命名空间级资源有效，为空表示所有命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器"
+// @Param type query string false "按 spec.type 过滤"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime，未指定时使用 apiserver 分块读取"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/resources/{group}/{version}/{resource} [get]
+// @Router /api/v1/k8s/resources/{group}/{version}/namespaces/{namespace}/{resource} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/resources/{group}/{version}/{resource} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/resources/{group}/{version}/namespaces/{namespace}/{resource} [get]
+func (k *K8sService) GetResources(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	q, err := req.ParseListQuery(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	if namespace := ctx.Params().Get("namespace"); namespace != "" {
+		q.Namespace = namespace
+	}
+
+	gvr := resourceParams(ctx)
+	if k.Resources.Denied(gvr) {
+		ctx.StatusCode(iris.StatusForbidden)
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("resource %s is not allowed", gvr.GroupResource())))
+		return
+	}
+	ri, err := c.resourceClient(gvr, q.Namespace, "list")
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	// 资源类型未知，fieldSelector 全部交给 apiserver 校验，内存中不再重复过滤
+	fieldSelector := q.FieldSelector.String()
+	q.FieldSelector = fields.Everything()
+	// 未指定排序时使用 apiserver 分块读取，否则读取全部后在内存中排序分页
+	q.Chunk = q.Sort == ""
+	if !q.Chunk && q.ChunkCursor() {
+		ctx.JSON(resp.BadResponse("continue token does not match sort and order"))
+		return
+	}
+	opts := q.ListOptions()
+	opts.FieldSelector = fieldSelector
+	list, err := ri.List(ctx.Request().Context(), opts)
+	if err != nil {
+		ctx.JSON(resp.ErrorWithMsg(err.Error()))
+		return
+	}
+
+	results := make([]map[string]interface{}, 0, len(list.Items))
+	for i := range list.Items {
+		list.Items[i].SetManagedFields(nil)
+		results = append(results, list.Items[i].Object)
+	}
+	results, page := req.Paginate(filterItems(results, q, unstructuredItem), q, unstructuredItem)
+	switch {
+	case !q.Paged():
+		ctx.JSON(resp.OkWithData(results))
+	case q.Chunk:
+		ctx.JSON(resp.OkWithPage(results, chunkPagination(q, unstructuredListMeta(list))))
+	default:
+		ctx.JSON(resp.OkWithPage(results, resp.Pagination{Total: int64(page.Total), Limit: q.Limit, Continue: page.Continue}))
+	}
+}
+
+// unstructuredItem type 参数匹配 spec.type
+func unstructuredItem(obj map[string]interface{}) req.ListItem {
+	u := unstructured.Unstructured{Object: obj}
+	itemType, _, _ := unstructured.NestedString(obj, "spec", "type")
+	return req.ListItem{
+		ID:         u.GetNamespace() + "/" + u.GetName(),
+		CreateTime: u.GetCreationTimestamp().Local().Format(timeLayout),
+		Name:       u.GetName(),
+		Namespace:  u.GetNamespace(),
+		Type:       itemType,
+		Labels:     u.GetLabels(),
+	}
+}
+
+// resourceParams 从路径参数解析 GroupVersionResource
+func resourceParams(ctx iris.Context) schema.GroupVersionResource {
+	group := ctx.Params().Get("group")
+	if group == coreGroup {
+		group = ""
+	}
+	return schema.GroupVersionResource{
+		Group:    group,
+		Version:  ctx.Params().Get("version"),
+		Resource: ctx.Params().Get("resource"),
+	}
+}
+
+// invalidateDiscovery 刷新 discovery 缓存，距上次刷新不足 discoveryRefreshInterval 时跳过并返回 false
+func (c *Cluster) invalidateDiscovery() bool {
+	c.discoveryMu.Lock()
+	defer c.discoveryMu.Unlock()
+	if time.Since(c.discoveryAt) < discoveryRefreshInterval {
+		return false
+	}
+	c.discoveryAt = time.Now()
+	c.Discovery.Invalidate()
+	return true
+}
+
+// findAPIResource 通过 discovery 查找资源，未找到时刷新缓存重试一次（可能是新安装的 CRD），
+// 刷新受 discoveryRefreshInterval 限制
+func (c *Cluster) findAPIResource(gvr schema.GroupVersionResource) (*metav1.APIResource, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		list, err := c.Discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+		if err == nil {
+			for i := range list.APIResources {
+				if list.APIResources[i].Name == gvr.Resource {
+					return &list.APIResources[i], nil
+				}
+			}
+		}
+		if attempt > 0 || !c.invalidateDiscovery() {
+			break
+		}
+	}
+	return nil, fmt.Errorf("resource %s is not served by cluster %s", gvr.String(), c.ID)
+}
+
+// resourceClient 校验资源支持 verb，返回对应作用域的 dynamic 客户端
+func (c *Cluster) resourceClient(gvr schema.GroupVersionResource, namespace, verb string) (dynamic.ResourceInterface, error) {
+	r, err := c.findAPIResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+	if !sets.New(r.Verbs...).Has(verb) {
+		return nil, fmt.Errorf("resource %s does not support %s", gvr.String(), verb)
+	}
+	if !r.Namespaced {
+		if namespace != "" {
+			return nil, fmt.Errorf("resource %s is cluster-scoped", gvr.String())
+		}
+		return c.Dynamic.Resource(gvr), nil
+	}
+	return c.Dynamic.Resource(gvr).Namespace(namespace), nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResourceClient(t *testing.T) {
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	clusterWidgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "clusterwidgets"}
+
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "widgets", Namespaced: true, Kind: "Widget", Verbs: metav1.Verbs{"get", "list"}},
+			{Name: "clusterwidgets", Kind: "ClusterWidget", Verbs: metav1.Verbs{"get"}},
+		},
+	}}
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w1", "namespace": "default"},
+	}}
+	c := &Cluster{
+		ClusterInfo: ClusterInfo{ID: "test"},
+		Dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{widgets: "WidgetList", clusterWidgets: "ClusterWidgetList"}, widget),
+		Discovery: memory.NewMemCacheClient(client.Discovery()),
+	}
+
+	ri, err := c.resourceClient(widgets, "default", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := ri.List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(list.Items) != 1 || list.Items[0].GetName() != "w1" {
+		t.Fatalf("List() = %v, %v", list, err)
+	}
+
+	if _, err := c.resourceClient(clusterWidgets, "", "list"); err == nil {
+		t.Fatal("expected error for unsupported verb")
+	}
+	if _, err := c.resourceClient(clusterWidgets, "default", "get"); err == nil {
+		t.Fatal("expected error for namespace on cluster-scoped resource")
+	}
+	if _, err := c.resourceClient(schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "gadgets"}, "", "list"); err == nil {
+		t.Fatal("expected error for unknown resource")
+	}
+}
+
+// countingDiscovery 记录 discovery 缓存被刷新的次数
+type countingDiscovery struct {
+	discovery.CachedDiscoveryInterface
+	invalidated int
+}
+
+func (d *countingDiscovery) Invalidate() {
+	d.invalidated++
+	d.CachedDiscoveryInterface.Invalidate()
+}
+
+func TestFindAPIResourceInvalidate(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	d := &countingDiscovery{CachedDiscoveryInterface: memory.NewMemCacheClient(client.Discovery())}
+	c := &Cluster{ClusterInfo: ClusterInfo{ID: "test"}, Discovery: d}
+
+	gadgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "gadgets"}
+	for i := 0; i < 3; i++ {
+		if _, err := c.findAPIResource(gadgets); err == nil {
+			t.Fatal("expected error for unknown resource")
+		}
+	}
+	if d.invalidated != 1 {
+		t.Errorf("invalidated = %d, want 1 within discoveryRefreshInterval", d.invalidated)
+	}
+}
+
+func TestGetResourcesDenylist(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: metav1.Verbs{"get", "list"}}},
+	}}
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {
+			ClusterInfo: ClusterInfo{ID: "test"},
+			Dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{{Version: "v1", Resource: "secrets"}: "SecretList"}),
+			Discovery: memory.NewMemCacheClient(client.Discovery()),
+		}},
+		order: []string{"test"},
+	}
+	k := &K8sService{Registry: reg, Resources: ResourceOptions{Denylist: sets.New("secrets")}}
+	app := iris.New()
+	app.Get("/resources/{group}/{version}/{resource}", k.GetResources)
+	e := httptest.New(t, app)
+
+	e.GET("/resources/core/v1/secrets").Expect().Status(httptest.StatusForbidden)
+	k.Resources.Denylist = sets.New[string]()
+	e.GET("/resources/core/v1/secrets").Expect().Status(httptest.StatusOK)
+}
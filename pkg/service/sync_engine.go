@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/v12/x/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// 资源类型，同时作为缓存的分组键
+const (
+	KindNamespace   = "namespace"
+	KindService     = "service"
+	KindDeployment  = "deployment"
+	KindStatefulSet = "statefulset"
+	KindDaemonSet   = "daemonset"
+	KindPod         = "pod"
+	KindNode        = "node"
+	KindIngress     = "ingress"
+	KindGateway     = "gateway"
+	KindHTTPRoute   = "httproute"
+)
+
+// builtinKinds 内置类型，自定义资源不能使用这些名称
+var builtinKinds = map[string]bool{
+	KindNamespace: true, KindService: true, KindDeployment: true, KindStatefulSet: true, KindDaemonSet: true,
+	KindPod: true, KindNode: true, KindIngress: true, KindGateway: true, KindHTTPRoute: true,
+}
+
+// ClusterInfo 集群标识信息
+type ClusterInfo struct {
+	ID       string
+	Name     string
+	RegionID string
+}
+
+// LoadClusterInfo 读取集群标识，环境变量优先于配置文件
+func LoadClusterInfo() (ClusterInfo, error) {
+	var info ClusterInfo
+	for _, item := range []struct {
+		key   string
+		value *string
+	}{
+		{"kubernetes.clusterID", &info.ID},
+		{"kubernetes.clusterName", &info.Name},
+		{"kubernetes.clusterRegionID", &info.RegionID},
+	} {
+		if os.Getenv(item.key) != "" {
+			*item.value = os.Getenv(item.key)
+		} else if sysinit.GCF.UString(item.key) != "" {
+			*item.value = sysinit.GCF.UString(item.key)
+		} else {
+			return info, errors.New(item.key + " is empty")
+		}
+	}
+	return info, nil
+}
+
+// SyncOptions 控制同步引擎 watch 的资源类型，namespace 和 service 总是同步
+type SyncOptions struct {
+	Workloads  bool // deployment/statefulset/daemonset
+	Pods       bool
+	Nodes      bool
+	Endpoints  bool
+	Ingresses  bool
+	GatewayAPI bool
+	Events     bool           // 缓存 Event，供事件接口直接读取
+	Resources  []ResourceSpec // sync.resources 配置的自定义资源
+
+	ProbeInterval time.Duration // apiserver 健康探测周期，与同步任务是否运行无关
+}
+
+const defaultProbeInterval = 60 * time.Second
+
+func LoadSyncOptions() SyncOptions {
+	return SyncOptions{
+		Workloads:  sysinit.GCF.UBool("sync.workloads", true),
+		Pods:       sysinit.GCF.UBool("sync.pods", true),
+		Nodes:      sysinit.GCF.UBool("sync.nodes", true),
+		Endpoints:  sysinit.GCF.UBool("sync.endpoints", true),
+		Ingresses:  sysinit.GCF.UBool("sync.ingresses", true),
+		GatewayAPI: sysinit.GCF.UBool("sync.gatewayAPI", true),
+		Events:     sysinit.GCF.UBool("sync.events", true),
+		Resources:  loadResourceSpecs(),
+
+		ProbeInterval: sysinit.UDuration("sync.probeInterval", defaultProbeInterval),
+	}
+}
+
+// convertFunc 将 informer 中的对象转换为对外输出的 VO
+type convertFunc func(obj interface{}) interface{}
+
+// SyncEngine 基于 shared informer 的同步引擎
+// 通过 watch 增量维护内存缓存，缓存内容变化时递增 generation 并发出通知
+type SyncEngine struct {
+	Cluster ClusterInfo
+
+	client         kubernetes.Interface
+	factory        informers.SharedInformerFactory
+	dynamicFactory dynamicinformer.DynamicSharedInformerFactory // 仅在安装了 Gateway API 时创建
+	informers      []cache.SharedIndexInformer
+	replicaSets    cache.Indexer             // 仅含元数据，用于解析 pod 的 owner
+	endpointSlices cache.Indexer             // 用于解析 service 的后端
+	services       cache.Indexer             // 用于判断路由指向的 service 是否存在
+	customKinds    []string                  // 已 watch 的自定义资源名称
+	events         cache.SharedIndexInformer // 不参与快照，配置 sync.events 或事件历史时创建
+	probeInterval  time.Duration
+
+	mu         sync.RWMutex
+	items      map[string]map[string]interface{} // kind -> key -> vo
+	version    string
+	probedAt   time.Time
+	probeErr   error
+	startedAt  time.Time
+	syncedAt   time.Time            // 最近一次探测成功的时间，之前的缓存内容可以视为最新
+	watchErrAt map[string]time.Time // kind -> 最近一次 watch 中断的时间
+	generation uint64
+	changed    chan struct{}
+}
+
+func NewSyncEngine(client kubernetes.Interface, cluster ClusterInfo, options SyncOptions) *SyncEngine {
+	e := &SyncEngine{
+		Cluster:    cluster,
+		client:     client,
+		factory:    informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithTransform(stripManagedFields)),
+		items:      map[string]map[string]interface{}{},
+		watchErrAt: map[string]time.Time{},
+		changed:    make(chan struct{}, 1),
+	}
+	e.probeInterval = options.ProbeInterval
+	if e.probeInterval <= 0 {
+		e.probeInterval = defaultProbeInterval
+	}
+
+	e.watch(KindNamespace, e.factory.Core().V1().Namespaces().Informer(), func(obj interface{}) interface{} {
+		return toNamespaceVo(cluster, obj.(*corev1.Namespace))
+	})
+	serviceInformer := e.factory.Core().V1().Services().Informer()
+	e.services = serviceInformer.GetIndexer()
+	if options.Endpoints {
+		e.watchEndpointSlices(serviceInformer)
+	}
+	e.watch(KindService, serviceInformer, func(obj interface{}) interface{} {
+		return e.toServiceVo(obj.(*corev1.Service))
+	})
+	if options.Workloads {
+		apps := e.factory.Apps().V1()
+		e.watch(KindDeployment, apps.Deployments().Informer(), func(obj interface{}) interface{} {
+			return toDeploymentVo(cluster, obj.(*appsv1.Deployment))
+		})
+		e.watch(KindStatefulSet, apps.StatefulSets().Informer(), func(obj interface{}) interface{} {
+			return toStatefulSetVo(cluster, obj.(*appsv1.StatefulSet))
+		})
+		e.watch(KindDaemonSet, apps.DaemonSets().Informer(), func(obj interface{}) interface{} {
+			return toDaemonSetVo(cluster, obj.(*appsv1.DaemonSet))
+		})
+	}
+	if options.Pods {
+		e.watchPods()
+	}
+	if options.Nodes {
+		e.watch(KindNode, e.factory.Core().V1().Nodes().Informer(), func(obj interface{}) interface{} {
+			return toNodeVo(cluster, obj.(*corev1.Node))
+		})
+	}
+	if options.Ingresses {
+		e.watchIngresses()
+	}
+	if options.Events {
+		e.eventInformer()
+	}
+	return e
+}
+
+// Start 启动 informer 和健康探测，随 ctx 取消而停止
+func (e *SyncEngine) Start(ctx context.Context) {
+	e.mu.Lock()
+	e.startedAt = time.Now()
+	e.mu.Unlock()
+	go e.probe(ctx)
+	e.factory.Start(ctx.Done())
+	if e.dynamicFactory != nil {
+		e.dynamicFactory.Start(ctx.Done())
+	}
+}
+
+// WaitForSync 等待所有 informer 完成首次 list
+func (e *SyncEngine) WaitForSync(ctx context.Context) bool {
+	return cache.WaitForCacheSync(ctx.Done(), e.hasSyncedFuncs()...)
+}
+
+func (e *SyncEngine) HasSynced() bool {
+	for _, fn := range e.hasSyncedFuncs() {
+		if !fn() {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *SyncEngine) hasSyncedFuncs() []cache.InformerSynced {
+	var funcs []cache.InformerSynced
+	for _, informer := range e.informers {
+		funcs = append(funcs, informer.HasSynced)
+	}
+	return funcs
+}
+
+// Changed 缓存发生变化时收到通知，多次变化可能合并为一次
+func (e *SyncEngine) Changed() <-chan struct{} {
+	return e.changed
+}
+
+func (e *SyncEngine) Generation() uint64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.generation
+}
+
+// probe 按 probeInterval 探测 apiserver，同步任务停止后 Health、Stale 仍能反映集群的当前状态
+func (e *SyncEngine) probe(ctx context.Context) {
+	e.RefreshVersion()
+	ticker := time.NewTicker(e.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.RefreshVersion()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RefreshVersion 重新获取 apiserver 版本，同时作为集群健康探测
+func (e *SyncEngine) RefreshVersion() {
+	version, err := e.client.Discovery().ServerVersion()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.probedAt = time.Now()
+	e.probeErr = err
+	if err != nil {
+		logrus.Errorf("Failed to get Kubernetes server version of cluster %s: %v", e.Cluster.ID, err)
+		return
+	}
+	e.syncedAt = e.probedAt
+	if e.version != version.GitVersion {
+		e.version = version.GitVersion
+		e.bump()
+	}
+}
+
+// Health 返回最近一次探测结果，缓存未就绪或 apiserver 不可达时视为不健康
+func (e *SyncEngine) Health() (bool, error) {
+	e.mu.RLock()
+	probeErr, probed := e.probeErr, !e.probedAt.IsZero()
+	e.mu.RUnlock()
+
+	switch {
+	case probeErr != nil:
+		return false, probeErr
+	case !probed || !e.HasSynced():
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// Stale 集群不健康或该类资源的 watch 在最近一次探测成功后中断过时，informer 缓存可能已停止更新，
+// 返回 true 和缓存最后确认为最新的时间；从未探测成功时为 informer 启动时间
+func (e *SyncEngine) Stale(kind string) (time.Time, bool) {
+	healthy, _ := e.Health()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if healthy && !e.watchErrAt[kind].After(e.syncedAt) {
+		return time.Time{}, false
+	}
+	if e.syncedAt.IsZero() {
+		return e.startedAt, true
+	}
+	return e.syncedAt, true
+}
+
+// Version 返回 apiserver 版本
+func (e *SyncEngine) Version() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.version
+}
+
+// Count 返回缓存中某类资源的数量
+func (e *SyncEngine) Count(kind string) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.items[kind])
+}
+
+// Watching 是否通过 informer 缓存了该类资源
+func (e *SyncEngine) Watching(kind string) bool {
+	_, ok := e.items[kind]
+	return ok
+}
+
+// Cached 该类资源可以直接从缓存读取
+func (e *SyncEngine) Cached(kind string) bool {
+	return e.Watching(kind) && e.HasSynced()
+}
+
+// ListNamespaces 返回缓存中的命名空间，按名称排序
+func (e *SyncEngine) ListNamespaces() []vo.NamespaceVo {
+	return listCached[vo.NamespaceVo](e, KindNamespace)
+}
+
+// ListServices 返回缓存中的 service，按命名空间和名称排序
+func (e *SyncEngine) ListServices() []vo.ServiceVo {
+	return listCached[vo.ServiceVo](e, KindService)
+}
+
+// ListPods 返回缓存中的 pod，按命名空间和名称排序
+func (e *SyncEngine) ListPods() []vo.PodVo {
+	return listCached[vo.PodVo](e, KindPod)
+}
+
+// Get 按 namespace/name（集群级资源为 name）从缓存获取单个对象
+func (e *SyncEngine) Get(kind, key string) (interface{}, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	item, ok := e.items[kind][key]
+	return item, ok
+}
+
+// ListNodes 返回缓存中的 node，按名称排序
+func (e *SyncEngine) ListNodes() []vo.NodeVo {
+	return listCached[vo.NodeVo](e, KindNode)
+}
+
+// listCached 返回缓存中某类资源的 VO，按命名空间和名称排序
+func listCached[T any](e *SyncEngine, kind string) []T {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return listItems[T](e, kind)
+}
+
+// listItems 需在持有读锁时调用，未 watch 的资源类型返回 nil
+func listItems[T any](e *SyncEngine, kind string) []T {
+	if !e.Watching(kind) {
+		return nil
+	}
+	keys := make([]string, 0, len(e.items[kind]))
+	for key := range e.items[kind] {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		nsI, nameI, _ := cache.SplitMetaNamespaceKey(keys[i])
+		nsJ, nameJ, _ := cache.SplitMetaNamespaceKey(keys[j])
+		if nsI != nsJ {
+			return nsI < nsJ
+		}
+		return nameI < nameJ
+	})
+
+	results := make([]T, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, e.items[kind][key].(T))
+	}
+	return results
+}
+
+// Snapshot 返回当前缓存的完整快照及其 generation
+func (e *SyncEngine) Snapshot() (ClusterData, uint64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return ClusterData{
+		ClusterId:       e.Cluster.ID,
+		ClusterName:     e.Cluster.Name,
+		ClusterRegionID: e.Cluster.RegionID,
+		K8sVersion:      e.version,
+		Namespaces:      listItems[vo.NamespaceVo](e, KindNamespace),
+		Services:        listItems[vo.ServiceVo](e, KindService),
+		Deployments:     listItems[vo.WorkloadVo](e, KindDeployment),
+		StatefulSets:    listItems[vo.WorkloadVo](e, KindStatefulSet),
+		DaemonSets:      listItems[vo.WorkloadVo](e, KindDaemonSet),
+		Pods:            listItems[vo.PodVo](e, KindPod),
+		Nodes:           listItems[vo.NodeVo](e, KindNode),
+		Ingresses:       listItems[vo.IngressVo](e, KindIngress),
+		Gateways:        listItems[vo.GatewayVo](e, KindGateway),
+		HTTPRoutes:      listItems[vo.HTTPRouteVo](e, KindHTTPRoute),
+		Resources:       e.customResources(),
+	}, e.generation
+}
+
+// customResources 需在持有读锁时调用
+func (e *SyncEngine) customResources() map[string][]vo.CustomResourceVo {
+	if len(e.customKinds) == 0 {
+		return nil
+	}
+	resources := make(map[string][]vo.CustomResourceVo, len(e.customKinds))
+	for _, kind := range e.customKinds {
+		resources[kind] = listItems[vo.CustomResourceVo](e, kind)
+	}
+	return resources
+}
+
+func (e *SyncEngine) watch(kind string, informer cache.SharedIndexInformer, convert convertFunc) {
+	e.items[kind] = map[string]interface{}{}
+	e.informers = append(e.informers, informer)
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			e.upsert(kind, obj, convert)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			e.upsert(kind, obj, convert)
+		},
+		DeleteFunc: func(obj interface{}) {
+			e.remove(kind, obj)
+		},
+	})
+	if err != nil {
+		logrus.Errorf("Failed to add %s event handler: %v", kind, err)
+	}
+	err = informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, r *cache.Reflector, err error) {
+		e.mu.Lock()
+		e.watchErrAt[kind] = time.Now()
+		e.mu.Unlock()
+		cache.DefaultWatchErrorHandler(ctx, r, err)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to set %s watch error handler: %v", kind, err)
+	}
+}
+
+func (e *SyncEngine) upsert(kind string, obj interface{}, convert convertFunc) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		logrus.Errorf("Failed to get %s key: %v", kind, err)
+		return
+	}
+	item := convert(obj)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if old, ok := e.items[kind][key]; ok && reflect.DeepEqual(old, item) {
+		return
+	}
+	e.items[kind][key] = item
+	e.bump()
+}
+
+// refreshItem 重新转换已同步的对象，用于其他资源变化时从索引快照刷新。
+// 只更新 items 中已有的对象：删除事件可能在读取快照之后先处理，此时不能把已删除的对象写回
+func (e *SyncEngine) refreshItem(kind string, obj interface{}, convert convertFunc) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		logrus.Errorf("Failed to get %s key: %v", kind, err)
+		return
+	}
+	item := convert(obj)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	old, ok := e.items[kind][key]
+	if !ok || reflect.DeepEqual(old, item) {
+		return
+	}
+	e.items[kind][key] = item
+	e.bump()
+}
+
+func (e *SyncEngine) remove(kind string, obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		logrus.Errorf("Failed to get %s key: %v", kind, err)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.items[kind][key]; !ok {
+		return
+	}
+	delete(e.items[kind], key)
+	e.bump()
+}
+
+// bump 需在持有写锁时调用
+func (e *SyncEngine) bump() {
+	e.generation++
+	select {
+	case e.changed <- struct{}{}:
+	default:
+	}
+}
+
+// stripManagedFields 丢弃 managedFields，减少缓存占用
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+func toNamespaceVo(cluster ClusterInfo, ns *corev1.Namespace) vo.NamespaceVo {
+	return vo.NamespaceVo{
+		NamespaceID: fmt.Sprintf("%s/%s", cluster.ID, ns.Name),
+		Name:        ns.Name,
+		CreateTime:  ns.CreationTimestamp.Local().Format(timeLayout),
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		Status:      string(ns.Status.Phase),
+		Labels:      ns.Labels,
+	}
+}
+
+func toServiceVo(cluster ClusterInfo, svc *corev1.Service) vo.ServiceVo {
+	return vo.ServiceVo{
+		ServiceID:   fmt.Sprintf("%s/%s/%s", cluster.ID, svc.Namespace, svc.Name),
+		Name:        svc.Name,
+		Namespace:   svc.Namespace,
+		Type:        string(svc.Spec.Type),
+		ClusterIP:   svc.Spec.ClusterIP,
+		Ports:       svc.Spec.Ports,
+		CreateTime:  svc.CreationTimestamp.Local().Format(timeLayout),
+		Selector:    svc.Spec.Selector,
+		ClusterName: cluster.Name,
+		ClusterID:   cluster.ID,
+		NamespaceID: fmt.Sprintf("%s/%s", cluster.ID, svc.Namespace),
+		Labels:      svc.Labels,
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSyncEngine(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}},
+	)
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test", Name: "测试集群", RegionID: "default"}, SyncOptions{Workloads: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+
+	services := engine.ListServices()
+	if len(services) != 1 || services[0].ServiceID != "test/default/web" {
+		t.Fatalf("ListServices() = %+v", services)
+	}
+	if namespaces := engine.ListNamespaces(); len(namespaces) != 1 || namespaces[0].NamespaceID != "test/default" {
+		t.Fatalf("ListNamespaces() = %+v", namespaces)
+	}
+
+	generation := engine.Generation()
+	_, err := client.CoreV1().Services("default").Create(ctx, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(engine.ListServices()) == 2 })
+	if engine.Generation() <= generation {
+		t.Errorf("generation not bumped after add")
+	}
+
+	// 与 VO 无关的字段变化不应产生新的 generation
+	generation = engine.Generation()
+	svc, _ := client.CoreV1().Services("default").Get(ctx, "api", metav1.GetOptions{})
+	svc.Annotations = map[string]string{"note": "ignored"}
+	if _, err := client.CoreV1().Services("default").Update(ctx, svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if engine.Generation() != generation {
+		t.Errorf("generation bumped by unrelated update")
+	}
+
+	if err := client.CoreV1().Services("default").Delete(ctx, "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(engine.ListServices()) == 1 })
+
+	data, _ := engine.Snapshot()
+	if data.ClusterId != "test" || len(data.Services) != 1 || data.Services[0].Name != "api" {
+		t.Errorf("Snapshot() = %+v", data)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 健康探测由引擎自己执行，同步任务停止后 watch 中断的标记也会被之后的探测成功清除
+func TestSyncEngineProbe(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	engine := NewSyncEngine(client, ClusterInfo{ID: "test"}, SyncOptions{ProbeInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	if !engine.WaitForSync(ctx) {
+		t.Fatal("cache not synced")
+	}
+
+	engine.mu.Lock()
+	engine.watchErrAt[KindService] = time.Now()
+	engine.mu.Unlock()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, stale := engine.Stale(KindService); !stale {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("kind still stale after probes succeeded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+var (
+	ErrSyncRunning    = errors.New("sync is already running")
+	ErrSyncNotRunning = errors.New("sync is not running")
+)
+
+// SyncManager 管理集群数据同步到 Redis 的后台任务
+// 同步任务运行在独立的 goroutine 中，生命周期受进程级 context 控制。
+// 数据来自 SyncEngine 的内存缓存，只有缓存发生变化时才会写 redis，
+// 未变化时每个周期仅刷新过期时间。
+type SyncManager struct {
+	parent   context.Context
+	engine   *SyncEngine
+	store    *RedisStore
+	interval time.Duration
+	debounce time.Duration
+	ttl      time.Duration
+
+	mu           sync.Mutex
+	cancel       context.CancelFunc
+	done         chan struct{}
+	startedAt    time.Time
+	lastSyncAt   time.Time
+	lastWriteAt  time.Time
+	lastDuration time.Duration
+	lastErr      error
+	written      bool
+	writtenGen   uint64
+	syncCount    int64
+	writeCount   int64
+	failCount    int64
+}
+
+func NewSyncManager(parent context.Context, engine *SyncEngine) *SyncManager {
+	return &SyncManager{
+		parent:   parent,
+		engine:   engine,
+		store:    NewRedisStore(sysinit.RedisCli, engine.Cluster.ID),
+		interval: sysinit.UDuration("sync.interval", 60*time.Second),
+		debounce: sysinit.UDuration("sync.debounce", 2*time.Second),
+		ttl:      sysinit.UDuration("sync.ttl", 3600*time.Second),
+	}
+}
+
+// Start 启动后台同步，重复启动返回 ErrSyncRunning
+func (m *SyncManager) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		return ErrSyncRunning
+	}
+	if err := m.parent.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(m.parent)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	m.startedAt = time.Now()
+
+	go m.run(ctx, m.done)
+	logrus.Infof("Sync started, interval %s, ttl %s", m.interval, m.ttl)
+	return nil
+}
+
+// Stop 停止后台同步并等待当前同步结束
+func (m *SyncManager) Stop() error {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return ErrSyncNotRunning
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// Wait 等待后台同步退出，用于进程关闭时的收尾
+func (m *SyncManager) Wait() {
+	m.mu.Lock()
+	done := m.done
+	m.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+func (m *SyncManager) Status() vo.SyncStatusVo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := vo.SyncStatusVo{
+		ClusterID:  m.engine.Cluster.ID,
+		Running:    m.cancel != nil,
+		Synced:     m.engine.HasSynced(),
+		Interval:   m.interval.String(),
+		TTL:        m.ttl.String(),
+		Generation: m.engine.Generation(),
+		SyncCount:  m.syncCount,
+		WriteCount: m.writeCount,
+		FailCount:  m.failCount,
+	}
+	if !m.startedAt.IsZero() {
+		status.StartedAt = m.startedAt.Format(timeLayout)
+	}
+	if !m.lastSyncAt.IsZero() {
+		status.LastSyncAt = m.lastSyncAt.Format(timeLayout)
+		status.LastDuration = m.lastDuration.String()
+	}
+	if !m.lastWriteAt.IsZero() {
+		status.LastWriteAt = m.lastWriteAt.Format(timeLayout)
+	}
+	if m.lastErr != nil {
+		status.LastError = m.lastErr.Error()
+	}
+	return status
+}
+
+func (m *SyncManager) run(ctx context.Context, done chan struct{}) {
+	defer func() {
+		m.mu.Lock()
+		m.cancel = nil
+		m.done = nil
+		m.mu.Unlock()
+		close(done)
+	}()
+
+	if !m.engine.WaitForSync(ctx) {
+		logrus.Info("Sync stopped before cache synced")
+		return
+	}
+	if err := m.store.EnsureGroups(ctx); err != nil {
+		logrus.Errorf("Failed to create event consumer groups: %v", err)
+	}
+	m.syncOnce(ctx)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	// 缓存变化后延迟 debounce 再写入，合并短时间内的连续变化
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-m.engine.Changed():
+			if flush == nil {
+				flush = time.After(m.debounce)
+			}
+		case <-flush:
+			flush = nil
+			m.syncOnce(ctx)
+		case <-ticker.C:
+			m.syncOnce(ctx)
+		case <-ctx.Done():
+			logrus.Info("Sync stopped")
+			return
+		}
+	}
+}
+
+func (m *SyncManager) syncOnce(ctx context.Context) {
+	start := time.Now()
+
+	m.mu.Lock()
+	written, writtenGen := m.written, m.writtenGen
+	m.mu.Unlock()
+
+	var err error
+	if written && writtenGen == m.engine.Generation() {
+		// 数据未变化时只延长过期时间，key 已过期或被删除时重写
+		written, err = m.store.Touch(ctx, m.ttl)
+	}
+
+	wrote := false
+	if err == nil && (!written || writtenGen != m.engine.Generation()) {
+		data, generation := m.engine.Snapshot()
+		if err = m.store.Write(ctx, data, generation, m.ttl); err == nil {
+			wrote = true
+			writtenGen = generation
+			logrus.Infof("Sync completed for cluster %s, generation %d", data.ClusterName, generation)
+		}
+	}
+	if err != nil && ctx.Err() == nil {
+		logrus.Errorf("Sync failed: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSyncAt = start
+	m.lastDuration = time.Since(start)
+	m.lastErr = err
+	m.syncCount++
+	if wrote {
+		m.written = true
+		m.writtenGen = writtenGen
+		m.lastWriteAt = start
+		m.writeCount++
+	}
+	if err != nil {
+		m.failCount++
+	}
+}
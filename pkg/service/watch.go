@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/sirupsen/logrus"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/sysinit"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	defaultWatchHeartbeat = 15 * time.Second
+	// watchBlock 读取 redis 事件时单次阻塞的最长时间
+	watchBlock = 5 * time.Second
+	// watchReadCount 单次从 redis 读取的事件数
+	watchReadCount = 100
+	// liveWatchPrefix apiserver watch 的续传位置前缀，用于和 redis revision 区分
+	liveWatchPrefix = "rv:"
+)
+
+// WatchOptions watch 接口配置
+type WatchOptions struct {
+	Heartbeat      time.Duration // 空闲时发送 bookmark 的间隔，避免代理断开连接
+	AllowedOrigins []string      // 允许建立 WebSocket 的来源，为空时只允许同源
+}
+
+func LoadWatchOptions() WatchOptions {
+	options := WatchOptions{Heartbeat: sysinit.UDuration("watch.heartbeat", defaultWatchHeartbeat)}
+	for _, origin := range sysinit.GCF.UList("watch.allowedOrigins") {
+		if value, ok := origin.(string); ok && value != "" {
+			options.AllowedOrigins = append(options.AllowedOrigins, value)
+		}
+	}
+	return options
+}
+
+// watchKind 过滤和转换某类资源的事件
+type watchKind struct {
+	// item 从事件中的 VO 解析过滤所需的元数据
+	item func(data json.RawMessage) (req.ListItem, error)
+	// convert 将 apiserver watch 返回的对象转换为 VO，为空表示只能从 redis 事件读取
+	convert func(ctx context.Context, c *Cluster, obj *unstructured.Unstructured) (interface{}, error)
+}
+
+var watchKinds = map[string]watchKind{
+	KindNamespace: {item: decodeItem(namespaceItem), convert: convertTyped(func(_ context.Context, c *Cluster, ns *corev1.Namespace) interface{} {
+		return toNamespaceVo(c.ClusterInfo, ns)
+	})},
+	KindNode: {item: decodeItem(nodeItem), convert: convertTyped(func(_ context.Context, c *Cluster, node *corev1.Node) interface{} {
+		return toNodeVo(c.ClusterInfo, node)
+	})},
+	KindService: {item: decodeItem(serviceItem), convert: convertTyped(func(_ context.Context, c *Cluster, svc *corev1.Service) interface{} {
+		return c.Engine.toServiceVo(svc)
+	})},
+	KindPod: {item: decodeItem(podItem), convert: convertTyped(func(_ context.Context, c *Cluster, pod *corev1.Pod) interface{} {
+		return c.Engine.toPodVo(pod)
+	})},
+	KindDeployment: {item: decodeItem(workloadItem), convert: convertTyped(func(_ context.Context, c *Cluster, d *appsv1.Deployment) interface{} {
+		return toDeploymentVo(c.ClusterInfo, d)
+	})},
+	KindStatefulSet: {item: decodeItem(workloadItem), convert: convertTyped(func(_ context.Context, c *Cluster, sts *appsv1.StatefulSet) interface{} {
+		return toStatefulSetVo(c.ClusterInfo, sts)
+	})},
+	KindDaemonSet: {item: decodeItem(workloadItem), convert: convertTyped(func(_ context.Context, c *Cluster, ds *appsv1.DaemonSet) interface{} {
+		return toDaemonSetVo(c.ClusterInfo, ds)
+	})},
+	KindIngress: {item: decodeItem(ingressItem), convert: convertTyped(func(ctx context.Context, c *Cluster, ing *networkingv1.Ingress) interface{} {
+		return toIngressVo(c.ClusterInfo, ing, watchServiceLookup(ctx, c))
+	})},
+	KindGateway: {item: decodeItem(gatewayItem), convert: func(_ context.Context, c *Cluster, obj *unstructured.Unstructured) (interface{}, error) {
+		return toGatewayVo(c.ClusterInfo, obj), nil
+	}},
+	KindHTTPRoute: {item: decodeItem(httpRouteItem), convert: func(ctx context.Context, c *Cluster, obj *unstructured.Unstructured) (interface{}, error) {
+		return toHTTPRouteVo(c.ClusterInfo, obj, watchServiceLookup(ctx, c)), nil
+	}},
+}
+
+// lookupWatchKind sync.resources 配置的自定义资源只能从 redis 事件读取
+func lookupWatchKind(c *Cluster, kind string) (watchKind, bool) {
+	if wk, ok := watchKinds[kind]; ok {
+		return wk, true
+	}
+	if c.Engine.Watching(kind) {
+		return watchKind{item: decodeItem(customResourceItem)}, true
+	}
+	return watchKind{}, false
+}
+
+func decodeItem[T any](item func(T) req.ListItem) func(json.RawMessage) (req.ListItem, error) {
+	return func(data json.RawMessage) (req.ListItem, error) {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return req.ListItem{}, err
+		}
+		return item(v), nil
+	}
+}
+
+// convertTyped 先将 unstructured 对象转换为内置类型，再复用同步引擎的转换函数
+func convertTyped[T any](convert func(ctx context.Context, c *Cluster, obj *T) interface{}) func(context.Context, *Cluster, *unstructured.Unstructured) (interface{}, error) {
+	return func(ctx context.Context, c *Cluster, obj *unstructured.Unstructured) (interface{}, error) {
+		typed := new(T)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+			return nil, err
+		}
+		return convert(ctx, c, typed), nil
+	}
+}
+
+// watchServiceLookup service 缓存可用时使用缓存，否则逐个查询 apiserver
+func watchServiceLookup(ctx context.Context, c *Cluster) serviceLookup {
+	if c.Engine.Cached(KindService) {
+		return c.Engine.serviceExists
+	}
+	return liveServiceGetter(ctx, c)
+}
+
+// WatchResources
+// @Summary 订阅资源变化
+// @Description 默认以 Server-Sent Events 推送，请求带 WebSocket 升级头时改为 WebSocket，每条消息为一个 vo.WatchMessageVo。
+// @Description 建立连接后先推送一条 bookmark 作为当前位置，空闲时按 watch.heartbeat 重复推送。
+// @Description 同步任务运行时读取 redis 事件，消息 ID 为 revision；否则直接 watch apiserver，消息 ID 为 rv:{resourceVersion}。
+// @Description 重连时通过 revision/resourceVersion 参数或 SSE 的 Last-Event-ID 头从该位置之后续传，位置过旧时推送 error 后关闭，需重新获取列表。
+// @Description 对象更新后进入或离开过滤范围时，分别推送为 created 和 deleted。
+// @Description 直接 watch apiserver 时，连接前就不在过滤范围内的对象第一次更新也会推送一次 deleted，客户端忽略不存在的对象即可。
+// @Tags　k8s
+// @Produce text/event-stream
+// @Param cluster query string false "集群ID"
+// @Param kind path string true "资源类型：namespace/service/deployment/statefulset/daemonset/pod/node/ingress/gateway/httproute，或 sync.resources 中配置的名称"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器，支持的字段同列表接口"
+// @Param type query string false "类型，同列表接口"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param revision query int false "从该 revision 之后续传（redis 事件）"
+// @Param resourceVersion query string false "从该 resourceVersion 之后续传（apiserver watch）"
+// @Param Last-Event-ID header string false "SSE 重连时由浏览器自动带上，优先于 revision/resourceVersion"
+// @Success 200 {object} vo.WatchMessageVo
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/watch/{kind} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/watch/{kind} [get]
+func (k *K8sService) WatchResources(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	kind := ctx.Params().Get("kind")
+	wk, ok := lookupWatchKind(c, kind)
+	if !ok {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("unsupported kind %s", kind)))
+		return
+	}
+	q, err := req.ParseListQuery(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	revision, resourceVersion, err := parseWatchResume(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	source, err := selectWatchSource(c, kind, wk, q, revision, resourceVersion)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+
+	// 请求结束、客户端断开或进程退出时停止
+	watchCtx, cancel := context.WithCancel(ctx.Request().Context())
+	defer cancel()
+	if parent := k.Registry.ctx; parent != nil {
+		defer context.AfterFunc(parent, cancel)()
+	}
+	w, err := openWatchWriter(ctx, cancel, k.Watch.AllowedOrigins)
+	if err != nil {
+		logrus.Warnf("Failed to open watch stream: %v", err)
+		return
+	}
+	defer w.Close()
+
+	heartbeat := k.Watch.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = defaultWatchHeartbeat
+	}
+	runWatch(watchCtx, w, source, newWatchFilter(kind, q, wk.item), heartbeat)
+}
+
+// parseWatchResume 解析续传位置，Last-Event-ID 中 rv: 开头的为 resourceVersion，其余为 revision
+func parseWatchResume(ctx iris.Context) (int64, string, error) {
+	revision, resourceVersion := ctx.URLParam("revision"), ctx.URLParam("resourceVersion")
+	if lastID := ctx.GetHeader("Last-Event-ID"); lastID != "" {
+		revision, resourceVersion = lastID, ""
+		if strings.HasPrefix(lastID, liveWatchPrefix) {
+			revision, resourceVersion = "", strings.TrimPrefix(lastID, liveWatchPrefix)
+		}
+	}
+	if revision == "" {
+		return 0, resourceVersion, nil
+	}
+	if resourceVersion != "" {
+		return 0, "", fmt.Errorf("revision and resourceVersion cannot be used together")
+	}
+	value, err := strconv.ParseInt(revision, 10, 64)
+	if err != nil || value < 0 {
+		return 0, "", fmt.Errorf("invalid revision %q", revision)
+	}
+	return value, "", nil
+}
+
+// eventSource 将消息写入 out，直到 ctx 取消或出错
+type eventSource func(ctx context.Context, out chan<- vo.WatchMessageVo) error
+
+// selectWatchSource 指定 resourceVersion 时 watch apiserver，指定 revision 时读取 redis 事件，
+// 都未指定时同步任务运行中则读取 redis 事件，否则 watch apiserver
+func selectWatchSource(c *Cluster, kind string, wk watchKind, q req.ListQuery, revision int64, resourceVersion string) (eventSource, error) {
+	var publisher *ChangePublisher
+	if c.Syncer != nil && c.Syncer.store != nil && c.Engine.Watching(kind) {
+		publisher = c.Syncer.store.publisher
+	}
+	switch {
+	case resourceVersion != "":
+	case revision > 0:
+		if publisher == nil {
+			return nil, fmt.Errorf("%s of cluster %s is not published to Redis, revision is not supported", kind, c.ID)
+		}
+		return redisEventSource(publisher, revision), nil
+	case publisher != nil && c.Syncer.Status().Running:
+		return redisEventSource(publisher, 0), nil
+	}
+	if wk.convert == nil {
+		return nil, fmt.Errorf("%s of cluster %s can only be watched through Redis events", kind, c.ID)
+	}
+	return liveEventSource(c, kind, wk, q, resourceVersion), nil
+}
+
+// redisEventSource 读取集群的事件 stream，since 大于 0 时从该 revision 之后续传
+// stream 中包含所有资源类型的事件，由 watchFilter 筛选。先订阅集群共享的读取再追赶已有事件，
+// 两者重叠的事件按 stream ID 去重
+func redisEventSource(p *ChangePublisher, since int64) eventSource {
+	return func(ctx context.Context, out chan<- vo.WatchMessageVo) error {
+		cursor, current, err := p.Position(ctx, since)
+		if err != nil {
+			return err
+		}
+		if !sendWatchMessage(ctx, out, vo.WatchMessageVo{Type: vo.WatchBookmark, ID: strconv.FormatInt(current, 10)}) {
+			return nil
+		}
+		sub, err := p.Subscribe(ctx)
+		if err != nil {
+			return err
+		}
+		defer p.Unsubscribe(sub)
+
+		send := func(events []streamEvent) bool {
+			for i := range events {
+				if !streamIDAfter(events[i].id, cursor) {
+					continue
+				}
+				cursor = events[i].id
+				if events[i].event.Revision <= since {
+					continue
+				}
+				msg := vo.WatchMessageVo{Type: vo.WatchEvent, ID: strconv.FormatInt(events[i].event.Revision, 10), Event: &events[i].event}
+				if !sendWatchMessage(ctx, out, msg) {
+					return false
+				}
+			}
+			return true
+		}
+		for lagged := true; ctx.Err() == nil; lagged = sub.lagged.Swap(false) {
+			// 首次以及分发的批次被丢弃后，直接从 stream 追赶
+			if lagged {
+				if _, err := p.CatchUp(ctx, cursor, send); err != nil {
+					return err
+				}
+			}
+			select {
+			case events := <-sub.ch:
+				if !send(events) {
+					return nil
+				}
+			case <-ctx.Done():
+			}
+		}
+		return nil
+	}
+}
+
+// liveEventSource watch apiserver，resourceVersion 为空时从当前版本开始，不推送已有对象
+// apiserver 到达超时关闭 watch 后从最后收到的 resourceVersion 继续
+func liveEventSource(c *Cluster, kind string, wk watchKind, q req.ListQuery, resourceVersion string) eventSource {
+	spec := objectKinds[kind]
+	return func(ctx context.Context, out chan<- vo.WatchMessageVo) error {
+		var ri dynamic.ResourceInterface = c.Dynamic.Resource(spec.gvr)
+		if spec.namespaced {
+			ri = c.Dynamic.Resource(spec.gvr).Namespace(q.Namespace)
+		}
+		opts := q.ListOptions()
+		rv := resourceVersion
+		if rv == "" {
+			list, err := ri.List(ctx, metav1.ListOptions{LabelSelector: opts.LabelSelector, FieldSelector: opts.FieldSelector, Limit: 1})
+			if err != nil {
+				return err
+			}
+			rv = list.GetResourceVersion()
+		}
+		if !sendWatchMessage(ctx, out, vo.WatchMessageVo{Type: vo.WatchBookmark, ID: liveWatchPrefix + rv}) {
+			return nil
+		}
+
+		opts.AllowWatchBookmarks = true
+		for ctx.Err() == nil {
+			opts.ResourceVersion = rv
+			w, err := ri.Watch(ctx, opts)
+			if err != nil {
+				return err
+			}
+			rv, err = forwardWatch(ctx, c, kind, wk, w, rv, out)
+			w.Stop()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// forwardWatch 转发一次 watch 的事件，返回最后收到的 resourceVersion
+func forwardWatch(ctx context.Context, c *Cluster, kind string, wk watchKind, w watch.Interface, rv string, out chan<- vo.WatchMessageVo) (string, error) {
+	for {
+		var ev watch.Event
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return rv, nil
+		case ev, ok = <-w.ResultChan():
+			if !ok {
+				return rv, nil
+			}
+		}
+
+		if ev.Type == watch.Error {
+			err := apierrors.FromObject(ev.Object)
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				return rv, fmt.Errorf("resourceVersion %s is too old: %w", rv, err)
+			}
+			return rv, err
+		}
+		obj, ok := ev.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		rv = obj.GetResourceVersion()
+		msg := vo.WatchMessageVo{Type: vo.WatchBookmark, ID: liveWatchPrefix + rv}
+		if ev.Type != watch.Bookmark {
+			event, err := liveChangeEvent(ctx, c, kind, wk, ev.Type, obj)
+			if err != nil {
+				logrus.Warnf("Failed to convert %s %s/%s of cluster %s: %v", kind, obj.GetNamespace(), obj.GetName(), c.ID, err)
+				continue
+			}
+			msg.Type, msg.Event = vo.WatchEvent, &event
+		}
+		if !sendWatchMessage(ctx, out, msg) {
+			return rv, nil
+		}
+	}
+}
+
+// liveChangeEvent 将 apiserver watch 事件转换为与 redis 事件相同的格式，更新事件没有 old
+func liveChangeEvent(ctx context.Context, c *Cluster, kind string, wk watchKind, eventType watch.EventType, obj *unstructured.Unstructured) (vo.ChangeEventVo, error) {
+	item, err := wk.convert(ctx, c, obj)
+	if err != nil {
+		return vo.ChangeEventVo{}, err
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return vo.ChangeEventVo{}, err
+	}
+	meta, err := wk.item(data)
+	if err != nil {
+		return vo.ChangeEventVo{}, err
+	}
+
+	event := vo.ChangeEventVo{
+		Kind:      kind,
+		ID:        meta.ID,
+		ClusterID: c.ID,
+		Namespace: obj.GetNamespace(),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	switch eventType {
+	case watch.Added:
+		event.Type, event.New = vo.EventCreated, data
+	case watch.Deleted:
+		event.Type, event.Old = vo.EventDeleted, data
+	default:
+		event.Type, event.New = vo.EventUpdated, data
+	}
+	return event, nil
+}
+
+func sendWatchMessage(ctx context.Context, out chan<- vo.WatchMessageVo, msg vo.WatchMessageVo) bool {
+	select {
+	case out <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// watchFilter 按列表接口的过滤条件筛选事件
+// 更新前后只有一边满足条件时，分别转换为 created（进入过滤范围）或 deleted（离开过滤范围）
+type watchFilter struct {
+	kind  string
+	query req.ListQuery
+	item  func(json.RawMessage) (req.ListItem, error)
+	// hidden 没有 old 的事件中不满足条件的对象 ID，用于判断对象是否离开或重新进入过滤范围
+	hidden map[string]struct{}
+}
+
+func newWatchFilter(kind string, query req.ListQuery, item func(json.RawMessage) (req.ListItem, error)) watchFilter {
+	return watchFilter{kind: kind, query: query, item: item, hidden: map[string]struct{}{}}
+}
+
+func (f watchFilter) apply(event vo.ChangeEventVo) (vo.ChangeEventVo, bool) {
+	if event.Kind != f.kind {
+		return event, false
+	}
+	if event.Old == nil {
+		return f.applyWithoutOld(event)
+	}
+	if event.New == nil {
+		delete(f.hidden, event.ID)
+	}
+	oldMatch, newMatch := f.match(event.Old), f.match(event.New)
+	switch {
+	case newMatch && oldMatch:
+	case newMatch:
+		event.Type = vo.EventCreated
+	case oldMatch && event.New == nil:
+	case oldMatch:
+		event.Type = vo.EventDeleted
+	default:
+		return event, false
+	}
+	return event, true
+}
+
+// applyWithoutOld 处理 apiserver watch 的新增和更新事件，无法比较更新前的对象，
+// 因此记录不满足条件的对象：更新后不满足条件时推送一次 deleted，之后重新满足条件时推送 created。
+// 建立连接前就不满足条件的对象第一次更新时也会推送 deleted，客户端忽略即可
+func (f watchFilter) applyWithoutOld(event vo.ChangeEventVo) (vo.ChangeEventVo, bool) {
+	_, hidden := f.hidden[event.ID]
+	if f.match(event.New) {
+		delete(f.hidden, event.ID)
+		if hidden {
+			event.Type = vo.EventCreated
+		}
+		return event, true
+	}
+	f.hidden[event.ID] = struct{}{}
+	if hidden || event.Type == vo.EventCreated {
+		return event, false
+	}
+	event.Type, event.Old, event.New = vo.EventDeleted, event.New, nil
+	return event, true
+}
+
+func (f watchFilter) match(data json.RawMessage) bool {
+	if len(data) == 0 {
+		return false
+	}
+	item, err := f.item(data)
+	if err != nil {
+		logrus.Warnf("Failed to decode %s in change event: %v", f.kind, err)
+		return false
+	}
+	return f.query.Match(item)
+}
+
+// runWatch 推送事件直到 ctx 取消、客户端断开或事件来源出错，出错时先推送 error 消息
+// 没有消息时按 heartbeat 推送 bookmark，ID 为最后一条消息（包括被过滤掉的事件）的位置
+func runWatch(ctx context.Context, w watchWriter, source eventSource, filter watchFilter, heartbeat time.Duration) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	messages := make(chan vo.WatchMessageVo, watchReadCount)
+	go func() {
+		defer close(messages)
+		if err := source(ctx, messages); err != nil && ctx.Err() == nil {
+			sendWatchMessage(ctx, messages, vo.WatchMessageVo{Type: vo.WatchError, Message: err.Error()})
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	var last string
+	for {
+		var msg vo.WatchMessageVo
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			msg = vo.WatchMessageVo{Type: vo.WatchBookmark, ID: last}
+		case m, ok := <-messages:
+			if !ok {
+				return
+			}
+			if m.ID != "" {
+				last = m.ID
+			}
+			if m.Type == vo.WatchEvent {
+				event, ok := filter.apply(*m.Event)
+				if !ok {
+					continue
+				}
+				m.Event = &event
+			}
+			msg = m
+			ticker.Reset(heartbeat)
+		}
+		if err := w.Write(msg); err != nil {
+			logrus.Debugf("Watch client disconnected: %v", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestWatchFilter(t *testing.T) {
+	selector, _ := labels.Parse("app=web")
+	query := req.ListQuery{Namespace: "default", LabelSelector: selector}
+	service := func(namespace, app string) json.RawMessage {
+		b, _ := json.Marshal(vo.ServiceVo{Name: "web", Namespace: namespace, Labels: map[string]string{"app": app}})
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		event vo.ChangeEventVo
+		want  string // 为空表示被过滤
+	}{
+		{"created", vo.ChangeEventVo{Kind: KindService, Type: vo.EventCreated, New: service("default", "web")}, vo.EventCreated},
+		{"other kind", vo.ChangeEventVo{Kind: KindPod, Type: vo.EventCreated, New: service("default", "web")}, ""},
+		{"other namespace", vo.ChangeEventVo{Kind: KindService, Type: vo.EventCreated, New: service("kube-system", "web")}, ""},
+		{"updated", vo.ChangeEventVo{Kind: KindService, Type: vo.EventUpdated, Old: service("default", "web"), New: service("default", "web")}, vo.EventUpdated},
+		{"enters selector", vo.ChangeEventVo{Kind: KindService, Type: vo.EventUpdated, Old: service("default", "api"), New: service("default", "web")}, vo.EventCreated},
+		{"leaves selector", vo.ChangeEventVo{Kind: KindService, Type: vo.EventUpdated, Old: service("default", "web"), New: service("default", "api")}, vo.EventDeleted},
+		{"updated without old", vo.ChangeEventVo{Kind: KindService, Type: vo.EventUpdated, New: service("default", "web")}, vo.EventUpdated},
+		{"deleted", vo.ChangeEventVo{Kind: KindService, Type: vo.EventDeleted, Old: service("default", "web")}, vo.EventDeleted},
+	}
+	for _, tt := range tests {
+		got := ""
+		if event, ok := newWatchFilter(KindService, query, decodeItem(serviceItem)).apply(tt.event); ok {
+			got = event.Type
+		}
+		if got != tt.want {
+			t.Errorf("%s: apply() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWatchFilterWithoutOld(t *testing.T) {
+	selector, _ := labels.Parse("app=web")
+	f := newWatchFilter(KindService, req.ListQuery{Namespace: "default", LabelSelector: selector}, decodeItem(serviceItem))
+	event := func(eventType, id, app string) vo.ChangeEventVo {
+		b, _ := json.Marshal(vo.ServiceVo{Name: id, Namespace: "default", Labels: map[string]string{"app": app}})
+		if eventType == vo.EventDeleted {
+			return vo.ChangeEventVo{Kind: KindService, Type: eventType, ID: id, Old: b}
+		}
+		return vo.ChangeEventVo{Kind: KindService, Type: eventType, ID: id, New: b}
+	}
+
+	// apiserver watch 的更新事件没有 old，按之前推送的结果判断是否离开或重新进入过滤范围
+	tests := []struct {
+		name  string
+		event vo.ChangeEventVo
+		want  string // 为空表示被过滤
+	}{
+		{"updated", event(vo.EventUpdated, "a", "web"), vo.EventUpdated},
+		{"leaves selector", event(vo.EventUpdated, "a", "api"), vo.EventDeleted},
+		{"updated outside selector", event(vo.EventUpdated, "a", "api"), ""},
+		{"enters selector", event(vo.EventUpdated, "a", "web"), vo.EventCreated},
+		{"updated again", event(vo.EventUpdated, "a", "web"), vo.EventUpdated},
+		{"created outside selector", event(vo.EventCreated, "b", "api"), ""},
+		{"updated after created outside selector", event(vo.EventUpdated, "b", "api"), ""},
+		{"enters selector after created", event(vo.EventUpdated, "b", "web"), vo.EventCreated},
+		{"deleted", event(vo.EventDeleted, "b", "web"), vo.EventDeleted},
+		{"created outside selector again", event(vo.EventCreated, "c", "api"), ""},
+		{"deleted outside selector", event(vo.EventDeleted, "c", "api"), ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if event, ok := f.apply(tt.event); ok {
+			got = event.Type
+			if got == vo.EventDeleted && (event.Old == nil || event.New != nil) {
+				t.Errorf("%s: deleted event should carry the object in old", tt.name)
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s: apply() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if len(f.hidden) != 0 {
+		t.Errorf("hidden = %v, want empty after all objects are deleted", f.hidden)
+	}
+}
+
+func TestRedisEventSource(t *testing.T) {
+	_, client := newTestRedis(t)
+	p := &ChangePublisher{
+		client:      client,
+		clusterID:   "test",
+		revisionKey: "cmdb-k8s-test:revision",
+		streamKey:   "cmdb-k8s-test:events",
+		channel:     "cmdb-k8s-test:events",
+		maxLen:      100,
+	}
+	ctx := context.Background()
+	events, err := p.Events(ctx, []itemChange{
+		{Kind: KindService, ID: "test/default/a", New: &storedItem{Namespace: "default", Data: `{"name":"a"}`}},
+		{Kind: KindService, ID: "test/default/b", New: &storedItem{Namespace: "default", Data: `{"name":"b"}`}},
+		{Kind: KindService, ID: "test/default/c", New: &storedItem{Namespace: "default", Data: `{"name":"c"}`}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipe := client.TxPipeline()
+	p.Publish(ctx, pipe, events)
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	watchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out := make(chan vo.WatchMessageVo, 10)
+	go redisEventSource(p, 1)(watchCtx, out)
+
+	var ids []string
+	for len(ids) < 3 {
+		select {
+		case msg := <-out:
+			ids = append(ids, msg.Type+":"+msg.ID)
+		case <-watchCtx.Done():
+			t.Fatalf("received %v", ids)
+		}
+	}
+	if want := []string{"bookmark:1", "event:2", "event:3"}; ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Errorf("messages = %v, want %v", ids, want)
+	}
+
+	// 不续传时从最新的事件之后开始
+	if cursor, current, err := p.Position(ctx, 0); err != nil || current != 3 || cursor == "0-0" {
+		t.Errorf("Position(0) = %q, %d, %v", cursor, current, err)
+	}
+	if _, _, err := p.Position(ctx, 5); err == nil {
+		t.Error("Position() accepted a revision newer than current")
+	}
+	if err := client.XTrimMaxLen(ctx, p.streamKey, 1).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := p.Position(ctx, 1); !errors.Is(err, ErrRevisionTooOld) {
+		t.Errorf("Position() on trimmed stream = %v", err)
+	}
+	if _, _, err := p.Position(ctx, 2); err != nil {
+		t.Errorf("Position(2) = %v", err)
+	}
+}
+
+// TestRedisEventSourceShared 多个 watch 共用一个读取 stream 的 goroutine
+func TestRedisEventSourceShared(t *testing.T) {
+	_, client := newTestRedis(t)
+	p := &ChangePublisher{
+		client:      client,
+		clusterID:   "test",
+		revisionKey: "cmdb-k8s-test:revision",
+		streamKey:   "cmdb-k8s-test:events",
+		channel:     "cmdb-k8s-test:events",
+		maxLen:      100,
+	}
+	ctx := context.Background()
+	publish := func(id string) {
+		events, err := p.Events(ctx, []itemChange{{Kind: KindService, ID: id, New: &storedItem{Namespace: "default", Data: `{"name":"a"}`}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pipe := client.TxPipeline()
+		p.Publish(ctx, pipe, events)
+		if _, err := pipe.Exec(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	publish("test/default/a")
+
+	watchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	outs := []chan vo.WatchMessageVo{make(chan vo.WatchMessageVo, 10), make(chan vo.WatchMessageVo, 10)}
+	done := make(chan struct{}, len(outs))
+	for _, out := range outs {
+		go func(out chan vo.WatchMessageVo) {
+			redisEventSource(p, 0)(watchCtx, out)
+			done <- struct{}{}
+		}(out)
+		if msg := <-out; msg.Type != vo.WatchBookmark || msg.ID != "1" {
+			t.Fatalf("first message = %+v", msg)
+		}
+	}
+	// 等待两个订阅者都加入
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		p.mu.Lock()
+		n := len(p.subs)
+		p.mu.Unlock()
+		if n == len(outs) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("subscribers = %d", n)
+		}
+	}
+	publish("test/default/b")
+	for i, out := range outs {
+		select {
+		case msg := <-out:
+			if msg.Type != vo.WatchEvent || msg.ID != "2" {
+				t.Errorf("watch %d received %+v", i, msg)
+			}
+		case <-watchCtx.Done():
+			t.Fatalf("watch %d received nothing", i)
+		}
+	}
+
+	cancel()
+	for range outs {
+		<-done
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.subs) != 0 || p.stopHub != nil {
+		t.Errorf("stream reader still running with %d subscribers", len(p.subs))
+	}
+}
+
+func TestChangeSubscriberLagged(t *testing.T) {
+	_, client := newTestRedis(t)
+	p := &ChangePublisher{client: client, clusterID: "test", streamKey: "cmdb-k8s-test:events"}
+	sub, err := p.Subscribe(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Unsubscribe(sub)
+	for i := 0; i <= subscriberBuffer; i++ {
+		p.broadcast([]streamEvent{{id: fmt.Sprintf("1-%d", i)}})
+	}
+	if !sub.lagged.Load() || len(sub.ch) != subscriberBuffer {
+		t.Errorf("lagged = %v, buffered = %d", sub.lagged.Load(), len(sub.ch))
+	}
+
+	if !streamIDAfter("10-0", "9-5") || !streamIDAfter("1-10", "1-9") || streamIDAfter("1-1", "1-1") {
+		t.Error("streamIDAfter() compares IDs as strings")
+	}
+}
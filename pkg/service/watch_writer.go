@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kataras/iris/v12"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+)
+
+// webSocketWriteTimeout 单条 WebSocket 消息的写超时，超时视为客户端已断开
+const webSocketWriteTimeout = 10 * time.Second
+
+// watchWriter 向客户端推送 watch 消息，只在一个 goroutine 中使用
+type watchWriter interface {
+	Write(msg vo.WatchMessageVo) error
+	Close()
+}
+
+// openWatchWriter 请求带 WebSocket 升级头时使用 WebSocket，否则使用 SSE
+// WebSocket 连接关闭时调用 cancel
+func openWatchWriter(ctx iris.Context, cancel context.CancelFunc, allowedOrigins []string) (watchWriter, error) {
+	if websocket.IsWebSocketUpgrade(ctx.Request()) {
+		return openWebSocketWriter(ctx, cancel, allowedOrigins)
+	}
+	return openSSEWriter(ctx)
+}
+
+// sseWriter Server-Sent Events，event 为消息类型，id 为续传位置，data 为消息 JSON
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func openSSEWriter(ctx iris.Context) (*sseWriter, error) {
+	w := ctx.ResponseWriter()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("response writer does not support streaming")
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // 关闭 nginx 的响应缓冲
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseWriter{w: w, flusher: flusher}, nil
+}
+
+func (s *sseWriter) Write(msg vo.WatchMessageVo) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if msg.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", msg.ID)
+	}
+	fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", msg.Type, data)
+	if _, err := s.w.Write(b.Bytes()); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *sseWriter) Close() {}
+
+// webSocketWriter 每条消息为一个 JSON 文本帧
+type webSocketWriter struct {
+	conn *websocket.Conn
+}
+
+func openWebSocketWriter(ctx iris.Context, cancel context.CancelFunc, allowedOrigins []string) (*webSocketWriter, error) {
+	conn, err := upgradeWebSocket(ctx, cancel, allowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	return &webSocketWriter{conn: conn}, nil
+}
+
+// upgradeWebSocket 升级为只由服务端推送的 WebSocket 连接，连接断开时调用 cancel
+func upgradeWebSocket(ctx iris.Context, cancel context.CancelFunc, allowedOrigins []string) (*websocket.Conn, error) {
+	upgrader := websocket.Upgrader{CheckOrigin: originChecker(allowedOrigins)}
+	conn, err := upgrader.Upgrade(ctx.ResponseWriter(), ctx.Request(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// 客户端不发送数据，读取循环只用于处理 ping/close 控制帧，读取失败表示连接已断开
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	return conn, nil
+}
+
+// closeWebSocket 发送正常关闭帧后关闭连接
+func closeWebSocket(conn *websocket.Conn) {
+	deadline := time.Now().Add(time.Second)
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+	conn.Close()
+}
+
+func (w *webSocketWriter) Write(msg vo.WatchMessageVo) error {
+	if err := w.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout)); err != nil {
+		return err
+	}
+	return w.conn.WriteJSON(msg)
+}
+
+func (w *webSocketWriter) Close() {
+	closeWebSocket(w.conn)
+}
+
+// originChecker 未配置 watch.allowedOrigins 时使用默认的同源检查，"*" 允许所有来源
+func originChecker(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		for _, item := range allowed {
+			if item == "*" || strings.EqualFold(item, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
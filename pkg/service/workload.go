@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kataras/iris/v12"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/req"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetDeployments
+// @Summary 获取集群deployment信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/deployments [get]
+// @Router /api/v1/clusters/{cluster}/k8s/deployments [get]
+func (k *K8sService) GetDeployments(ctx iris.Context) {
+	listKind(k, ctx, KindDeployment, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.WorkloadVo, metav1.ListMeta, error) {
+		list, err := c.ClientSet.AppsV1().Deployments(q.Namespace).List(ctx, q.ListOptions())
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.WorkloadVo
+		for i := range list.Items {
+			results = append(results, toDeploymentVo(c.ClusterInfo, &list.Items[i]))
+		}
+		return results, list.ListMeta, nil
+	}, workloadItem)
+}
+
+// GetStatefulSets
+// @Summary 获取集群statefulset信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/statefulsets [get]
+// @Router /api/v1/clusters/{cluster}/k8s/statefulsets [get]
+func (k *K8sService) GetStatefulSets(ctx iris.Context) {
+	listKind(k, ctx, KindStatefulSet, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.WorkloadVo, metav1.ListMeta, error) {
+		list, err := c.ClientSet.AppsV1().StatefulSets(q.Namespace).List(ctx, q.ListOptions())
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.WorkloadVo
+		for i := range list.Items {
+			results = append(results, toStatefulSetVo(c.ClusterInfo, &list.Items[i]))
+		}
+		return results, list.ListMeta, nil
+	}, workloadItem)
+}
+
+// GetDaemonSets
+// @Summary 获取集群daemonset信息
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID，all 表示所有集群"
+// @Param namespace query string false "命名空间"
+// @Param labelSelector query string false "标签选择器"
+// @Param fieldSelector query string false "字段选择器"
+// @Param name query string false "名称"
+// @Param nameMatch query string false "名称匹配方式：contains（默认）/prefix"
+// @Param limit query int false "每页数量"
+// @Param continue query string false "分页游标，取自上一页的 pagination.continue"
+// @Param sort query string false "排序字段：name/namespace/createdTime"
+// @Param order query string false "排序方向：asc（默认）/desc"
+// @Success 200 {object} resp.Message
+// @Header 200 {string} X-Snapshot-Age "数据来自 redis 快照或已停止更新的 informer 缓存时为数据的时间（秒）"
+// @Failure 400 {object} resp.Message
+// @Router /api/v1/k8s/daemonsets [get]
+// @Router /api/v1/clusters/{cluster}/k8s/daemonsets [get]
+func (k *K8sService) GetDaemonSets(ctx iris.Context) {
+	listKind(k, ctx, KindDaemonSet, func(ctx context.Context, c *Cluster, q req.ListQuery) ([]vo.WorkloadVo, metav1.ListMeta, error) {
+		list, err := c.ClientSet.AppsV1().DaemonSets(q.Namespace).List(ctx, q.ListOptions())
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		var results []vo.WorkloadVo
+		for i := range list.Items {
+			results = append(results, toDaemonSetVo(c.ClusterInfo, &list.Items[i]))
+		}
+		return results, list.ListMeta, nil
+	}, workloadItem)
+}
+
+func workloadItem(w vo.WorkloadVo) req.ListItem {
+	return req.ListItem{ID: w.WorkloadID, CreateTime: w.CreateTime, Name: w.Name, Namespace: w.Namespace, Labels: w.Labels}
+}
+
+func toDeploymentVo(cluster ClusterInfo, d *appsv1.Deployment) vo.WorkloadVo {
+	w := newWorkloadVo(cluster, "Deployment", &d.ObjectMeta, &d.Spec.Template.Spec, d.Spec.Selector)
+	w.Replicas = replicasOrDefault(d.Spec.Replicas)
+	w.ReadyReplicas = d.Status.ReadyReplicas
+	w.AvailableReplicas = d.Status.AvailableReplicas
+	return w
+}
+
+func toStatefulSetVo(cluster ClusterInfo, sts *appsv1.StatefulSet) vo.WorkloadVo {
+	w := newWorkloadVo(cluster, "StatefulSet", &sts.ObjectMeta, &sts.Spec.Template.Spec, sts.Spec.Selector)
+	w.Replicas = replicasOrDefault(sts.Spec.Replicas)
+	w.ReadyReplicas = sts.Status.ReadyReplicas
+	w.AvailableReplicas = sts.Status.AvailableReplicas
+	return w
+}
+
+func toDaemonSetVo(cluster ClusterInfo, ds *appsv1.DaemonSet) vo.WorkloadVo {
+	w := newWorkloadVo(cluster, "DaemonSet", &ds.ObjectMeta, &ds.Spec.Template.Spec, ds.Spec.Selector)
+	w.Replicas = ds.Status.DesiredNumberScheduled
+	w.ReadyReplicas = ds.Status.NumberReady
+	w.AvailableReplicas = ds.Status.NumberAvailable
+	return w
+}
+
+func newWorkloadVo(cluster ClusterInfo, kind string, meta *metav1.ObjectMeta, podSpec *corev1.PodSpec, selector *metav1.LabelSelector) vo.WorkloadVo {
+	return vo.WorkloadVo{
+		WorkloadID:  fmt.Sprintf("%s/%s/%s/%s", cluster.ID, meta.Namespace, kind, meta.Name),
+		Kind:        kind,
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		NamespaceID: fmt.Sprintf("%s/%s", cluster.ID, meta.Namespace),
+		Images:      containerImages(podSpec),
+		Labels:      meta.Labels,
+		Selector:    selector,
+		Owner:       controllerOwner(meta),
+		CreateTime:  meta.CreationTimestamp.Local().Format(timeLayout),
+	}
+}
+
+// replicasOrDefault spec.replicas 未设置时默认为 1
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// containerImages 返回 initContainers 和 containers 使用的镜像
+func containerImages(spec *corev1.PodSpec) []string {
+	var images []string
+	for _, c := range spec.InitContainers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// controllerOwner 返回控制器 owner，格式 kind/name
+func controllerOwner(meta *metav1.ObjectMeta) string {
+	if ref := metav1.GetControllerOfNoCopy(meta); ref != nil {
+		return ref.Kind + "/" + ref.Name
+	}
+	return ""
+}
+
+// GetDeployment
+// @Summary 获取单个deployment
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/deployments/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/deployments/{name} [get]
+func (k *K8sService) GetDeployment(ctx iris.Context) {
+	k.getObject(ctx, KindDeployment)
+}
+
+func liveDeployment(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	d, err := c.ClientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toDeploymentVo(c.ClusterInfo, d), nil
+}
+
+// GetStatefulSet
+// @Summary 获取单个statefulset
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/statefulsets/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/statefulsets/{name} [get]
+func (k *K8sService) GetStatefulSet(ctx iris.Context) {
+	k.getObject(ctx, KindStatefulSet)
+}
+
+func liveStatefulSet(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	sts, err := c.ClientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toStatefulSetVo(c.ClusterInfo, sts), nil
+}
+
+// GetDaemonSet
+// @Summary 获取单个daemonset
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param view query string false "summary（默认）返回摘要，raw 返回原始对象"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/daemonsets/{name} [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/daemonsets/{name} [get]
+func (k *K8sService) GetDaemonSet(ctx iris.Context) {
+	k.getObject(ctx, KindDaemonSet)
+}
+
+func liveDaemonSet(ctx context.Context, c *Cluster, namespace, name string) (interface{}, error) {
+	ds, err := c.ClientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toDaemonSetVo(c.ClusterInfo, ds), nil
+}
@@ -0,0 +1,632 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	"github.com/yilei-pixocial/kubeapi/pkg/vo"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// 工作负载操作的审计 action
+const (
+	AuditScale    = "scale"
+	AuditRestart  = "restart"
+	AuditRollback = "rollback"
+)
+
+const (
+	// restartedAtAnnotation 与 kubectl rollout restart 使用的注解相同
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+	// revisionAnnotation Deployment 和 ReplicaSet 的版本号
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// rollbackSkippedAnnotations 回滚时不从 ReplicaSet 复制的注解，与 kubectl rollout undo 一致
+var rollbackSkippedAnnotations = map[string]bool{
+	corev1.LastAppliedConfigAnnotation:          true,
+	revisionAnnotation:                          true,
+	"deployment.kubernetes.io/revision-history": true,
+	"deployment.kubernetes.io/desired-replicas": true,
+	"deployment.kubernetes.io/max-replicas":     true,
+	"deprecated.deployment.rollback.to":         true,
+}
+
+var workloadKindNames = map[string]string{
+	KindDeployment:  "Deployment",
+	KindStatefulSet: "StatefulSet",
+	KindDaemonSet:   "DaemonSet",
+}
+
+// parseDryRun dryRun 只支持 All，与 kubectl --dry-run=server 相同，由 apiserver 校验但不保存
+func parseDryRun(ctx iris.Context) ([]string, error) {
+	switch value := ctx.URLParam("dryRun"); value {
+	case "":
+		return nil, nil
+	case metav1.DryRunAll:
+		return []string{metav1.DryRunAll}, nil
+	default:
+		return nil, fmt.Errorf("invalid dryRun %q, must be %s", value, metav1.DryRunAll)
+	}
+}
+
+// actionResult 返回操作后的对象，去掉 managedFields 并补全 apiVersion 和 kind
+func actionResult(obj runtime.Object, gvk schema.GroupVersionKind) runtime.Object {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return obj
+}
+
+// ScaleDeployment
+// @Summary 扩缩容deployment
+// @Description 通过 scale 子资源修改副本数，返回操作后的 Scale 对象，操作写入审计记录
+// @Tags　k8s
+// @Accept application/json
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param dryRun query string false "All 表示只校验不保存"
+// @Param body body vo.ScaleRequestVo true "目标副本数"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/deployments/{name}/scale [post]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/deployments/{name}/scale [post]
+func (k *K8sService) ScaleDeployment(ctx iris.Context) {
+	k.scaleWorkload(ctx, KindDeployment)
+}
+
+// ScaleStatefulSet
+// @Summary 扩缩容statefulset
+// @Description 通过 scale 子资源修改副本数，返回操作后的 Scale 对象，操作写入审计记录
+// @Tags　k8s
+// @Accept application/json
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param dryRun query string false "All 表示只校验不保存"
+// @Param body body vo.ScaleRequestVo true "目标副本数"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/statefulsets/{name}/scale [post]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/statefulsets/{name}/scale [post]
+func (k *K8sService) ScaleStatefulSet(ctx iris.Context) {
+	k.scaleWorkload(ctx, KindStatefulSet)
+}
+
+func (k *K8sService) scaleWorkload(ctx iris.Context, kind string) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	var body vo.ScaleRequestVo
+	if err := ctx.ReadJSON(&body); err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	if body.Replicas == nil || *body.Replicas < 0 {
+		ctx.JSON(resp.BadResponse("replicas is required and must not be negative"))
+		return
+	}
+
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+	record := k.Registry.audit.Begin(ctx, c.ID, AuditScale, workloadKindNames[kind], namespace, name)
+	record.DryRun = dryRun != nil
+	auditDetail(record, body)
+
+	scale := &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: *body.Replicas},
+	}
+	opts := metav1.UpdateOptions{DryRun: dryRun}
+	var result *autoscalingv1.Scale
+	if kind == KindDeployment {
+		result, err = c.ClientSet.AppsV1().Deployments(namespace).UpdateScale(ctx.Request().Context(), name, scale, opts)
+	} else {
+		result, err = c.ClientSet.AppsV1().StatefulSets(namespace).UpdateScale(ctx.Request().Context(), name, scale, opts)
+	}
+	k.Registry.audit.End(record, err)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(resp.OkWithData(actionResult(result, autoscalingv1.SchemeGroupVersion.WithKind("Scale"))))
+}
+
+// RestartDeployment
+// @Summary 滚动重启deployment
+// @Description 与 kubectl rollout restart 相同，在 pod 模板中设置 kubectl.kubernetes.io/restartedAt 注解，返回操作后的对象，操作写入审计记录
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param dryRun query string false "All 表示只校验不保存"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/deployments/{name}/restart [post]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/deployments/{name}/restart [post]
+func (k *K8sService) RestartDeployment(ctx iris.Context) {
+	k.restartWorkload(ctx, KindDeployment)
+}
+
+// RestartStatefulSet
+// @Summary 滚动重启statefulset
+// @Description 与 kubectl rollout restart 相同，在 pod 模板中设置 kubectl.kubernetes.io/restartedAt 注解，返回操作后的对象，操作写入审计记录
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param dryRun query string false "All 表示只校验不保存"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/statefulsets/{name}/restart [post]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/statefulsets/{name}/restart [post]
+func (k *K8sService) RestartStatefulSet(ctx iris.Context) {
+	k.restartWorkload(ctx, KindStatefulSet)
+}
+
+// RestartDaemonSet
+// @Summary 滚动重启daemonset
+// @Description 与 kubectl rollout restart 相同，在 pod 模板中设置 kubectl.kubernetes.io/restartedAt 注解，返回操作后的对象，操作写入审计记录
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param dryRun query string false "All 表示只校验不保存"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/daemonsets/{name}/restart [post]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/daemonsets/{name}/restart [post]
+func (k *K8sService) RestartDaemonSet(ctx iris.Context) {
+	k.restartWorkload(ctx, KindDaemonSet)
+}
+
+func (k *K8sService) restartWorkload(ctx iris.Context, kind string) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+	apps := c.ClientSet.AppsV1()
+	if kind == KindDeployment {
+		// 暂停的 deployment 修改模板后不会发布，与 kubectl 一样拒绝
+		d, err := apps.Deployments(namespace).Get(ctx.Request().Context(), name, metav1.GetOptions{})
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		if d.Spec.Paused {
+			ctx.JSON(resp.BadResponse(fmt.Sprintf("can't restart paused deployment %s, resume it first", name)))
+			return
+		}
+	}
+
+	restartedAt := time.Now().Format(time.RFC3339)
+	record := k.Registry.audit.Begin(ctx, c.ID, AuditRestart, workloadKindNames[kind], namespace, name)
+	record.DryRun = dryRun != nil
+	auditDetail(record, map[string]string{"restartedAt": restartedAt})
+
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`, restartedAtAnnotation, restartedAt)
+	opts := metav1.PatchOptions{DryRun: dryRun}
+	var result runtime.Object
+	switch kind {
+	case KindDeployment:
+		result, err = apps.Deployments(namespace).Patch(ctx.Request().Context(), name, types.StrategicMergePatchType, []byte(patch), opts)
+	case KindStatefulSet:
+		result, err = apps.StatefulSets(namespace).Patch(ctx.Request().Context(), name, types.StrategicMergePatchType, []byte(patch), opts)
+	case KindDaemonSet:
+		result, err = apps.DaemonSets(namespace).Patch(ctx.Request().Context(), name, types.StrategicMergePatchType, []byte(patch), opts)
+	}
+	k.Registry.audit.End(record, err)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(resp.OkWithData(actionResult(result, appsv1.SchemeGroupVersion.WithKind(workloadKindNames[kind]))))
+}
+
+// RollbackDeployment
+// @Summary 回滚deployment
+// @Description 与 kubectl rollout undo 相同，将 pod 模板恢复为指定版本 ReplicaSet 的模板，返回操作后的对象，操作写入审计记录
+// @Tags　k8s
+// @Accept application/json
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Param dryRun query string false "All 表示只校验不保存"
+// @Param body body vo.RollbackRequestVo false "目标版本，不传时回滚到上一个版本"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/deployments/{name}/rollback [post]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/deployments/{name}/rollback [post]
+func (k *K8sService) RollbackDeployment(ctx iris.Context) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	var body vo.RollbackRequestVo
+	if ctx.GetContentLength() != 0 {
+		if err := ctx.ReadJSON(&body); err != nil {
+			ctx.JSON(resp.BadResponse(err.Error()))
+			return
+		}
+	}
+	if body.Revision < 0 {
+		ctx.JSON(resp.BadResponse("revision must not be negative"))
+		return
+	}
+
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+	deployments := c.ClientSet.AppsV1().Deployments(namespace)
+	d, err := deployments.Get(ctx.Request().Context(), name, metav1.GetOptions{})
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	if d.Spec.Paused {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("can't rollback paused deployment %s, resume it first", name)))
+		return
+	}
+	replicaSets, err := deploymentReplicaSets(ctx.Request().Context(), c, d)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	rs, revision, err := rollbackTarget(replicaSets, body.Revision)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	patch, err := rollbackPatch(d, rs)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(fmt.Sprintf("%v, revision %d", err, revision)))
+		return
+	}
+
+	record := k.Registry.audit.Begin(ctx, c.ID, AuditRollback, workloadKindNames[KindDeployment], namespace, name)
+	record.DryRun = dryRun != nil
+	auditDetail(record, map[string]interface{}{"revision": revision, "replicaSet": rs.Name})
+	result, err := deployments.Patch(ctx.Request().Context(), name, types.JSONPatchType, patch, metav1.PatchOptions{DryRun: dryRun})
+	k.Registry.audit.End(record, err)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(resp.OkWithData(actionResult(result, appsv1.SchemeGroupVersion.WithKind("Deployment"))))
+}
+
+// deploymentReplicaSets 返回 deployment 控制的 ReplicaSet
+func deploymentReplicaSets(ctx context.Context, c *Cluster, d *appsv1.Deployment) ([]*appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.ClientSet.AppsV1().ReplicaSets(d.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var results []*appsv1.ReplicaSet
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], d) {
+			results = append(results, &list.Items[i])
+		}
+	}
+	return results, nil
+}
+
+// rollbackTarget 查找指定版本的 ReplicaSet，revision 为 0 时返回当前版本之前的最新版本
+func rollbackTarget(replicaSets []*appsv1.ReplicaSet, revision int64) (*appsv1.ReplicaSet, int64, error) {
+	revisions := make(map[int64]*appsv1.ReplicaSet)
+	var sorted []int64
+	for _, rs := range replicaSets {
+		v, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions[v] = rs
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	if revision == 0 {
+		if len(sorted) < 2 {
+			return nil, 0, fmt.Errorf("no previous revision found in rollout history")
+		}
+		revision = sorted[1]
+	}
+	rs, ok := revisions[revision]
+	if !ok {
+		return nil, 0, fmt.Errorf("unable to find revision %d in rollout history", revision)
+	}
+	return rs, revision, nil
+}
+
+// rollbackPatch 生成替换 pod 模板和注解的 JSON patch，带 resourceVersion 校验避免覆盖并发修改
+func rollbackPatch(d *appsv1.Deployment, rs *appsv1.ReplicaSet) ([]byte, error) {
+	template := rs.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if equality.Semantic.DeepEqual(template, &d.Spec.Template) {
+		return nil, fmt.Errorf("current template already matches the target")
+	}
+
+	annotations := make(map[string]string)
+	for key := range rollbackSkippedAnnotations {
+		if value, ok := d.Annotations[key]; ok {
+			annotations[key] = value
+		}
+	}
+	for key, value := range rs.Annotations {
+		if !rollbackSkippedAnnotations[key] {
+			annotations[key] = value
+		}
+	}
+
+	var ops []map[string]interface{}
+	if d.ResourceVersion != "" {
+		ops = append(ops, map[string]interface{}{"op": "test", "path": "/metadata/resourceVersion", "value": d.ResourceVersion})
+	}
+	ops = append(ops,
+		map[string]interface{}{"op": "replace", "path": "/spec/template", "value": template},
+		map[string]interface{}{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	)
+	return json.Marshal(ops)
+}
+
+// GetDeploymentRollout
+// @Summary 获取deployment发布状态
+// @Description 判断逻辑与 kubectl rollout status 一致，progress 为已更新且可用的副本百分比
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Success 200 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/deployments/{name}/rollout [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/deployments/{name}/rollout [get]
+func (k *K8sService) GetDeploymentRollout(ctx iris.Context) {
+	k.getRolloutStatus(ctx, KindDeployment)
+}
+
+// GetStatefulSetRollout
+// @Summary 获取statefulset发布状态
+// @Description 判断逻辑与 kubectl rollout status 一致，只支持 RollingUpdate 策略，progress 为已更新且就绪的副本百分比
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/statefulsets/{name}/rollout [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/statefulsets/{name}/rollout [get]
+func (k *K8sService) GetStatefulSetRollout(ctx iris.Context) {
+	k.getRolloutStatus(ctx, KindStatefulSet)
+}
+
+// GetDaemonSetRollout
+// @Summary 获取daemonset发布状态
+// @Description 判断逻辑与 kubectl rollout status 一致，只支持 RollingUpdate 策略，progress 为已更新且可用的 pod 百分比
+// @Tags　k8s
+// @Produce application/json
+// @Param cluster query string false "集群ID"
+// @Param namespace path string true "命名空间"
+// @Param name path string true "名称"
+// @Success 200 {object} resp.Message
+// @Failure 400 {object} resp.Message
+// @Failure 404 {object} resp.Message
+// @Router /api/v1/k8s/namespaces/{namespace}/daemonsets/{name}/rollout [get]
+// @Router /api/v1/clusters/{cluster}/k8s/namespaces/{namespace}/daemonsets/{name}/rollout [get]
+func (k *K8sService) GetDaemonSetRollout(ctx iris.Context) {
+	k.getRolloutStatus(ctx, KindDaemonSet)
+}
+
+// getRolloutStatus 发布状态需要最新的 status，始终从 apiserver 读取
+func (k *K8sService) getRolloutStatus(ctx iris.Context, kind string) {
+	c, err := k.Registry.ResolveOne(ctx)
+	if err != nil {
+		ctx.JSON(resp.BadResponse(err.Error()))
+		return
+	}
+	namespace, name := ctx.Params().Get("namespace"), ctx.Params().Get("name")
+	apps := c.ClientSet.AppsV1()
+	var status vo.RolloutStatusVo
+	switch kind {
+	case KindDeployment:
+		d, err := apps.Deployments(namespace).Get(ctx.Request().Context(), name, metav1.GetOptions{})
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		status = deploymentRolloutStatus(d)
+	case KindStatefulSet:
+		sts, err := apps.StatefulSets(namespace).Get(ctx.Request().Context(), name, metav1.GetOptions{})
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		if status, err = statefulSetRolloutStatus(sts); err != nil {
+			ctx.JSON(resp.BadResponse(err.Error()))
+			return
+		}
+	case KindDaemonSet:
+		ds, err := apps.DaemonSets(namespace).Get(ctx.Request().Context(), name, metav1.GetOptions{})
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		if status, err = daemonSetRolloutStatus(ds); err != nil {
+			ctx.JSON(resp.BadResponse(err.Error()))
+			return
+		}
+	}
+	status.ClusterID = c.ID
+	ctx.JSON(resp.OkWithData(status))
+}
+
+func newRolloutStatus(kind string, meta *metav1.ObjectMeta) vo.RolloutStatusVo {
+	return vo.RolloutStatusVo{Kind: kind, Name: meta.Name, Namespace: meta.Namespace}
+}
+
+// rolloutProgress 已更新且可用的副本占期望副本数的百分比
+func rolloutProgress(s vo.RolloutStatusVo, ready int32) int {
+	if s.Done {
+		return 100
+	}
+	if s.Replicas <= 0 {
+		return 0
+	}
+	return int(min(s.UpdatedReplicas, ready, s.Replicas) * 100 / s.Replicas)
+}
+
+func deploymentRolloutStatus(d *appsv1.Deployment) vo.RolloutStatusVo {
+	s := newRolloutStatus("Deployment", &d.ObjectMeta)
+	s.Revision = d.Annotations[revisionAnnotation]
+	s.Replicas = replicasOrDefault(d.Spec.Replicas)
+	s.UpdatedReplicas = d.Status.UpdatedReplicas
+	s.ReadyReplicas = d.Status.ReadyReplicas
+	s.AvailableReplicas = d.Status.AvailableReplicas
+
+	switch {
+	case d.Generation > d.Status.ObservedGeneration:
+		s.Message = "waiting for deployment spec update to be observed"
+	case progressDeadlineExceeded(d):
+		s.Message = fmt.Sprintf("deployment %q exceeded its progress deadline", d.Name)
+	case d.Status.UpdatedReplicas < s.Replicas:
+		s.Message = fmt.Sprintf("waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated",
+			d.Name, d.Status.UpdatedReplicas, s.Replicas)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		s.Message = fmt.Sprintf("waiting for deployment %q rollout to finish: %d old replicas are pending termination",
+			d.Name, d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		s.Message = fmt.Sprintf("waiting for deployment %q rollout to finish: %d of %d updated replicas are available",
+			d.Name, d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	default:
+		s.Done = true
+		s.Message = fmt.Sprintf("deployment %q successfully rolled out", d.Name)
+	}
+	s.Progress = rolloutProgress(s, s.AvailableReplicas)
+	return s
+}
+
+func progressDeadlineExceeded(d *appsv1.Deployment) bool {
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing {
+			return cond.Reason == "ProgressDeadlineExceeded"
+		}
+	}
+	return false
+}
+
+func statefulSetRolloutStatus(sts *appsv1.StatefulSet) (vo.RolloutStatusVo, error) {
+	s := newRolloutStatus("StatefulSet", &sts.ObjectMeta)
+	if sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return s, fmt.Errorf("rollout status is only available for %s strategy type", appsv1.RollingUpdateStatefulSetStrategyType)
+	}
+	s.Revision = sts.Status.UpdateRevision
+	s.Replicas = replicasOrDefault(sts.Spec.Replicas)
+	s.UpdatedReplicas = sts.Status.UpdatedReplicas
+	s.ReadyReplicas = sts.Status.ReadyReplicas
+	s.AvailableReplicas = sts.Status.AvailableReplicas
+
+	var partition int32
+	if rolling := sts.Spec.UpdateStrategy.RollingUpdate; rolling != nil && rolling.Partition != nil {
+		partition = *rolling.Partition
+	}
+	switch {
+	case sts.Status.ObservedGeneration == 0 || sts.Generation > sts.Status.ObservedGeneration:
+		s.Message = "waiting for statefulset spec update to be observed"
+	case sts.Status.ReadyReplicas < s.Replicas:
+		s.Message = fmt.Sprintf("waiting for %d pods to be ready", s.Replicas-sts.Status.ReadyReplicas)
+	case partition > 0:
+		// 分区发布只更新序号不小于 partition 的 pod
+		if sts.Status.UpdatedReplicas < s.Replicas-partition {
+			s.Message = fmt.Sprintf("waiting for partitioned roll out to finish: %d out of %d new pods have been updated",
+				sts.Status.UpdatedReplicas, s.Replicas-partition)
+		} else {
+			s.Done = true
+			s.Message = fmt.Sprintf("partitioned roll out complete: %d new pods have been updated", sts.Status.UpdatedReplicas)
+		}
+	case sts.Status.UpdateRevision != sts.Status.CurrentRevision:
+		s.Message = fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s",
+			sts.Status.UpdatedReplicas, sts.Status.UpdateRevision)
+	default:
+		s.Done = true
+		s.Message = fmt.Sprintf("statefulset rolling update complete %d pods at revision %s", sts.Status.CurrentReplicas, sts.Status.CurrentRevision)
+	}
+	s.Progress = rolloutProgress(s, s.ReadyReplicas)
+	return s, nil
+}
+
+func daemonSetRolloutStatus(ds *appsv1.DaemonSet) (vo.RolloutStatusVo, error) {
+	s := newRolloutStatus("DaemonSet", &ds.ObjectMeta)
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return s, fmt.Errorf("rollout status is only available for %s strategy type", appsv1.RollingUpdateDaemonSetStrategyType)
+	}
+	s.Replicas = ds.Status.DesiredNumberScheduled
+	s.UpdatedReplicas = ds.Status.UpdatedNumberScheduled
+	s.ReadyReplicas = ds.Status.NumberReady
+	s.AvailableReplicas = ds.Status.NumberAvailable
+
+	switch {
+	case ds.Generation > ds.Status.ObservedGeneration:
+		s.Message = "waiting for daemon set spec update to be observed"
+	case ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled:
+		s.Message = fmt.Sprintf("waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated",
+			ds.Name, ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+	case ds.Status.NumberAvailable < ds.Status.DesiredNumberScheduled:
+		s.Message = fmt.Sprintf("waiting for daemon set %q rollout to finish: %d of %d updated pods are available",
+			ds.Name, ds.Status.NumberAvailable, ds.Status.DesiredNumberScheduled)
+	default:
+		s.Done = true
+		s.Message = fmt.Sprintf("daemon set %q successfully rolled out", ds.Name)
+	}
+	s.Progress = rolloutProgress(s, s.AvailableReplicas)
+	return s, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/httptest"
+	"github.com/yilei-pixocial/kubeapi/pkg/sys/resp"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWorkloadActions(t *testing.T) {
+	replicas := int32(2)
+	isController := true
+	template := func(image string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+		}
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid", Annotations: map[string]string{revisionAnnotation: "2"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: template("nginx:1.27"),
+		},
+	}
+	replicaSet := func(name, revision, image string) *appsv1.ReplicaSet {
+		tmpl := template(image)
+		tmpl.Labels = map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: name}
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "web-" + name, Namespace: "default", Labels: map[string]string{"app": "web"},
+				Annotations:     map[string]string{revisionAnnotation: revision, "team": "infra"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController}},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: tmpl},
+		}
+	}
+	client := fake.NewSimpleClientset(deploy, replicaSet("a", "1", "nginx:1.26"), replicaSet("b", "2", "nginx:1.27"))
+	// fake clientset 不支持 scale 子资源，直接返回请求的 Scale
+	var scaled *autoscalingv1.Scale
+	client.PrependReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		update := action.(k8stesting.UpdateAction)
+		if update.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scaled = update.GetObject().(*autoscalingv1.Scale)
+		return true, scaled, nil
+	})
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {ClusterInfo: ClusterInfo{ID: "test"}, ClientSet: client}},
+		order:    []string{"test"},
+	}
+	k := &K8sService{Registry: reg}
+	app := iris.New()
+	app.Post("/namespaces/{namespace}/deployments/{name}/scale", k.ScaleDeployment)
+	app.Post("/namespaces/{namespace}/deployments/{name}/restart", k.RestartDeployment)
+	app.Post("/namespaces/{namespace}/deployments/{name}/rollback", k.RollbackDeployment)
+	app.Get("/namespaces/{namespace}/deployments/{name}/rollout", k.GetDeploymentRollout)
+	e := httptest.New(t, app)
+
+	obj := e.POST("/namespaces/default/deployments/web/scale").WithQuery("dryRun", "All").WithJSON(map[string]int{"replicas": 5}).
+		Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	obj.Value("kind").IsEqual("Scale")
+	obj.Path("$.spec.replicas").IsEqual(5)
+	if len(scaled.Name) == 0 {
+		t.Error("scale subresource was not updated")
+	}
+	e.POST("/namespaces/default/deployments/web/scale").WithJSON(map[string]int{}).Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+	e.POST("/namespaces/default/deployments/web/scale").WithQuery("dryRun", "true").WithJSON(map[string]int{"replicas": 1}).Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+
+	obj = e.POST("/namespaces/default/deployments/web/restart").Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	obj.Value("kind").IsEqual("Deployment")
+	obj.Path("$.spec.template.metadata.annotations").Object().ContainsKey(restartedAtAnnotation)
+	e.POST("/namespaces/default/deployments/missing/restart").Expect().Status(httptest.StatusNotFound)
+
+	// 未指定版本时回滚到上一个版本，去掉 pod-template-hash 标签并复制 ReplicaSet 的注解
+	obj = e.POST("/namespaces/default/deployments/web/rollback").Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	obj.Path("$.spec.template.spec.containers[0].image").IsEqual("nginx:1.26")
+	obj.Path("$.spec.template.metadata.labels").Object().NotContainsKey(appsv1.DefaultDeploymentUniqueLabelKey)
+	obj.Path("$.metadata.annotations").Object().IsEqual(map[string]string{revisionAnnotation: "2", "team": "infra"})
+	e.POST("/namespaces/default/deployments/web/rollback").WithJSON(map[string]int{"revision": 1}).Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD) // 模板已经是版本 1
+	e.POST("/namespaces/default/deployments/web/rollback").WithJSON(map[string]int{"revision": 9}).Expect().
+		JSON().Object().Value("code").IsEqual(resp.MESSAGE_BAD)
+
+	status := e.GET("/namespaces/default/deployments/web/rollout").Expect().Status(httptest.StatusOK).JSON().Object().Value("data").Object()
+	status.Value("clusterID").IsEqual("test")
+	status.Value("done").IsEqual(false)
+	status.Value("progress").IsEqual(0)
+}
+
+func TestRolloutStatus(t *testing.T) {
+	replicas := int32(4)
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 5, UpdatedReplicas: 4, AvailableReplicas: 3},
+	}
+	if s := deploymentRolloutStatus(d); s.Done || s.Progress != 75 || s.Message != `waiting for deployment "web" rollout to finish: 1 old replicas are pending termination` {
+		t.Errorf("deploymentRolloutStatus() = %+v", s)
+	}
+	d.Status.Replicas, d.Status.AvailableReplicas = 4, 4
+	if s := deploymentRolloutStatus(d); !s.Done || s.Progress != 100 {
+		t.Errorf("deploymentRolloutStatus() = %+v", s)
+	}
+	d.Generation = 3
+	if s := deploymentRolloutStatus(d); s.Done {
+		t.Errorf("unobserved generation should not be done: %+v", s)
+	}
+
+	partition := int32(2)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Generation: 1},
+		Spec: appsv1.StatefulSetSpec{Replicas: &replicas, UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+		}},
+		Status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 4, UpdatedReplicas: 2, UpdateRevision: "db-2", CurrentRevision: "db-1"},
+	}
+	if s, err := statefulSetRolloutStatus(sts); err != nil || !s.Done || s.Revision != "db-2" {
+		t.Errorf("statefulSetRolloutStatus() = %+v, %v", s, err)
+	}
+	sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	if _, err := statefulSetRolloutStatus(sts); err == nil {
+		t.Error("OnDelete strategy should not be supported")
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent"},
+		Spec:       appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 4, UpdatedNumberScheduled: 4, NumberAvailable: 2},
+	}
+	if s, err := daemonSetRolloutStatus(ds); err != nil || s.Done || s.Progress != 50 {
+		t.Errorf("daemonSetRolloutStatus() = %+v, %v", s, err)
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	client := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	reg := &ClusterRegistry{
+		clusters: map[string]*Cluster{"test": {ClusterInfo: ClusterInfo{ID: "test"}, ClientSet: client}},
+		order:    []string{"test"},
+		audit:    &Auditor{userHeader: defaultAuditUserHeader, requireUser: true},
+	}
+	k := &K8sService{Registry: reg}
+	app := iris.New()
+	app.Post("/namespaces/{namespace}/deployments/{name}/restart", k.RequireUser, k.RestartDeployment)
+	e := httptest.New(t, app)
+
+	e.POST("/namespaces/default/deployments/web/restart").Expect().Status(httptest.StatusUnauthorized).
+		JSON().Object().Value("message").String().Contains(defaultAuditUserHeader)
+	e.POST("/namespaces/default/deployments/web/restart").WithHeader(defaultAuditUserHeader, "alice").
+		Expect().Status(httptest.StatusOK)
+
+	// 未开启 requireUser 时记录为 anonymous 并放行
+	reg.audit.requireUser = false
+	e.POST("/namespaces/default/deployments/web/restart").Expect().Status(httptest.StatusOK)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestToWorkloadVo(t *testing.T) {
+	cluster := ClusterInfo{ID: "test", Name: "测试集群"}
+	isController := true
+	podSpec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+		Containers:     []corev1.Container{{Name: "web", Image: "nginx:1.27"}},
+	}
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Rollout", Name: "web", Controller: &isController},
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{Spec: podSpec},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 1, AvailableReplicas: 1},
+	}
+	w := toDeploymentVo(cluster, deploy)
+	if w.WorkloadID != "test/default/Deployment/web" || w.Kind != "Deployment" {
+		t.Errorf("WorkloadID = %q, Kind = %q", w.WorkloadID, w.Kind)
+	}
+	if w.Replicas != 1 || w.ReadyReplicas != 1 || w.AvailableReplicas != 1 {
+		t.Errorf("replicas = %d/%d/%d", w.Replicas, w.ReadyReplicas, w.AvailableReplicas)
+	}
+	if !reflect.DeepEqual(w.Images, []string{"busybox:1.36", "nginx:1.27"}) {
+		t.Errorf("Images = %v", w.Images)
+	}
+	if w.Owner != "Rollout/web" {
+		t.Errorf("Owner = %q", w.Owner)
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+		Spec:       appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: podSpec}},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2, NumberAvailable: 2},
+	}
+	w = toDaemonSetVo(cluster, ds)
+	if w.WorkloadID != "test/kube-system/DaemonSet/agent" || w.Replicas != 3 || w.ReadyReplicas != 2 || w.Owner != "" {
+		t.Errorf("toDaemonSetVo() = %+v", w)
+	}
+}
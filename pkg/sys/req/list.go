@@ -0,0 +1,144 @@
+package req
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kataras/iris/v12"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// name 参数的匹配方式
+const (
+	NameMatchContains = "contains"
+	NameMatchPrefix   = "prefix"
+)
+
+// 所有资源的 apiserver 都支持的 fieldSelector 字段
+const (
+	FieldName      = "metadata.name"
+	FieldNamespace = "metadata.namespace"
+)
+
+// ListQuery 列表接口通用的过滤参数
+// namespace、labelSelector 以及 apiserver 支持的 fieldSelector 下推到 ListOptions，其余在内存中过滤
+type ListQuery struct {
+	Namespace     string
+	LabelSelector labels.Selector
+	FieldSelector fields.Selector
+	Type          string
+	Name          string
+	NameMatch     string
+
+	// 分页与排序
+	Limit  int64
+	Sort   string
+	Order  string
+	Cursor *Cursor
+	Chunk  bool // 分块读取 apiserver，ListOptions 带上 limit 和 continue
+}
+
+// ListItem 内存过滤时使用的对象元数据
+type ListItem struct {
+	ID         string // 分页时用于稳定排序
+	CreateTime string
+	Name       string
+	Namespace  string // 集群级资源为空，忽略 namespace 参数
+	Type       string
+	Labels     map[string]string
+	Fields     fields.Set // 除 metadata.name/metadata.namespace 外可用于 fieldSelector 的字段
+}
+
+/**
+ * @Description: 解析列表接口的过滤参数
+ * @param ctx
+ * @return ListQuery
+ */
+func ParseListQuery(ctx iris.Context) (ListQuery, error) {
+	return ParseListQueryWithSort(ctx, "", OrderAsc)
+}
+
+// ParseListQueryWithSort 同 ParseListQuery，未指定 sort/order 时使用给定的默认排序
+func ParseListQueryWithSort(ctx iris.Context, defaultSort, defaultOrder string) (ListQuery, error) {
+	q := ListQuery{
+		Namespace: ctx.URLParam("namespace"),
+		Type:      ctx.URLParam("type"),
+		Name:      ctx.URLParam("name"),
+		NameMatch: ctx.URLParamDefault("nameMatch", NameMatchContains),
+	}
+
+	var err error
+	if q.LabelSelector, err = labels.Parse(ctx.URLParam("labelSelector")); err != nil {
+		return q, fmt.Errorf("invalid labelSelector: %w", err)
+	}
+	if q.FieldSelector, err = fields.ParseSelector(ctx.URLParam("fieldSelector")); err != nil {
+		return q, fmt.Errorf("invalid fieldSelector: %w", err)
+	}
+	if q.NameMatch != NameMatchContains && q.NameMatch != NameMatchPrefix {
+		return q, fmt.Errorf("invalid nameMatch %q, must be %s or %s", q.NameMatch, NameMatchContains, NameMatchPrefix)
+	}
+	if err := q.parsePage(ctx, defaultSort, defaultOrder); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// ListOptions 转换为 apiserver 的查询条件，serverFields 为该资源额外支持的 fieldSelector 字段
+// fieldSelector 中有 apiserver 不支持的字段时不下推，只在内存中过滤
+// 注意：分块读取时 fieldSelector 未下推会导致每页数量少于 limit
+func (q ListQuery) ListOptions(serverFields ...string) metav1.ListOptions {
+	var opts metav1.ListOptions
+	if q.Chunk {
+		opts.Limit = q.Limit
+		if q.ChunkCursor() {
+			opts.Continue = q.Cursor.Continue
+		}
+	}
+	if q.LabelSelector != nil && !q.LabelSelector.Empty() {
+		opts.LabelSelector = q.LabelSelector.String()
+	}
+	if q.FieldSelector != nil && !q.FieldSelector.Empty() {
+		supported := sets.New(FieldName, FieldNamespace).Insert(serverFields...)
+		for _, r := range q.FieldSelector.Requirements() {
+			if !supported.Has(r.Field) {
+				return opts
+			}
+		}
+		opts.FieldSelector = q.FieldSelector.String()
+	}
+	return opts
+}
+
+// Match 在内存中判断对象是否满足所有过滤条件
+func (q ListQuery) Match(item ListItem) bool {
+	if q.Namespace != "" && item.Namespace != "" && item.Namespace != q.Namespace {
+		return false
+	}
+	if q.Type != "" && !strings.EqualFold(item.Type, q.Type) {
+		return false
+	}
+	if q.Name != "" {
+		if q.NameMatch == NameMatchPrefix && !strings.HasPrefix(item.Name, q.Name) {
+			return false
+		}
+		if q.NameMatch == NameMatchContains && !strings.Contains(item.Name, q.Name) {
+			return false
+		}
+	}
+	if q.LabelSelector != nil && !q.LabelSelector.Empty() && !q.LabelSelector.Matches(labels.Set(item.Labels)) {
+		return false
+	}
+	if q.FieldSelector != nil && !q.FieldSelector.Empty() {
+		set := fields.Set{FieldName: item.Name, FieldNamespace: item.Namespace}
+		for k, v := range item.Fields {
+			set[k] = v
+		}
+		if !q.FieldSelector.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package req
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestListQueryListOptions(t *testing.T) {
+	q := ListQuery{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"app": "web"}),
+		FieldSelector: fields.OneTermEqualSelector("status.phase", "Running"),
+	}
+	opts := q.ListOptions("status.phase")
+	if opts.LabelSelector != "app=web" || opts.FieldSelector != "status.phase=Running" {
+		t.Fatalf("ListOptions() = %+v", opts)
+	}
+	// apiserver 不支持的字段只在内存中过滤
+	if opts := q.ListOptions(); opts.FieldSelector != "" {
+		t.Fatalf("ListOptions() = %+v", opts)
+	}
+}
+
+func TestListQueryMatch(t *testing.T) {
+	item := ListItem{
+		Name:      "web-frontend",
+		Namespace: "default",
+		Type:      "NodePort",
+		Labels:    map[string]string{"app": "web", "tier": "frontend"},
+		Fields:    fields.Set{"spec.type": "NodePort"},
+	}
+
+	selector, _ := labels.Parse("app=web,tier in (frontend)")
+	tests := []struct {
+		name  string
+		query ListQuery
+		want  bool
+	}{
+		{"empty", ListQuery{}, true},
+		{"namespace", ListQuery{Namespace: "kube-system"}, false},
+		{"type case insensitive", ListQuery{Type: "nodeport"}, true},
+		{"type mismatch", ListQuery{Type: "ClusterIP"}, false},
+		{"name contains", ListQuery{Name: "front", NameMatch: NameMatchContains}, true},
+		{"name prefix", ListQuery{Name: "front", NameMatch: NameMatchPrefix}, false},
+		{"label selector", ListQuery{LabelSelector: selector}, true},
+		{"field selector", ListQuery{FieldSelector: fields.OneTermNotEqualSelector("spec.type", "NodePort")}, false},
+		{"metadata field", ListQuery{FieldSelector: fields.OneTermEqualSelector(FieldNamespace, "default")}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.query.Match(item); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 集群级资源忽略 namespace 参数
+	if !(ListQuery{Namespace: "default"}).Match(ListItem{Name: "node-1"}) {
+		t.Error("cluster-scoped items should ignore namespace")
+	}
+}
@@ -0,0 +1,182 @@
+package req
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12"
+)
+
+// 排序字段
+const (
+	SortName        = "name"
+	SortNamespace   = "namespace"
+	SortCreatedTime = "createdTime"
+)
+
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// Cursor 分页游标，对外以 base64 编码的不透明字符串传递
+// 分块读取 apiserver 时只有 Continue；内存分页时记录上一页最后一个对象的排序值和 ID
+type Cursor struct {
+	Continue string `json:"c,omitempty"`
+	Sort     string `json:"s,omitempty"`
+	Order    string `json:"o,omitempty"`
+	Value    string `json:"v,omitempty"`
+	ID       string `json:"i,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	return &c, nil
+}
+
+// parsePage 解析 limit、continue、sort、order 参数，sort 和 order 未指定时使用默认值
+func (q *ListQuery) parsePage(ctx iris.Context, defaultSort, defaultOrder string) error {
+	if limit := ctx.URLParam("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid limit %q", limit)
+		}
+		q.Limit = n
+	}
+
+	q.Sort = ctx.URLParamDefault("sort", defaultSort)
+	switch q.Sort {
+	case "", SortName, SortNamespace, SortCreatedTime:
+	default:
+		return fmt.Errorf("invalid sort %q, must be %s, %s or %s", q.Sort, SortName, SortNamespace, SortCreatedTime)
+	}
+	q.Order = strings.ToLower(ctx.URLParamDefault("order", defaultOrder))
+	if q.Order != OrderAsc && q.Order != OrderDesc {
+		return fmt.Errorf("invalid order %q, must be %s or %s", q.Order, OrderAsc, OrderDesc)
+	}
+
+	if token := ctx.URLParam("continue"); token != "" {
+		cursor, err := DecodeCursor(token)
+		if err != nil {
+			return err
+		}
+		if cursor.Continue == "" && (cursor.Sort != q.Sort || cursor.Order != q.Order) {
+			return fmt.Errorf("continue token does not match sort and order")
+		}
+		q.Cursor = cursor
+	}
+	return nil
+}
+
+// Paged 是否请求了分页
+func (q ListQuery) Paged() bool {
+	return q.Limit > 0 || q.Cursor != nil
+}
+
+// ChunkCursor 游标来自 apiserver 分块读取
+func (q ListQuery) ChunkCursor() bool {
+	return q.Cursor != nil && q.Cursor.Continue != ""
+}
+
+// Page 分页结果
+type Page struct {
+	Total    int
+	Continue string
+}
+
+// Paginate 在内存中排序并按游标分页
+// 未指定 sort 时，分页按 ID 排序以保证顺序稳定，不分页时保持原有顺序
+func Paginate[T any](items []T, q ListQuery, item func(T) ListItem) ([]T, Page) {
+	if q.Sort == "" && !q.Paged() {
+		return items, Page{Total: len(items)}
+	}
+
+	keys := make([]ListItem, len(items))
+	for i := range items {
+		keys[i] = item(items[i])
+	}
+	less := func(a, b ListItem) int {
+		if c := strings.Compare(q.sortValue(a), q.sortValue(b)); c != 0 {
+			if q.Order == OrderDesc {
+				return -c
+			}
+			return c
+		}
+		if q.Order == OrderDesc {
+			return -strings.Compare(a.ID, b.ID)
+		}
+		return strings.Compare(a.ID, b.ID)
+	}
+
+	index := make([]int, len(items))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return less(keys[index[i]], keys[index[j]]) < 0
+	})
+
+	start := 0
+	if q.Cursor != nil && !q.ChunkCursor() {
+		last := ListItem{ID: q.Cursor.ID}
+		q.setSortValue(&last, q.Cursor.Value)
+		start = sort.Search(len(index), func(i int) bool {
+			return less(keys[index[i]], last) > 0
+		})
+	}
+	end := len(index)
+	if q.Limit > 0 && start+int(q.Limit) < end {
+		end = start + int(q.Limit)
+	}
+
+	page := Page{Total: len(items)}
+	results := make([]T, 0, end-start)
+	for _, i := range index[start:end] {
+		results = append(results, items[i])
+	}
+	if end < len(index) {
+		last := keys[index[end-1]]
+		page.Continue = Cursor{Sort: q.Sort, Order: q.Order, Value: q.sortValue(last), ID: last.ID}.Encode()
+	}
+	return results, page
+}
+
+func (q ListQuery) sortValue(item ListItem) string {
+	switch q.Sort {
+	case SortName:
+		return item.Name
+	case SortNamespace:
+		return item.Namespace
+	case SortCreatedTime:
+		return item.CreateTime
+	default:
+		return ""
+	}
+}
+
+func (q ListQuery) setSortValue(item *ListItem, value string) {
+	switch q.Sort {
+	case SortName:
+		item.Name = value
+	case SortNamespace:
+		item.Namespace = value
+	case SortCreatedTime:
+		item.CreateTime = value
+	}
+}
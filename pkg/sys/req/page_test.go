@@ -0,0 +1,55 @@
+package req
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []ListItem{
+		{ID: "c/ns-b/web", Name: "web", Namespace: "ns-b", CreateTime: "2024-01-03 00:00:00"},
+		{ID: "c/ns-a/api", Name: "api", Namespace: "ns-a", CreateTime: "2024-01-01 00:00:00"},
+		{ID: "c/ns-a/web", Name: "web", Namespace: "ns-a", CreateTime: "2024-01-02 00:00:00"},
+		{ID: "c/ns-c/db", Name: "db", Namespace: "ns-c", CreateTime: "2024-01-04 00:00:00"},
+	}
+	identity := func(item ListItem) ListItem { return item }
+	ids := func(items []ListItem) []string {
+		var result []string
+		for _, item := range items {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	// 不分页且未指定排序时保持原有顺序
+	all, page := Paginate(items, ListQuery{Order: OrderAsc}, identity)
+	if !reflect.DeepEqual(all, items) || page.Total != 4 || page.Continue != "" {
+		t.Fatalf("Paginate() = %v, %+v", ids(all), page)
+	}
+
+	// 按名称排序，名称相同时按 ID 排序，逐页读取
+	q := ListQuery{Limit: 2, Sort: SortName, Order: OrderAsc}
+	var got []string
+	for i := 0; ; i++ {
+		result, page := Paginate(items, q, identity)
+		got = append(got, ids(result)...)
+		if page.Continue == "" {
+			break
+		}
+		if i > 3 {
+			t.Fatal("too many pages")
+		}
+		if q.Cursor, _ = DecodeCursor(page.Continue); q.Cursor == nil {
+			t.Fatal("invalid cursor")
+		}
+	}
+	want := []string{"c/ns-a/api", "c/ns-c/db", "c/ns-a/web", "c/ns-b/web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("pages = %v, want %v", got, want)
+	}
+
+	desc, page := Paginate(items, ListQuery{Limit: 3, Sort: SortCreatedTime, Order: OrderDesc}, identity)
+	if !reflect.DeepEqual(ids(desc), []string{"c/ns-c/db", "c/ns-b/web", "c/ns-a/web"}) || page.Continue == "" {
+		t.Fatalf("Paginate() = %v, %+v", ids(desc), page)
+	}
+}
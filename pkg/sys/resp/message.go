@@ -1,9 +1,18 @@
 package resp
 
 type Message struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Code       int         `json:"code"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"` // 仅在请求分页时返回
+}
+
+// Pagination 分页信息
+type Pagination struct {
+	Total     int64  `json:"total"`               // 过滤后的总数，分块读取 apiserver 时未知为 -1
+	Limit     int64  `json:"limit"`               // 每页数量，0 表示不限制
+	Continue  string `json:"continue"`            // 下一页游标，为空表示没有更多数据
+	Remaining *int64 `json:"remaining,omitempty"` // 分块读取 apiserver 时剩余数量的估计值
 }
 
 func BadResponse(msg string) Message {
@@ -19,6 +28,20 @@ func OkWithData(data interface{}) Message {
 	return Message{Code: MESSAGE_OK, Message: "success", Data: data}
 }
 
+func OkWithPage(data interface{}, page Pagination) Message {
+	result := OkWithData(data)
+	result.Pagination = &page
+	return result
+}
+
+func NotFound(msg string) Message {
+	return Message{Code: MESSAGE_NOT_FOUND, Message: msg}
+}
+
 func ErrorWithMsg(msg string) Message {
 	return Message{Code: MESSAGE_ERROR, Message: msg}
 }
+
+func OkResponseWithRet(data interface{}) Message {
+	return OkWithData(data)
+}
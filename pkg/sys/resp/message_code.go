@@ -2,7 +2,8 @@ package resp
 
 // Message 类常量
 const (
-	MESSAGE_OK    = 200
-	MESSAGE_BAD   = 501
-	MESSAGE_ERROR = 500
+	MESSAGE_OK        = 200
+	MESSAGE_BAD       = 501
+	MESSAGE_ERROR     = 500
+	MESSAGE_NOT_FOUND = 404
 )
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/olebedev/config"
 )
@@ -25,3 +26,19 @@ func InitConf() error {
 	GCF = cfg
 	return nil
 }
+
+// UDuration 读取时长配置，例如 "60s"、"1h"；未配置或格式错误时返回默认值
+func UDuration(path string, def time.Duration) time.Duration {
+	if GCF == nil {
+		return def
+	}
+	value := GCF.UString(path)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
@@ -4,23 +4,25 @@ import (
 	"fmt"
 
 	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/mysql"
 )
 
 // mysql 连接（已经配置了连接池）
 var MysqlSession *gorm.DB
 
 // mysql 初始化
-func InitMysql() {
+func InitMysql() error {
 	db, err := gorm.Open("mysql", GCF.UString("mysql.url"))
 	if err != nil {
-		fmt.Println(err)
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
 	}
 	db.DB().SetMaxIdleConns(GCF.UInt("mysql.max_idle", 10))  //空闲最大连接数
 	db.DB().SetMaxOpenConns(GCF.UInt("mysql.max_open", 100)) //最大连接数
 
-	db.LogMode(true)
+	db.LogMode(GCF.UBool("mysql.log", true))
 	//db.SetLogger(Logger{})
 	MysqlSession = db
 
 	//fmt.Println("mysql init end",db)
+	return nil
 }
@@ -102,3 +102,37 @@ func TestAesDecrypt(t *testing.T) {
 		})
 	}
 }
+
+func TestAesGcm(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		text    string
+		wantErr bool
+	}{
+		{"case 1", []byte("0123456789abcdef0123456789abcdef"), "apiVersion: v1\nkind: Config", false},
+		{"case 2", []byte("0123456789abcdef"), "", false},
+		{"非法密钥长度", []byte("short"), "123456", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crypted, err := AesGcmEncrypt(tt.key, tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AesGcmEncrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := AesGcmDecrypt(tt.key, crypted)
+			if err != nil {
+				t.Fatalf("AesGcmDecrypt() error = %v", err)
+			}
+			if got != tt.text {
+				t.Errorf("AesGcmDecrypt() = %v, want %v", got, tt.text)
+			}
+			if _, err := AesGcmDecrypt([]byte("fedcba9876543210fedcba9876543210"), crypted); err == nil {
+				t.Errorf("AesGcmDecrypt() with wrong key succeeded")
+			}
+		})
+	}
+}
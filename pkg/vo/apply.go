@@ -0,0 +1,27 @@
+package vo
+
+// apply 结果
+const (
+	ApplyCreated    = "created"
+	ApplyConfigured = "configured"
+	ApplyUnchanged  = "unchanged"
+	ApplyPruned     = "pruned"
+	ApplyError      = "error"
+)
+
+// ApplyObjectVo 单个对象的 apply 结果
+type ApplyObjectVo struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Result     string `json:"result"` // created/configured/unchanged/pruned/error
+	Error      string `json:"error,omitempty"`
+}
+
+// ApplyResultVo apply 请求的结果，objects 按文档顺序排列，被 prune 的对象在最后
+type ApplyResultVo struct {
+	DryRun       bool            `json:"dryRun"`
+	FieldManager string          `json:"fieldManager"`
+	Objects      []ApplyObjectVo `json:"objects"`
+}
@@ -0,0 +1,27 @@
+package vo
+
+type ClusterVo struct {
+	ClusterID       string       `json:"clusterID"`
+	ClusterName     string       `json:"clusterName"`
+	ClusterRegionID string       `json:"clusterRegionID"`
+	Source          string       `json:"source"`
+	K8sVersion      string       `json:"k8sVersion"`
+	Healthy         bool         `json:"healthy"`
+	HealthError     string       `json:"healthError"`
+	NamespaceCount  int          `json:"namespaceCount"`
+	ServiceCount    int          `json:"serviceCount"`
+	Sync            SyncStatusVo `json:"sync"`
+}
+
+// ClusterRequestVo 注册集群的请求，kubeconfig 和 server+token 二选一
+type ClusterRequestVo struct {
+	ClusterID       string `json:"clusterID"`
+	ClusterName     string `json:"clusterName"`
+	ClusterRegionID string `json:"clusterRegionID"`
+	Kubeconfig      string `json:"kubeconfig"`
+	Context         string `json:"context"`
+	Server          string `json:"server"`
+	Token           string `json:"token"`
+	CAData          string `json:"caData"` // PEM 格式的 CA 证书，也可以是 base64 编码后的 PEM
+	Insecure        bool   `json:"insecure"`
+}
@@ -0,0 +1,18 @@
+package vo
+
+// CustomResourceVo 通过配置同步的自定义资源，Fields 为按 JSONPath 提取的字段
+type CustomResourceVo struct {
+	ResourceID  string                 `json:"resourceID"` // clusterID/namespace/Kind/name，集群级资源为 clusterID/Kind/name
+	Group       string                 `json:"group"`
+	Version     string                 `json:"version"`
+	Resource    string                 `json:"resource"`
+	Kind        string                 `json:"kind"`
+	Name        string                 `json:"name"`
+	Namespace   string                 `json:"namespace"`
+	ClusterID   string                 `json:"clusterID"`
+	ClusterName string                 `json:"clusterName"`
+	NamespaceID string                 `json:"namespaceID"`
+	Labels      map[string]string      `json:"labels"`
+	Fields      map[string]interface{} `json:"fields"`
+	CreateTime  string                 `json:"createdTime"`
+}
@@ -0,0 +1,38 @@
+package vo
+
+import "encoding/json"
+
+// 资源变化事件类型
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+type ChangeEventVo struct {
+	Revision  int64           `json:"revision"`
+	Type      string          `json:"type"`
+	Kind      string          `json:"kind"`
+	ID        string          `json:"id"`
+	ClusterID string          `json:"clusterID"`
+	Namespace string          `json:"namespace,omitempty"`
+	Old       json.RawMessage `json:"old,omitempty"`
+	New       json.RawMessage `json:"new,omitempty"`
+	Timestamp string          `json:"timestamp"`
+}
+
+// watch 消息类型
+const (
+	WatchEvent    = "event"
+	WatchBookmark = "bookmark"
+	WatchError    = "error"
+)
+
+// WatchMessageVo watch 接口推送的消息，ID 为续传位置：
+// 来自 redis 事件时为 revision，来自 apiserver 时为 rv:{resourceVersion}
+type WatchMessageVo struct {
+	Type    string         `json:"type"`
+	ID      string         `json:"id,omitempty"`
+	Event   *ChangeEventVo `json:"event,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
@@ -0,0 +1,20 @@
+package vo
+
+// exec WebSocket 消息类型
+const (
+	ExecStdin  = "stdin"  // 客户端 -> 服务端，data 为输入
+	ExecResize = "resize" // 客户端 -> 服务端，cols/rows 为终端大小
+	ExecStdout = "stdout" // 服务端 -> 客户端
+	ExecStderr = "stderr" // 服务端 -> 客户端，tty=true 时合并到 stdout
+	ExecExit   = "exit"   // 服务端 -> 客户端，命令退出，code 为退出码
+	ExecError  = "error"  // 服务端 -> 客户端，会话异常结束，data 为原因
+)
+
+// ExecMessageVo exec 会话中双向传递的 JSON 文本帧
+type ExecMessageVo struct {
+	Op   string `json:"op"`
+	Data string `json:"data,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	Code *int   `json:"code,omitempty"`
+}
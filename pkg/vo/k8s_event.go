@@ -0,0 +1,22 @@
+package vo
+
+// K8sEventVo Kubernetes Event，FirstTime/LastTime 为事件首次和最近一次发生的时间
+type K8sEventVo struct {
+	EventID         string `json:"eventID"` // clusterID/namespace/name
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ClusterID       string `json:"clusterID"`
+	ClusterName     string `json:"clusterName"`
+	Type            string `json:"type"` // Normal/Warning
+	Reason          string `json:"reason"`
+	Message         string `json:"message"`
+	ObjectKind      string `json:"objectKind"`
+	ObjectName      string `json:"objectName"`
+	ObjectNamespace string `json:"objectNamespace"`
+	ObjectUID       string `json:"objectUID"`
+	FieldPath       string `json:"fieldPath,omitempty"` // 例如 spec.containers{app}
+	Source          string `json:"source"`              // 产生事件的组件
+	Count           int32  `json:"count"`
+	FirstTime       string `json:"firstTime"`
+	LastTime        string `json:"lastTime"`
+}
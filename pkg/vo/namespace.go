@@ -1,10 +1,54 @@
 package vo
 
 type NamespaceVo struct {
-	NamespaceID string `json:"namespaceID"`
-	Name        string `json:"name"`
-	CreateTime  string `json:"createdTime"`
-	ClusterID   string `json:"clusterID"`
-	ClusterName string `json:"clusterName"`
-	Status      string `json:"status"`
+	NamespaceID string            `json:"namespaceID"`
+	Name        string            `json:"name"`
+	CreateTime  string            `json:"createdTime"`
+	ClusterID   string            `json:"clusterID"`
+	ClusterName string            `json:"clusterName"`
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// NamespaceRequestVo 按模板创建命名空间的请求
+type NamespaceRequestVo struct {
+	Name        string            `json:"name"`
+	Template    string            `json:"template"`    // namespaces.templates 中的模板名称
+	Labels      map[string]string `json:"labels"`      // 覆盖模板中的同名标签
+	Annotations map[string]string `json:"annotations"` // 覆盖模板中的同名注解
+}
+
+// NamespaceObjectVo 随命名空间创建的对象及结果
+type NamespaceObjectVo struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"` // 为空表示创建成功
+}
+
+// NamespaceCreateVo 按模板创建命名空间的结果
+type NamespaceCreateVo struct {
+	Namespace NamespaceVo         `json:"namespace"`
+	Template  string              `json:"template"`
+	Objects   []NamespaceObjectVo `json:"objects"`
+}
+
+// NamespaceResourceVo 命名空间中某类资源的对象
+type NamespaceResourceVo struct {
+	Group    string   `json:"group"`
+	Version  string   `json:"version"`
+	Resource string   `json:"resource"`
+	Kind     string   `json:"kind"`
+	Count    int      `json:"count"`
+	Names    []string `json:"names"` // 最多返回前 50 个
+}
+
+// NamespaceDeletionPreviewVo 删除命名空间前的预览，删除时需要携带 token
+type NamespaceDeletionPreviewVo struct {
+	Name       string                `json:"name"`
+	ClusterID  string                `json:"clusterID"`
+	Total      int                   `json:"total"` // 将被删除的对象总数
+	Resources  []NamespaceResourceVo `json:"resources"`
+	Errors     []string              `json:"errors,omitempty"` // 无法列出的资源类型
+	Token      string                `json:"token"`
+	ExpireTime string                `json:"expireTime"`
 }
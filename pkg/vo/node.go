@@ -0,0 +1,55 @@
+package vo
+
+type NodeVo struct {
+	NodeID           string            `json:"nodeID"` // clusterID/Node/name
+	Name             string            `json:"name"`
+	ClusterID        string            `json:"clusterID"`
+	ClusterName      string            `json:"clusterName"`
+	Roles            []string          `json:"roles"`
+	Ready            bool              `json:"ready"`
+	Unschedulable    bool              `json:"unschedulable"`
+	KubeletVersion   string            `json:"kubeletVersion"`
+	ContainerRuntime string            `json:"containerRuntime"`
+	OSImage          string            `json:"osImage"`
+	KernelVersion    string            `json:"kernelVersion"`
+	OS               string            `json:"os"`
+	Arch             string            `json:"arch"`
+	ProviderID       string            `json:"providerID"`
+	InternalIP       string            `json:"internalIP"`
+	ExternalIP       string            `json:"externalIP"`
+	Hostname         string            `json:"hostname"`
+	Addresses        []NodeAddressVo   `json:"addresses"`
+	Region           string            `json:"region"`
+	Zone             string            `json:"zone"`
+	InstanceType     string            `json:"instanceType"`
+	Capacity         NodeResourceVo    `json:"capacity"`
+	Allocatable      NodeResourceVo    `json:"allocatable"`
+	Conditions       []NodeConditionVo `json:"conditions"`
+	Taints           []NodeTaintVo     `json:"taints"`
+	Labels           map[string]string `json:"labels"`
+	CreateTime       string            `json:"createdTime"`
+}
+
+type NodeAddressVo struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+type NodeResourceVo struct {
+	CPU              string `json:"cpu"`
+	Memory           string `json:"memory"`
+	Pods             string `json:"pods"`
+	EphemeralStorage string `json:"ephemeralStorage"`
+}
+
+type NodeConditionVo struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type NodeTaintVo struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Effect string `json:"effect"`
+}
@@ -0,0 +1,34 @@
+package vo
+
+type PodVo struct {
+	PodID        string              `json:"podID"` // clusterID/namespace/Pod/name
+	Name         string              `json:"name"`
+	Namespace    string              `json:"namespace"`
+	ClusterID    string              `json:"clusterID"`
+	ClusterName  string              `json:"clusterName"`
+	NamespaceID  string              `json:"namespaceID"`
+	Phase        string              `json:"phase"`
+	Ready        bool                `json:"ready"`
+	PodIP        string              `json:"podIP"`
+	HostIP       string              `json:"hostIP"`
+	NodeName     string              `json:"nodeName"`
+	QOSClass     string              `json:"qosClass"`
+	RestartCount int32               `json:"restartCount"`
+	Containers   []ContainerStatusVo `json:"containers"`
+	Labels       map[string]string   `json:"labels"`
+	Owner        string              `json:"owner"`      // 所属工作负载，ReplicaSet 会解析到 Deployment，格式 kind/name
+	WorkloadID   string              `json:"workloadID"` // 所属工作负载的 ID
+	CreateTime   string              `json:"createdTime"`
+}
+
+type ContainerStatusVo struct {
+	Name                  string `json:"name"`
+	Image                 string `json:"image"`
+	Init                  bool   `json:"init"`
+	Ready                 bool   `json:"ready"`
+	RestartCount          int32  `json:"restartCount"`
+	State                 string `json:"state"`  // running/waiting/terminated
+	Reason                string `json:"reason"` // waiting/terminated 的原因
+	LastTerminationReason string `json:"lastTerminationReason"`
+	LastTerminationCode   int32  `json:"lastTerminationCode"`
+}
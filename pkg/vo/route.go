@@ -0,0 +1,74 @@
+package vo
+
+// RouteRuleVo 一条 host/path 到 service 的路由规则
+type RouteRuleVo struct {
+	Host             string `json:"host"`
+	Path             string `json:"path"`
+	PathType         string `json:"pathType"`
+	ServiceName      string `json:"serviceName"`
+	ServiceNamespace string `json:"serviceNamespace"`
+	ServicePort      string `json:"servicePort"`
+	ServiceID        string `json:"serviceID"`
+	Weight           *int32 `json:"weight,omitempty"`
+	Backend          string `json:"backend,omitempty"`        // 非 service 后端，格式 kind/name
+	ServiceMissing   bool   `json:"serviceMissing"`           // 指向的 service 不存在
+	DefaultBackend   bool   `json:"defaultBackend,omitempty"` // ingress 默认后端
+}
+
+type IngressTLSVo struct {
+	Hosts      []string `json:"hosts"`
+	SecretName string   `json:"secretName"`
+}
+
+type IngressVo struct {
+	IngressID       string            `json:"ingressID"` // clusterID/namespace/Ingress/name
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	ClusterID       string            `json:"clusterID"`
+	ClusterName     string            `json:"clusterName"`
+	NamespaceID     string            `json:"namespaceID"`
+	IngressClass    string            `json:"ingressClass"`
+	Rules           []RouteRuleVo     `json:"rules"`
+	TLS             []IngressTLSVo    `json:"tls"`
+	Addresses       []string          `json:"addresses"`
+	MissingBackends bool              `json:"missingBackends"`
+	Labels          map[string]string `json:"labels"`
+	CreateTime      string            `json:"createdTime"`
+}
+
+type GatewayListenerVo struct {
+	Name       string   `json:"name"`
+	Hostname   string   `json:"hostname"`
+	Port       int64    `json:"port"`
+	Protocol   string   `json:"protocol"`
+	TLSSecrets []string `json:"tlsSecrets"` // namespace/name
+}
+
+type GatewayVo struct {
+	GatewayID    string              `json:"gatewayID"` // clusterID/namespace/Gateway/name
+	Name         string              `json:"name"`
+	Namespace    string              `json:"namespace"`
+	ClusterID    string              `json:"clusterID"`
+	ClusterName  string              `json:"clusterName"`
+	NamespaceID  string              `json:"namespaceID"`
+	GatewayClass string              `json:"gatewayClass"`
+	Listeners    []GatewayListenerVo `json:"listeners"`
+	Addresses    []string            `json:"addresses"`
+	Labels       map[string]string   `json:"labels"`
+	CreateTime   string              `json:"createdTime"`
+}
+
+type HTTPRouteVo struct {
+	RouteID         string            `json:"routeID"` // clusterID/namespace/HTTPRoute/name
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	ClusterID       string            `json:"clusterID"`
+	ClusterName     string            `json:"clusterName"`
+	NamespaceID     string            `json:"namespaceID"`
+	Hostnames       []string          `json:"hostnames"`
+	Gateways        []string          `json:"gateways"` // 父 Gateway 的 GatewayID
+	Rules           []RouteRuleVo     `json:"rules"`
+	MissingBackends bool              `json:"missingBackends"`
+	Labels          map[string]string `json:"labels"`
+	CreateTime      string            `json:"createdTime"`
+}
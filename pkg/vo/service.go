@@ -1,15 +1,49 @@
 package vo
 
 type ServiceVo struct {
-	ServiceID   string      `json:"serviceID"`
-	Name        string      `json:"name"`
-	Namespace   string      `json:"namespace"`
-	ClusterIP   string      `json:"clusterIP"`
-	ClusterID   string      `json:"clusterID"`
-	Ports       interface{} `json:"ports"`
-	CreateTime  string      `json:"createdTime"`
-	ClusterName string      `json:"clusterName"`
-	Selector    interface{} `json:"selector"`
-	Type        string      `json:"type"`
-	NamespaceID string      `json:"namespaceID"`
+	ServiceID   string            `json:"serviceID"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	ClusterIP   string            `json:"clusterIP"`
+	ClusterID   string            `json:"clusterID"`
+	Ports       interface{}       `json:"ports"`
+	CreateTime  string            `json:"createdTime"`
+	ClusterName string            `json:"clusterName"`
+	Selector    interface{}       `json:"selector"`
+	Type        string            `json:"type"`
+	NamespaceID string            `json:"namespaceID"`
+	Labels      map[string]string `json:"labels"`
+	// 以下字段来自 EndpointSlice
+	Endpoints        []EndpointVo `json:"endpoints"`
+	ReadyEndpoints   int          `json:"readyEndpoints"`
+	NoReadyEndpoints bool         `json:"noReadyEndpoints"` // 非 ExternalName 且没有 ready 的后端
+}
+
+type EndpointVo struct {
+	PodName     string           `json:"podName"`
+	Address     string           `json:"address"`
+	NodeName    string           `json:"nodeName"`
+	Zone        string           `json:"zone"`
+	Ready       bool             `json:"ready"`
+	Serving     bool             `json:"serving"`
+	Terminating bool             `json:"terminating"`
+	Ports       []EndpointPortVo `json:"ports"`
+}
+
+type EndpointPortVo struct {
+	Name        string `json:"name"`
+	Protocol    string `json:"protocol"`
+	ServicePort int32  `json:"servicePort"`
+	TargetPort  int32  `json:"targetPort"`
+}
+
+type ServiceEndpointsVo struct {
+	ServiceID        string       `json:"serviceID"`
+	Name             string       `json:"name"`
+	Namespace        string       `json:"namespace"`
+	ClusterID        string       `json:"clusterID"`
+	Type             string       `json:"type"`
+	Endpoints        []EndpointVo `json:"endpoints"`
+	ReadyEndpoints   int          `json:"readyEndpoints"`
+	NoReadyEndpoints bool         `json:"noReadyEndpoints"`
 }
@@ -0,0 +1,18 @@
+package vo
+
+type SyncStatusVo struct {
+	ClusterID    string `json:"clusterID"`
+	Running      bool   `json:"running"`
+	Synced       bool   `json:"synced"`
+	Interval     string `json:"interval"`
+	TTL          string `json:"ttl"`
+	StartedAt    string `json:"startedAt"`
+	LastSyncAt   string `json:"lastSyncAt"`
+	LastWriteAt  string `json:"lastWriteAt"`
+	LastDuration string `json:"lastDuration"`
+	LastError    string `json:"lastError"`
+	Generation   uint64 `json:"generation"`
+	SyncCount    int64  `json:"syncCount"`
+	WriteCount   int64  `json:"writeCount"`
+	FailCount    int64  `json:"failCount"`
+}
@@ -0,0 +1,46 @@
+package vo
+
+// WorkloadVo Deployment/StatefulSet/DaemonSet 的统一结构
+type WorkloadVo struct {
+	WorkloadID        string            `json:"workloadID"` // clusterID/namespace/kind/name
+	Kind              string            `json:"kind"`
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	ClusterID         string            `json:"clusterID"`
+	ClusterName       string            `json:"clusterName"`
+	NamespaceID       string            `json:"namespaceID"`
+	Replicas          int32             `json:"replicas"`
+	ReadyReplicas     int32             `json:"readyReplicas"`
+	AvailableReplicas int32             `json:"availableReplicas"`
+	Images            []string          `json:"images"`
+	Labels            map[string]string `json:"labels"`
+	Selector          interface{}       `json:"selector"`
+	Owner             string            `json:"owner"` // 控制器 owner，格式 kind/name
+	CreateTime        string            `json:"createdTime"`
+}
+
+// ScaleRequestVo 扩缩容请求
+type ScaleRequestVo struct {
+	Replicas *int32 `json:"replicas"` // 目标副本数
+}
+
+// RollbackRequestVo 回滚请求
+type RollbackRequestVo struct {
+	Revision int64 `json:"revision"` // 目标版本，取自 ReplicaSet 的 deployment.kubernetes.io/revision 注解，0 表示上一个版本
+}
+
+// RolloutStatusVo 发布状态，判断逻辑与 kubectl rollout status 一致
+type RolloutStatusVo struct {
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace"`
+	ClusterID         string `json:"clusterID"`
+	Revision          string `json:"revision"` // Deployment 为版本号，StatefulSet 为 updateRevision
+	Replicas          int32  `json:"replicas"` // 期望副本数
+	UpdatedReplicas   int32  `json:"updatedReplicas"`
+	ReadyReplicas     int32  `json:"readyReplicas"`
+	AvailableReplicas int32  `json:"availableReplicas"`
+	Progress          int    `json:"progress"` // 已更新且可用的副本百分比
+	Done              bool   `json:"done"`
+	Message           string `json:"message"`
+}
@@ -9,11 +9,10 @@ import (
 	_ "github.com/yilei-pixocial/kubeapi/pkg/api"
 	"github.com/yilei-pixocial/kubeapi/pkg/service"
 	_ "github.com/yilei-pixocial/kubeapi/router/middleware"
-	"log"
 	"time"
 )
 
-func SetRoutes(app *iris.Application) {
+func SetRoutes(app *iris.Application, registry *service.ClusterRegistry, store *service.ClusterStore) {
 
 	requestCounter := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -48,15 +47,83 @@ func SetRoutes(app *iris.Application) {
 
 	app.Get("/metrics", iris.FromStd(promhttp.Handler()))
 
+	// 代理请求末尾的 / 需要原样转发，不能被重定向
+	app.WrapRouter(service.PreserveProxyTrailingSlash)
+
 	rootApi := app.Party("api/v1")
 
-	k8s, err := service.NewK8sService()
-	if err != nil {
-		log.Fatalln(fmt.Errorf("new k8s service error: %v", err))
-		return
-	}
+	k8s := service.NewK8sService(registry)
+	clusters := service.NewClusterService(registry, store)
+
+	// 集群可以通过 cluster 查询参数或路径指定，all 表示聚合所有集群
+	setK8sRoutes(rootApi.Party("/k8s"), k8s)
+	setK8sRoutes(rootApi.Party("/clusters/{cluster}/k8s"), k8s)
+
+	rootApi.Get("/clusters", clusters.GetClusters)                                 // 集群列表及健康状态
+	rootApi.Post("/clusters", k8s.RequireUser, clusters.CreateCluster)             // 注册集群
+	rootApi.Get("/clusters/{cluster}", clusters.GetCluster)                        // 集群详情
+	rootApi.Put("/clusters/{cluster}", k8s.RequireUser, clusters.UpdateCluster)    // 更新集群
+	rootApi.Delete("/clusters/{cluster}", k8s.RequireUser, clusters.DeleteCluster) // 删除集群
+
+	rootApi.Get("/sync/status", clusters.GetSyncStatus)              // 同步任务状态
+	rootApi.Post("/sync/start", k8s.RequireUser, clusters.StartSync) // 启动同步任务
+	rootApi.Post("/sync/stop", k8s.RequireUser, clusters.StopSync)   // 停止同步任务
+
+}
+
+func setK8sRoutes(api iris.Party, k8s *service.K8sService) {
+	api.Get("/namespaces", k8s.GetNamespaces)                                    // 获取namespace
+	api.Get("/namespaces/{name}", k8s.GetNamespace)                              // 获取单个namespace
+	api.Post("/namespaces", k8s.RequireUser, k8s.CreateNamespace)                // 按模板创建namespace
+	api.Get("/namespaces/{name}/deletion-preview", k8s.PreviewNamespaceDeletion) // 预览删除namespace并获取确认token
+	api.Delete("/namespaces/{name}", k8s.RequireUser, k8s.DeleteNamespace)       // 删除namespace
+	api.Get("/services", k8s.GetServices)                                        // 获取service
+	api.Get("/services/{namespace}/{name}/endpoints", k8s.GetServiceEndpoints)   // 获取service后端
+
+	api.Get("/deployments", k8s.GetDeployments)   // 获取deployment
+	api.Get("/statefulsets", k8s.GetStatefulSets) // 获取statefulset
+	api.Get("/daemonsets", k8s.GetDaemonSets)     // 获取daemonset
+	api.Get("/pods", k8s.GetPods)                 // 获取pod
+	api.Get("/nodes", k8s.GetNodes)               // 获取node
+	api.Get("/nodes/{name}", k8s.GetNode)         // 获取单个node
+
+	api.Get("/ingresses", k8s.GetIngresses)   // 获取ingress
+	api.Get("/gateways", k8s.GetGateways)     // 获取gateway
+	api.Get("/httproutes", k8s.GetHTTPRoutes) // 获取httproute
+
+	// 命名空间下的单个对象
+	ns := api.Party("/namespaces/{namespace}")
+	ns.Get("/services/{name}", k8s.GetService)                // 获取单个service
+	ns.Get("/deployments/{name}", k8s.GetDeployment)          // 获取单个deployment
+	ns.Get("/statefulsets/{name}", k8s.GetStatefulSet)        // 获取单个statefulset
+	ns.Get("/daemonsets/{name}", k8s.GetDaemonSet)            // 获取单个daemonset
+	ns.Get("/pods/{name}", k8s.GetPod)                        // 获取单个pod
+	ns.Get("/pods/{name}/logs", k8s.GetPodLogs)               // 获取pod日志
+	ns.Get("/pods/{name}/exec", k8s.RequireUser, k8s.ExecPod) // 在pod中执行命令（WebSocket）
+	ns.Get("/logs", k8s.GetNamespaceLogs)                     // 按标签选择器聚合pod日志
+	ns.Get("/ingresses/{name}", k8s.GetIngress)               // 获取单个ingress
+	ns.Get("/gateways/{name}", k8s.GetGateway)                // 获取单个gateway
+	ns.Get("/httproutes/{name}", k8s.GetHTTPRoute)            // 获取单个httproute
+
+	// 工作负载操作，支持 dryRun=All，操作写入审计记录
+	ns.Post("/deployments/{name}/scale", k8s.RequireUser, k8s.ScaleDeployment)       // 扩缩容deployment
+	ns.Post("/statefulsets/{name}/scale", k8s.RequireUser, k8s.ScaleStatefulSet)     // 扩缩容statefulset
+	ns.Post("/deployments/{name}/restart", k8s.RequireUser, k8s.RestartDeployment)   // 滚动重启deployment
+	ns.Post("/statefulsets/{name}/restart", k8s.RequireUser, k8s.RestartStatefulSet) // 滚动重启statefulset
+	ns.Post("/daemonsets/{name}/restart", k8s.RequireUser, k8s.RestartDaemonSet)     // 滚动重启daemonset
+	ns.Post("/deployments/{name}/rollback", k8s.RequireUser, k8s.RollbackDeployment) // 回滚deployment
+	ns.Get("/deployments/{name}/rollout", k8s.GetDeploymentRollout)                  // 获取deployment发布状态
+	ns.Get("/statefulsets/{name}/rollout", k8s.GetStatefulSetRollout)                // 获取statefulset发布状态
+	ns.Get("/daemonsets/{name}/rollout", k8s.GetDaemonSetRollout)                    // 获取daemonset发布状态
+
+	api.Get("/events", k8s.GetEvents)                       // 获取Kubernetes事件
+	api.Get("/watch/{kind}", k8s.WatchResources)            // 订阅资源变化（SSE/WebSocket）
+	api.Post("/apply", k8s.RequireUser, k8s.ApplyManifests) // 服务端apply任意资源
 
-	rootApi.Get("/k8s/namespaces", k8s.GetNamespaces) // 获取namespace
-	rootApi.Get("/k8s/services", k8s.GetServices)     // 获取service
+	api.Any("/proxy/namespaces/{namespace}/{resource}/{target}", k8s.RequireUser, k8s.ProxyRequest)             // 代理HTTP请求到pod或service
+	api.Any("/proxy/namespaces/{namespace}/{resource}/{target}/{path:path}", k8s.RequireUser, k8s.ProxyRequest) // 代理HTTP请求到pod或service的路径
 
+	api.Get("/api-resources", k8s.GetAPIResources)                                              // 获取集群支持的资源类型
+	api.Get("/resources/{group}/{version}/{resource}", k8s.GetResources)                        // 获取任意资源
+	api.Get("/resources/{group}/{version}/namespaces/{namespace}/{resource}", k8s.GetResources) // 获取命名空间下的任意资源
 }